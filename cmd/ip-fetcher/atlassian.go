@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"slices"
 	"strings"
 
+	"github.com/jonhadfield/ip-fetcher/filter"
 	"github.com/jonhadfield/ip-fetcher/providers/atlassian"
 	"github.com/urfave/cli/v2"
 	"gopkg.in/h2non/gock.v1"
@@ -20,14 +22,17 @@ const (
 	fileNameLinesAtlassian  = "atlassian-prefixes.txt"
 )
 
-var atlassianFormats = []string{formatJSON, formatYAML, formatLines}
+var (
+	atlassianFormats    = []string{formatJSON, formatYAML, formatLines}
+	atlassianFilterKeys = []string{"region", "product", "direction"}
+)
 
 func atlassianCmd() *cli.Command {
 	return &cli.Command{
 		Name:      providerNameAtlassian,
 		HelpName:  "- fetch Atlassian prefixes",
 		Usage:     "Atlassian",
-		UsageText: "ip-fetcher atlassian {--stdout | --Path FILE} [--lines]",
+		UsageText: "ip-fetcher atlassian {--stdout | --Path FILE} [--lines] [--filter EXPRESSION]",
 		OnUsageError: func(cCtx *cli.Context, err error, isSubcommand bool) error {
 			_ = cli.ShowSubcommandHelp(cCtx)
 
@@ -50,6 +55,7 @@ func atlassianCmd() *cli.Command {
 				Name:  formatLines,
 				Usage: usageLinesOutput,
 			},
+			attributeFilterFlag(atlassianFilterKeys...),
 		},
 		Action: func(c *cli.Context) error {
 			path, stdout, err := resolveOutputTargets(c)
@@ -57,6 +63,11 @@ func atlassianCmd() *cli.Command {
 				return err
 			}
 
+			sel, err := selectorFromContext(c, atlassianFilterKeys...)
+			if err != nil {
+				return err
+			}
+
 			a := atlassian.New()
 
 			if isEnvEnabled("IP_FETCHER_MOCK_ATLASSIAN") {
@@ -79,6 +90,9 @@ func atlassianCmd() *cli.Command {
 				format = formatLines
 			}
 
+			doc = atlassianFilter(doc, sel)
+			families.Doc(&doc)
+
 			return atlassianOutput(doc, format, stdout, path)
 		},
 	}
@@ -108,18 +122,40 @@ func atlassianOutput(doc atlassian.Doc, format string, stdout bool, path string)
 		}
 	}
 
-	if stdout {
-		fmt.Printf("%s\n\n", data)
-	}
-
 	defaultName := fileNameOutputAtlassian
 	if format == formatLines {
 		defaultName = fileNameLinesAtlassian
 	}
 
-	return writeOutputs(path, false, SaveFileInput{
+	return writeOutputs(path, stdout, SaveFileInput{
 		Provider:        providerNameAtlassian,
 		DefaultFileName: defaultName,
 		Data:            data,
 	})
 }
+
+// atlassianFilter removes the items not matching sel, and their prefixes, from doc.
+func atlassianFilter(doc atlassian.Doc, sel filter.Selector) atlassian.Doc {
+	if len(sel) == 0 {
+		return doc
+	}
+
+	kept := make(map[string]bool)
+
+	doc.Items = slices.DeleteFunc(doc.Items, func(item atlassian.Item) bool {
+		if !sel.Match(filter.Attributes{"region": item.Region, "product": item.Product, "direction": item.Direction}) {
+			return true
+		}
+
+		kept[item.CIDR] = true
+
+		return false
+	})
+
+	removed := func(p netip.Prefix) bool { return !kept[p.String()] }
+
+	doc.IPv4Prefixes = slices.DeleteFunc(doc.IPv4Prefixes, removed)
+	doc.IPv6Prefixes = slices.DeleteFunc(doc.IPv6Prefixes, removed)
+
+	return doc
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jonhadfield/ip-fetcher/filter"
+	"github.com/urfave/cli/v2"
+)
+
+const flagFilter = "filter"
+
+// attributeFilterFlag returns the flag selecting prefixes by the attributes named by keys.
+func attributeFilterFlag(keys ...string) cli.Flag {
+	// a string flag, as slice flags split values on the commas separating conditions
+	return &cli.StringFlag{
+		Name: flagFilter,
+		Usage: fmt.Sprintf("only output prefixes matching key=value[|value...][,key=value...], "+
+			"where key is one of: %s", strings.Join(keys, ", ")),
+	}
+}
+
+// selectorFromContext parses the command's filter expression, checking it only refers to keys.
+func selectorFromContext(c *cli.Context, keys ...string) (filter.Selector, error) {
+	if c.String(flagFilter) == "" {
+		return nil, nil
+	}
+
+	sel, err := filter.ParseSelector([]string{c.String(flagFilter)})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = sel.Validate(keys...); err != nil {
+		return nil, err
+	}
+
+	return sel, nil
+}
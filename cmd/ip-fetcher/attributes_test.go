@@ -0,0 +1,108 @@
+package main_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mainpkg "github.com/jonhadfield/ip-fetcher/cmd/ip-fetcher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSCmdFilter(t *testing.T) {
+	defer testCleanUp(os.Args)
+	t.Setenv("IP_FETCHER_MOCK_AWS", "true")
+
+	tDir := t.TempDir()
+	app := mainpkg.GetApp()
+
+	os.Args = []string{"ip-fetcher", "aws", "--Path", tDir, "--lines", "--filter", "region=us-east-2,service=api_gateway"}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err := os.ReadFile(filepath.Join(tDir, "aws-prefixes.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "3.145.220.0/22\n3.145.230.0/24\n", string(data))
+
+	// the upstream document keeps its format
+	os.Args = []string{"ip-fetcher", "aws", "--Path", tDir, "--filter", "region=ap-north*|us-east-2,service!=api_gateway"}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err = os.ReadFile(filepath.Join(tDir, "ip-ranges.json"))
+	require.NoError(t, err)
+
+	var doc struct {
+		Prefixes []struct {
+			IPPrefix           string `json:"ip_prefix"`
+			NetworkBorderGroup string `json:"network_border_group"`
+		} `json:"prefixes"`
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	require.Len(t, doc.Prefixes, 1)
+	require.Equal(t, "3.5.140.0/22", doc.Prefixes[0].IPPrefix)
+	require.Equal(t, "ap-northeast-2", doc.Prefixes[0].NetworkBorderGroup)
+
+	os.Args = []string{"ip-fetcher", "aws", "--Path", tDir, "--filter", "tag=OSN"}
+	require.ErrorContains(t, app.Run(os.Args), "unknown filter key: tag")
+}
+
+func TestOCICmdFilter(t *testing.T) {
+	defer testCleanUp(os.Args)
+	t.Setenv("IP_FETCHER_MOCK_OCI", "true")
+
+	tDir := t.TempDir()
+	app := mainpkg.GetApp()
+
+	os.Args = []string{"ip-fetcher", "oci", "--Path", tDir, "--filter", "tag=OSN"}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err := os.ReadFile(filepath.Join(tDir, "public_ip_ranges.json"))
+	require.NoError(t, err)
+
+	var doc struct {
+		Regions []struct {
+			Region string `json:"region"`
+			CIDRs  []struct {
+				Tags []string `json:"tags"`
+			} `json:"cidrs"`
+		} `json:"regions"`
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	require.NotEmpty(t, doc.Regions)
+
+	var cidrs int
+
+	for _, r := range doc.Regions {
+		require.NotEmpty(t, r.CIDRs)
+
+		for _, c := range r.CIDRs {
+			require.Contains(t, c.Tags, "OSN")
+
+			cidrs++
+		}
+	}
+
+	require.Equal(t, 32, cidrs)
+}
+
+func TestGCPCmdFilter(t *testing.T) {
+	defer testCleanUp(os.Args)
+	t.Setenv("IP_FETCHER_MOCK_GCP", "true")
+
+	tDir := t.TempDir()
+	app := mainpkg.GetApp()
+
+	os.Args = []string{"ip-fetcher", "gcp", "--Path", tDir, "--format", "csv", "--filter", "scope=us-*"}
+	require.NoError(t, app.Run(os.Args))
+
+	os.Args = []string{"ip-fetcher", "gcp", "--Path", tDir, "--lines", "--filter", "scope=us-*"}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err := os.ReadFile(filepath.Join(tDir, "gcp-prefixes.txt"))
+	require.NoError(t, err)
+
+	lines := strings.Fields(string(data))
+	require.NotEmpty(t, lines)
+	require.NotContains(t, lines, "34.80.0.0/15") // asia-east1
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/url"
+	"slices"
 
+	"github.com/jonhadfield/ip-fetcher/filter"
 	"github.com/jonhadfield/ip-fetcher/providers/aws"
 	"github.com/urfave/cli/v2"
 	"gopkg.in/h2non/gock.v1"
@@ -15,12 +18,14 @@ const (
 	awsFileNameLines = "aws-prefixes.txt"
 )
 
+var awsFilterKeys = []string{"region", "service", "network-border-group"}
+
 func awsCmd() *cli.Command {
 	return &cli.Command{
 		Name:      awsProviderName,
 		HelpName:  "- fetch AWS prefixes",
 		Usage:     "Amazon Web Services",
-		UsageText: "ip-fetcher aws {--stdout | --Path FILE} [--lines]",
+		UsageText: "ip-fetcher aws {--stdout | --Path FILE} [--lines] [--filter EXPRESSION]",
 		OnUsageError: func(cCtx *cli.Context, err error, isSubcommand bool) error {
 			_ = cli.ShowSubcommandHelp(cCtx)
 
@@ -39,6 +44,7 @@ func awsCmd() *cli.Command {
 				Name:  formatLines,
 				Usage: usageLinesOutput,
 			},
+			attributeFilterFlag(awsFilterKeys...),
 		},
 		Action: func(c *cli.Context) error {
 			path, stdout, err := resolveOutputTargets(c)
@@ -46,6 +52,11 @@ func awsCmd() *cli.Command {
 				return err
 			}
 
+			sel, err := selectorFromContext(c, awsFilterKeys...)
+			if err != nil {
+				return err
+			}
+
 			a := aws.New()
 
 			mockEnabled, err := configureAWSMock(&a)
@@ -56,7 +67,7 @@ func awsCmd() *cli.Command {
 				defer gock.Off()
 			}
 
-			data, fileName, err := awsData(&a, c.Bool(formatLines))
+			data, fileName, err := awsData(&a, c.Bool(formatLines), sel)
 			if err != nil {
 				return err
 			}
@@ -91,13 +102,20 @@ func configureAWSMock(a *aws.AWS) (bool, error) {
 	return true, nil
 }
 
-func awsData(a *aws.AWS, asLines bool) ([]byte, string, error) {
+func awsData(a *aws.AWS, asLines bool, sel filter.Selector) ([]byte, string, error) {
 	if asLines {
 		doc, _, err := a.Fetch()
 		if err != nil {
 			return nil, "", err
 		}
 
+		doc.Prefixes = slices.DeleteFunc(doc.Prefixes, func(p aws.Prefix) bool {
+			return !sel.Match(awsAttributes(p.Region, p.Service, p.NetworkBorderGroup))
+		})
+		doc.IPv6Prefixes = slices.DeleteFunc(doc.IPv6Prefixes, func(p aws.IPv6Prefix) bool {
+			return !sel.Match(awsAttributes(p.Region, p.Service, p.NetworkBorderGroup))
+		})
+
 		data, err := docToLines(doc)
 		if err != nil {
 			return nil, "", err
@@ -111,5 +129,34 @@ func awsData(a *aws.AWS, asLines bool) ([]byte, string, error) {
 		return nil, "", err
 	}
 
+	if len(sel) == 0 {
+		return data, awsFileName, nil
+	}
+
+	// filter the upstream document, rather than the parsed one, to keep its format
+	var raw aws.RawDoc
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return nil, "", err
+	}
+
+	raw.Prefixes = slices.DeleteFunc(raw.Prefixes, func(p aws.RawPrefix) bool {
+		return !sel.Match(awsAttributes(p.Region, p.Service, p.NetworkBorderGroup))
+	})
+	raw.IPv6Prefixes = slices.DeleteFunc(raw.IPv6Prefixes, func(p aws.RawIPv6Prefix) bool {
+		return !sel.Match(awsAttributes(p.Region, p.Service, p.NetworkBorderGroup))
+	})
+
+	if data, err = json.MarshalIndent(raw, "", "  "); err != nil {
+		return nil, "", err
+	}
+
 	return data, awsFileName, nil
 }
+
+func awsAttributes(region, service, networkBorderGroup string) filter.Attributes {
+	return filter.Attributes{
+		"region":               {region},
+		"service":              {service},
+		"network-border-group": {networkBorderGroup},
+	}
+}
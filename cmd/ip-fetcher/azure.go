@@ -1,9 +1,12 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/url"
+	"strings"
 
+	"github.com/jonhadfield/ip-fetcher/filter"
 	"github.com/jonhadfield/ip-fetcher/providers/azure"
 	"github.com/urfave/cli/v2"
 	"gopkg.in/h2non/gock.v1"
@@ -24,7 +27,7 @@ func azureCmd() *cli.Command {
 		Name:      providerName,
 		HelpName:  "- fetch Azure prefixes",
 		Usage:     "Microsoft Azure",
-		UsageText: "ip-fetcher azure {--stdout | --Path FILE} [--lines]",
+		UsageText: "ip-fetcher azure {--stdout | --Path FILE} [--lines] [--filter EXPRESSION]",
 		OnUsageError: func(cCtx *cli.Context, err error, isSubcommand bool) error {
 			_ = cli.ShowSubcommandHelp(cCtx)
 
@@ -43,6 +46,7 @@ func azureCmd() *cli.Command {
 				Name:  formatLines,
 				Usage: usageLinesOutput,
 			},
+			attributeFilterFlag(azureFilterKeys...),
 		},
 		Action: func(c *cli.Context) error {
 			path, stdout, err := resolveOutputTargets(c)
@@ -50,6 +54,11 @@ func azureCmd() *cli.Command {
 				return err
 			}
 
+			sel, err := selectorFromContext(c, azureFilterKeys...)
+			if err != nil {
+				return err
+			}
+
 			a := azure.New()
 
 			if isEnvEnabled("IP_FETCHER_MOCK_AZURE") {
@@ -69,20 +78,9 @@ func azureCmd() *cli.Command {
 				gock.InterceptClient(a.Client.HTTPClient)
 			}
 
-			var data []byte
-			if c.Bool(formatLines) {
-				var doc azure.Doc
-				if doc, _, err = a.Fetch(); err != nil {
-					return err
-				}
-				if data, err = docToLines(doc); err != nil {
-					return err
-				}
-			} else {
-				data, _, _, err = a.FetchData()
-				if err != nil {
-					return err
-				}
+			data, err := azureData(&a, c.Bool(formatLines), sel)
+			if err != nil {
+				return err
 			}
 
 			defaultName := fileName
@@ -98,3 +96,60 @@ func azureCmd() *cli.Command {
 		},
 	}
 }
+
+var azureFilterKeys = []string{"name", "region", "service", "platform"}
+
+func azureData(a *azure.Azure, asLines bool, sel filter.Selector) ([]byte, error) {
+	if !asLines && len(sel) == 0 {
+		data, _, _, err := a.FetchData()
+
+		return data, err
+	}
+
+	// values are selected as they are decoded, so only those selected are held
+	var values []azure.Value
+
+	doc, _, err := a.FetchStream(func(v azure.Value) error {
+		if sel.Match(filter.Attributes{
+			"name":     {v.Name},
+			"region":   {v.Properties.Region},
+			"service":  {v.Properties.SystemService},
+			"platform": {v.Properties.Platform},
+		}) {
+			values = append(values, v)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	doc.Values = values
+
+	if !asLines {
+		return json.MarshalIndent(doc, "", "  ")
+	}
+
+	// address prefixes are strings, so aren't found by docToLines, and are listed by
+	// overlapping service tags
+	var sb strings.Builder
+
+	seen := make(map[string]bool)
+
+	for _, v := range doc.Values {
+		for _, p := range v.Properties.AddressPrefixes {
+			if !seen[p] {
+				seen[p] = true
+
+				sb.WriteString(p + "\n")
+			}
+		}
+	}
+
+	if sb.Len() == 0 {
+		return nil, errNoPrefixes
+	}
+
+	return []byte(sb.String()), nil
+}
@@ -118,16 +118,12 @@ func bunnyOutput(doc bunny.Doc, format string, stdout bool, path string) error {
 		}
 	}
 
-	if stdout {
-		fmt.Printf("%s\n\n", data)
-	}
-
 	defaultName := fileNameOutputBunny
 	if format == formatLines {
 		defaultName = fileNameLinesBunny
 	}
 
-	return writeOutputs(path, false, SaveFileInput{
+	return writeOutputs(path, stdout, SaveFileInput{
 		Provider:        providerNameBunny,
 		DefaultFileName: defaultName,
 		Data:            data,
@@ -111,16 +111,12 @@ func cdn77Output(doc cdn77.Doc, format string, stdout bool, path string) error {
 		}
 	}
 
-	if stdout {
-		fmt.Printf("%s\n\n", data)
-	}
-
 	defaultName := fileNameOutputCDN77
 	if format == formatLines {
 		defaultName = fileNameLinesCDN77
 	}
 
-	return writeOutputs(path, false, SaveFileInput{
+	return writeOutputs(path, stdout, SaveFileInput{
 		Provider:        providerNameCDN77,
 		DefaultFileName: defaultName,
 		Data:            data,
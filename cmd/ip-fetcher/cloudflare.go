@@ -26,7 +26,7 @@ func cloudflareCmd() *cli.Command { //nolint:gocognit,funlen,nestif
 		Name:      providerName,
 		HelpName:  "- fetch Cloudflare ip ranges",
 		Usage:     "Cloudflare",
-		UsageText: "ip-fetcher cloudflare [-4 ipv4] [-6 ipv6] {--stdout | --Path FILE}",
+		UsageText: "ip-fetcher cloudflare [--ipv4] [--ipv6] {--stdout | --Path FILE}",
 		OnUsageError: func(cCtx *cli.Context, err error, isSubcommand bool) error {
 			// nolint:errcheck
 			_ = cli.ShowSubcommandHelp(cCtx)
@@ -42,14 +42,6 @@ func cloudflareCmd() *cli.Command { //nolint:gocognit,funlen,nestif
 				Name:  flagStdout,
 				Usage: usageWriteToStdout, Aliases: []string{"s"},
 			},
-			&cli.BoolFlag{
-				Name:  "4",
-				Usage: flagIPv4, Aliases: []string{flagIPv4},
-			},
-			&cli.BoolFlag{
-				Name:  "6",
-				Usage: "ipv6", Aliases: []string{"ipv6"},
-			},
 		},
 		Action: func(c *cli.Context) error {
 			path, stdOut, err := resolveOutputTargets(c)
@@ -82,8 +74,9 @@ func cloudflareCmd() *cli.Command { //nolint:gocognit,funlen,nestif
 				gock.InterceptClient(cf.Client.HTTPClient)
 			}
 
-			processIPv4 := c.Bool("4")
-			processIPv6 := c.Bool("6")
+			// the family flags are added with the exclusion flags
+			processIPv4 := c.Bool(flagIPv4)
+			processIPv6 := c.Bool(flagIPv6)
 			if !processIPv4 && !processIPv6 {
 				processIPv4 = true
 				processIPv6 = true
@@ -97,6 +90,10 @@ func cloudflareCmd() *cli.Command { //nolint:gocognit,funlen,nestif
 					return fetchErr
 				}
 
+				if ipv4Data, fetchErr = prepareOutput(providerName+"-ipv4", ipv4Data); fetchErr != nil {
+					return fetchErr
+				}
+
 				if stdOut {
 					fmt.Printf("%s\n", ipv4Data)
 				}
@@ -113,6 +110,10 @@ func cloudflareCmd() *cli.Command { //nolint:gocognit,funlen,nestif
 
 					messages = append(messages, fmt.Sprintf("ipv4 Data written to %s", savedPath))
 				}
+
+				if fetchErr = safety.record(providerName+"-ipv4", ipv4Data); fetchErr != nil {
+					return fetchErr
+				}
 			}
 
 			if processIPv6 { //nolint:nestif
@@ -121,6 +122,10 @@ func cloudflareCmd() *cli.Command { //nolint:gocognit,funlen,nestif
 					return fetchErr
 				}
 
+				if ipv6Data, fetchErr = prepareOutput(providerName+"-ipv6", ipv6Data); fetchErr != nil {
+					return fetchErr
+				}
+
 				if stdOut {
 					fmt.Printf("%s\n", ipv6Data)
 				}
@@ -137,6 +142,10 @@ func cloudflareCmd() *cli.Command { //nolint:gocognit,funlen,nestif
 
 					messages = append(messages, fmt.Sprintf("ipv6 Data written to %s", savedPath))
 				}
+
+				if fetchErr = safety.record(providerName+"-ipv6", ipv6Data); fetchErr != nil {
+					return fetchErr
+				}
 			}
 
 			if len(messages) > 0 {
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	_url "github.com/jonhadfield/ip-fetcher/providers/url"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	flagConfig = "config"
+	envConfig  = "IP_FETCHER_CONFIG"
+
+	// configDir and configFile name the configuration file found in the user configuration
+	// directory, e.g. $XDG_CONFIG_HOME/ip-fetcher/config.yaml, when no path is given.
+	configDir         = "ip-fetcher"
+	configFile        = "config.yaml"
+	defaultConfigPath = "$XDG_CONFIG_HOME/" + configDir + "/" + configFile
+)
+
+// Config is the content of the optional YAML configuration file.
+type Config struct {
+	// UserAgent replaces the default User-Agent sent with every request.
+	UserAgent string `yaml:"user_agent"`
+	// Headers are sent with every request.
+	Headers map[string]string `yaml:"headers"`
+	// Providers holds settings keyed by provider name, e.g. aws.
+	Providers map[string]ProviderConfig `yaml:"providers"`
+	// URLs holds settings for sources fetched by the url command.
+	URLs []URLConfig `yaml:"urls"`
+	// Publish holds settings for the publish command.
+	Publish PublishConfig `yaml:"publish"`
+	// Jobs are run, in order, by the run command.
+	Jobs []JobConfig `yaml:"jobs"`
+}
+
+type PublishConfig struct {
+	// RepoURL is the repository to publish to, in place of GITHUB_PUBLISH_URL.
+	RepoURL string `yaml:"repo_url"`
+	// Token is used to push to the repository, in place of GITHUB_TOKEN, as env:NAME or file:PATH.
+	Token string `yaml:"token"`
+	// MaxDropPercent and MaxDrop set the shrink thresholds unless set by flags.
+	MaxDropPercent *float64 `yaml:"max_drop_percent"`
+	MaxDrop        *int     `yaml:"max_drop"`
+	// Destination publishes somewhere other than RepoURL: a git remote, s3://BUCKET/PREFIX or a directory.
+	Destination string `yaml:"destination"`
+	// SSHKey is the path of the private key for SSH git remotes, decrypted with SSHKeyPassphrase,
+	// as env:NAME or file:PATH, if encrypted.
+	SSHKey           string `yaml:"ssh_key"`
+	SSHKeyPassphrase string `yaml:"ssh_key_passphrase"`
+	// Formats are published for each provider alongside its data, unless set by flag.
+	Formats []string `yaml:"formats"`
+	// SigningKey is the path of an OpenPGP or SSH private key to sign commits and files with,
+	// decrypted with SigningKeyPassphrase, as env:NAME or file:PATH, if encrypted.
+	SigningKey           string `yaml:"signing_key"`
+	SigningKeyPassphrase string `yaml:"signing_key_passphrase"`
+	// PullRequest proposes changes in a pull request, from a branch named BranchPrefix followed
+	// by the date, unless set by flag.
+	PullRequest  bool   `yaml:"pull_request"`
+	BranchPrefix string `yaml:"branch_prefix"`
+	// Providers are published, defaulting to every provider, except those in ExcludeProviders,
+	// unless set by flags.
+	Providers        []string `yaml:"providers"`
+	ExcludeProviders []string `yaml:"exclude_providers"`
+	// Files replaces the name of the file each provider's data is published to, keyed by
+	// provider, e.g. aws: ranges/aws.json.
+	Files map[string]string `yaml:"files"`
+	// Branch is published to in place of the git destination's default branch, unless set by flag.
+	Branch string `yaml:"branch"`
+	// AuthorName and AuthorEmail replace the author of commits.
+	AuthorName  string `yaml:"author_name"`
+	AuthorEmail string `yaml:"author_email"`
+	// ReadMeTemplate is the path of a template replacing that of the published README, in
+	// which {{ rows }} is replaced by the table of providers and {{ date }} by the time published.
+	ReadMeTemplate string `yaml:"readme_template"`
+	// History archives a daily snapshot of each provider's data, keeping those from the last
+	// HistoryRetentionDays days, or all of them if zero, unless set by flags.
+	History              bool `yaml:"history"`
+	HistoryRetentionDays int  `yaml:"history_retention_days"`
+	// StaleAfter is how long a provider may go without a successful update before it is
+	// flagged as stale, e.g. 72h, unless set by flag.
+	StaleAfter *time.Duration `yaml:"stale_after"`
+	// SingleCommit publishes every change in one commit, unless set by flag.
+	SingleCommit bool `yaml:"single_commit"`
+	// S3 holds settings for s3:// destinations.
+	S3 S3Config `yaml:"s3"`
+}
+
+type S3Config struct {
+	// Endpoint is the object store's url, defaulting to AWS S3 in Region.
+	Endpoint string `yaml:"endpoint"`
+	Region   string `yaml:"region"`
+	// AccessKeyID and SecretAccessKey, as env:NAME or file:PATH, replace those set by the
+	// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables.
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+}
+
+type ProviderConfig struct {
+	// Headers are sent with every request made by the provider.
+	Headers map[string]string `yaml:"headers"`
+}
+
+type URLConfig struct {
+	// URL matches the urls requested by the url command with the same scheme and host, and a path
+	// equal to its own or within it, as matchSource describes.
+	URL string `yaml:"url"`
+	// Headers are sent with requests for matching urls.
+	Headers map[string]string `yaml:"headers"`
+	// Auth authenticates requests for matching urls.
+	Auth *AuthConfig `yaml:"auth"`
+	// Format is the format of the content of matching urls, e.g. json.
+	Format string `yaml:"format"`
+	// Selector locates the prefixes within JSON or CSV content.
+	Selector string `yaml:"selector"`
+}
+
+// configPath returns the path of the configuration file given by flag or environment variable
+// or, failing that, the file in the user configuration directory if it exists.
+func configPath(c *cli.Context) string {
+	if path := strings.TrimSpace(c.String(flagConfig)); path != "" {
+		return path
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	path := filepath.Join(dir, configDir, configFile)
+	if _, err = os.Stat(path); err != nil {
+		return ""
+	}
+
+	return path
+}
+
+// loadConfig reads the configuration file at path. An empty path returns an empty configuration.
+func loadConfig(path string) (Config, error) {
+	var cfg Config
+
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	// an empty file is a valid, empty, configuration
+	if err = dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return cfg, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// matchSource reports whether the configured url matches the source u. Both must have the same
+// scheme and host, ignoring case, and u's path must equal the configured path or lie beneath it,
+// whole segments at a time. A configured query must equal u's. Local paths match by path alone.
+func matchSource(configured, u string) bool {
+	if configured == "" {
+		return false
+	}
+
+	cu, err := _url.ParseSource(configured)
+	if err != nil {
+		return false
+	}
+
+	su, err := _url.ParseSource(u)
+	if err != nil {
+		return false
+	}
+
+	if !strings.EqualFold(cu.Scheme, su.Scheme) || !strings.EqualFold(cu.Host, su.Host) {
+		return false
+	}
+
+	if cu.RawQuery != "" && cu.RawQuery != su.RawQuery {
+		return false
+	}
+
+	configuredPath, path := cmp.Or(cu.Path, "/"), cmp.Or(su.Path, "/")
+	if configuredPath == path {
+		return true
+	}
+
+	if !strings.HasSuffix(configuredPath, "/") {
+		configuredPath += "/"
+	}
+
+	return strings.HasPrefix(path, configuredPath)
+}
+
+// urlHeaders returns the configured headers for every entry matching u.
+// Where entries set the same header, the longest, most specific, url wins.
+func (c Config) urlHeaders(u string) http.Header {
+	var matches []URLConfig
+
+	for _, uc := range c.URLs {
+		if matchSource(uc.URL, u) {
+			matches = append(matches, uc)
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b URLConfig) int {
+		return cmp.Compare(len(a.URL), len(b.URL))
+	})
+
+	res := http.Header{}
+
+	for _, uc := range matches {
+		for k, v := range uc.Headers {
+			res.Set(k, v)
+		}
+	}
+
+	return res
+}
+
+// urlFormat returns the format and selector of the entry with the longest url matching u that sets a format.
+func (c Config) urlFormat(u string) (string, string) {
+	var (
+		format, selector string
+		matched          int
+	)
+
+	for _, uc := range c.URLs {
+		if uc.Format == "" || !matchSource(uc.URL, u) || len(uc.URL) < matched {
+			continue
+		}
+
+		format, selector, matched = uc.Format, uc.Selector, len(uc.URL)
+	}
+
+	return format, selector
+}
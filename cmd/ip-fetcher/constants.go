@@ -11,6 +11,10 @@ const (
 	flagStdout = "stdout"
 	flagFormat = "format"
 	flagIPv4   = "ipv4"
+	flagIPv6   = "ipv6"
+
+	flagSourceFormat = "source-format"
+	flagSelector     = "selector"
 
 	formatLines = "lines"
 	formatJSON  = "json"
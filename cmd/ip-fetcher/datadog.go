@@ -108,16 +108,12 @@ func datadogOutput(doc datadog.Doc, format string, stdout bool, path string) err
 		}
 	}
 
-	if stdout {
-		fmt.Printf("%s\n\n", data)
-	}
-
 	defaultName := fileNameOutputDatadog
 	if format == formatLines {
 		defaultName = fileNameLinesDatadog
 	}
 
-	return writeOutputs(path, false, SaveFileInput{
+	return writeOutputs(path, stdout, SaveFileInput{
 		Provider:        providerNameDatadog,
 		DefaultFileName: defaultName,
 		Data:            data,
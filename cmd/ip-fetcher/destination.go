@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/jonhadfield/ip-fetcher/publisher"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	flagDestination      = "destination"
+	flagSSHKey           = "ssh-key"
+	flagSSHKeyPassphrase = "ssh-key-passphrase"
+	flagS3Endpoint       = "s3-endpoint"
+	flagS3Region         = "s3-region"
+
+	envDestination = "IP_FETCHER_PUBLISH_DESTINATION"
+	envSSHKey      = "IP_FETCHER_SSH_KEY"
+	envGitHubToken = "GITHUB_TOKEN"
+)
+
+func destinationFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name: flagDestination,
+			Usage: "where to publish: a git remote (https://, ssh://, git@ or file://), s3://BUCKET/PREFIX " +
+				"or a directory (default: GITHUB_PUBLISH_URL)",
+			EnvVars: []string{envDestination},
+		},
+		&cli.StringFlag{
+			Name:      flagSSHKey,
+			Usage:     "private key for SSH git remotes",
+			EnvVars:   []string{envSSHKey},
+			TakesFile: true,
+		},
+		&cli.StringFlag{
+			Name:  flagSSHKeyPassphrase,
+			Usage: "passphrase of an encrypted SSH key, as env:NAME or file:PATH",
+		},
+		&cli.StringFlag{
+			Name:    flagS3Endpoint,
+			Usage:   "url of an S3-compatible object store (default: AWS S3)",
+			EnvVars: []string{"AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"},
+		},
+		&cli.StringFlag{
+			Name:    flagS3Region,
+			Usage:   "region of the object store",
+			EnvVars: []string{"AWS_REGION", "AWS_DEFAULT_REGION"},
+		},
+	}
+}
+
+// publishDestination returns the destination set by flags or the configuration file, or nil
+// to publish to the repository at GITHUB_PUBLISH_URL.
+func publishDestination(c *cli.Context, pc PublishConfig) (publisher.Destination, error) {
+	dest := firstNonEmpty(c.String(flagDestination), pc.Destination)
+	if dest == "" {
+		return nil, nil
+	}
+
+	switch {
+	case strings.HasPrefix(dest, "s3://"):
+		return s3Destination(c, pc, dest)
+	case isGitRemote(dest):
+		auth, err := gitAuth(c, pc, dest)
+		if err != nil {
+			return nil, err
+		}
+
+		return publisher.NewGitDestination(dest, auth), nil
+	default:
+		return publisher.NewDirDestination(dest), nil
+	}
+}
+
+func isGitRemote(dest string) bool {
+	for _, prefix := range []string{"https://", "http://", "ssh://", "git://", "file://", "git@"} {
+		if strings.HasPrefix(dest, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// gitAuth returns the SSH key authentication for SSH remotes and the token authentication, if
+// a token is set, for HTTPS remotes.
+func gitAuth(c *cli.Context, pc PublishConfig, dest string) (transport.AuthMethod, error) {
+	if strings.HasPrefix(dest, "ssh://") || strings.HasPrefix(dest, "git@") {
+		key := firstNonEmpty(c.String(flagSSHKey), pc.SSHKey)
+		if key == "" {
+			return nil, errors.New("an ssh key is required to publish to " + dest)
+		}
+
+		var passphrase string
+
+		if ref := firstNonEmpty(c.String(flagSSHKeyPassphrase), pc.SSHKeyPassphrase); ref != "" {
+			var err error
+			if passphrase, err = readSecret(ref); err != nil {
+				return nil, fmt.Errorf("ssh key passphrase: %w", err)
+			}
+		}
+
+		var user string
+		if u, err := url.Parse(dest); err == nil && u.User != nil {
+			user = u.User.Username()
+		}
+
+		return publisher.SSHKeyAuth(user, key, passphrase)
+	}
+
+	if !strings.HasPrefix(dest, "https://") && !strings.HasPrefix(dest, "http://") {
+		return nil, nil
+	}
+
+	token, err := publishToken(pc)
+	if err != nil || token == "" {
+		return nil, err
+	}
+
+	return publisher.TokenAuth(token), nil
+}
+
+// publishToken returns the token set by the configuration file or GITHUB_TOKEN.
+func publishToken(pc PublishConfig) (string, error) {
+	if pc.Token == "" {
+		return strings.TrimSpace(os.Getenv(envGitHubToken)), nil
+	}
+
+	token, err := readSecret(pc.Token)
+	if err != nil {
+		return "", fmt.Errorf("publish token: %w", err)
+	}
+
+	return token, nil
+}
+
+func s3Destination(c *cli.Context, pc PublishConfig, dest string) (publisher.Destination, error) {
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(dest, "s3://"), "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("invalid destination %s: missing bucket", dest)
+	}
+
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	region := firstNonEmpty(c.String(flagS3Region), pc.S3.Region, "us-east-1")
+	endpoint := firstNonEmpty(c.String(flagS3Endpoint), pc.S3.Endpoint, "https://s3."+region+".amazonaws.com")
+
+	d := publisher.NewS3Destination(endpoint, region, bucket, prefix)
+	d.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	d.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	d.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+
+	for _, cred := range []struct {
+		ref   string
+		value *string
+	}{
+		{pc.S3.AccessKeyID, &d.AccessKeyID},
+		{pc.S3.SecretAccessKey, &d.SecretAccessKey},
+	} {
+		if cred.ref == "" {
+			continue
+		}
+
+		secret, err := readSecret(cred.ref)
+		if err != nil {
+			return nil, fmt.Errorf("s3 credentials: %w", err)
+		}
+
+		*cred.value = secret
+	}
+
+	return d, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
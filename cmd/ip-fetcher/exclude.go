@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jonhadfield/ip-fetcher/filter"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	flagExcludeBogons = "exclude-bogons"
+	flagExcludeFile   = "exclude-file"
+
+	envExcludeBogons = "IP_FETCHER_EXCLUDE_BOGONS"
+	envExcludeFile   = "IP_FETCHER_EXCLUDE_FILE"
+)
+
+//nolint:gochecknoglobals
+var (
+	// exclusions filters the output of the running command. It is nil when nothing is excluded.
+	exclusions *filter.Filter
+	// families excludes the address family not selected for the running command's output. It is
+	// nil when both are selected.
+	families *filter.Filter
+)
+
+func exclusionFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:    flagExcludeBogons,
+			Usage:   "exclude private, reserved, documentation and other bogon ranges",
+			EnvVars: []string{envExcludeBogons},
+		},
+		&cli.StringSliceFlag{
+			Name:      flagExcludeFile,
+			Usage:     "exclude the ranges listed in a file, such as a full bogon list (repeatable)",
+			EnvVars:   []string{envExcludeFile},
+			TakesFile: true,
+		},
+		&cli.BoolFlag{
+			Name:    flagIPv4,
+			Usage:   "only output IPv4 prefixes",
+			Aliases: []string{"4"},
+		},
+		&cli.BoolFlag{
+			Name:    flagIPv6,
+			Usage:   "only output IPv6 prefixes",
+			Aliases: []string{"6"},
+		},
+	}
+}
+
+// withExclusions adds the exclusion and address family flags to a command and, before it runs,
+// loads the ranges its output is filtered by.
+func withExclusions(cmd *cli.Command) {
+	cmd.Flags = append(cmd.Flags, exclusionFlags()...)
+
+	before := cmd.Before
+
+	cmd.Before = func(c *cli.Context) error {
+		f, err := exclusionFilter(c.Bool(flagExcludeBogons), c.StringSlice(flagExcludeFile))
+		if err != nil {
+			return err
+		}
+
+		exclusions = f
+
+		if family := filter.FamilyOf(c.Bool(flagIPv4), c.Bool(flagIPv6)); family != filter.AnyFamily {
+			families = filter.NewFamily(family)
+		}
+
+		if before != nil {
+			return before(c)
+		}
+
+		return nil
+	}
+}
+
+// exclusionFilter returns the filter excluding the bogons, if requested, and the ranges
+// listed in the files. It returns nil when nothing is excluded.
+func exclusionFilter(bogons bool, files []string) (*filter.Filter, error) {
+	var entries []filter.Entry
+
+	if bogons {
+		entries = filter.Bogons()
+	}
+
+	for _, path := range files {
+		fileEntries, err := filter.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load exclusions: %w", err)
+		}
+
+		entries = append(entries, fileEntries...)
+	}
+
+	if !bogons && len(files) == 0 {
+		return nil, nil
+	}
+
+	return filter.New(entries...), nil
+}
+
+// excludeData removes the prefixes of the family not selected, and the excluded prefixes, from
+// data, noting how many were excluded on stderr.
+func excludeData(data []byte) ([]byte, error) {
+	data, _, err := families.Data(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select address family: %w", err)
+	}
+
+	data, removed, err := exclusions.Data(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exclude prefixes: %w", err)
+	}
+
+	if removed > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "excluded %d bogon or listed prefixes\n", removed)
+	}
+
+	return data, nil
+}
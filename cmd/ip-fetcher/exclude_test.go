@@ -0,0 +1,193 @@
+package main_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mainpkg "github.com/jonhadfield/ip-fetcher/cmd/ip-fetcher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLCmdExcludeBogons(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("1.1.1.0/24\n10.0.0.0/8\n100.64.0.1\n41.0.0.0/8\n2606:4700::/32\nfe80::1\n"))
+	}))
+	defer ts.Close()
+
+	tDir := t.TempDir()
+	bogons := filepath.Join(tDir, "fullbogons.txt")
+	require.NoError(t, os.WriteFile(bogons, []byte("# full bogons\n41.0.0.0/8\n"), 0o600))
+
+	app := mainpkg.GetApp()
+
+	os.Args = []string{"ip-fetcher", "url", "--Path", tDir, "--exclude-bogons", ts.URL}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err := os.ReadFile(filepath.Join(tDir, "ips.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "1.1.1.0/24\n41.0.0.0/8\n2606:4700::/32", string(data))
+
+	os.Args = []string{
+		"ip-fetcher", "url", "--Path", tDir, "--exclude-bogons", "--exclude-file", bogons, ts.URL,
+	}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err = os.ReadFile(filepath.Join(tDir, "ips.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "1.1.1.0/24\n2606:4700::/32", string(data))
+
+	// nothing is excluded unless requested
+	os.Args = []string{"ip-fetcher", "url", "--Path", tDir, ts.URL}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err = os.ReadFile(filepath.Join(tDir, "ips.txt"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), "10.0.0.0/8")
+
+	os.Args = []string{"ip-fetcher", "url", "--Path", tDir, "--exclude-file", filepath.Join(tDir, "missing"), ts.URL}
+	require.ErrorContains(t, app.Run(os.Args), "failed to load exclusions")
+}
+
+func TestAWSCmdExcludeFile(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	t.Setenv("IP_FETCHER_MOCK_AWS", "true")
+
+	tDir := t.TempDir()
+	exclude := filepath.Join(tDir, "exclude.txt")
+	require.NoError(t, os.WriteFile(exclude, []byte("13.34.37.64/27\n"), 0o600))
+
+	app := mainpkg.GetApp()
+
+	os.Args = []string{"ip-fetcher", "aws", "--Path", tDir, "--exclude-file", exclude}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err := os.ReadFile(filepath.Join(tDir, "ip-ranges.json"))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "13.34.37.65/27")
+	require.Contains(t, string(data), "3.5.140.0/22")
+	require.Contains(t, string(data), "\"syncToken\": \"1657291988\"")
+}
+
+func TestProviderCmdsSelectFamily(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	t.Setenv("IP_FETCHER_MOCK_AWS", "true")
+	t.Setenv("IP_FETCHER_MOCK_GCP", "true")
+	t.Setenv("IP_FETCHER_MOCK_ZSCALER", "true")
+
+	tDir := t.TempDir()
+	app := mainpkg.GetApp()
+
+	os.Args = []string{"ip-fetcher", "aws", "--Path", tDir, "--lines", "--ipv4"}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err := os.ReadFile(filepath.Join(tDir, "aws-prefixes.txt"))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), ":")
+	require.Contains(t, string(data), "3.5.140.0/22")
+
+	os.Args = []string{"ip-fetcher", "aws", "--Path", tDir, "-6"}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err = os.ReadFile(filepath.Join(tDir, "ip-ranges.json"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"prefixes": []`)
+	require.Contains(t, string(data), "2600:1f70:4000:400::/56")
+
+	// csv is formatted after the family is selected
+	os.Args = []string{"ip-fetcher", "gcp", "--Path", filepath.Join(tDir, "gcp.csv"), "--format", "csv", "--ipv4"}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err = os.ReadFile(filepath.Join(tDir, "gcp.csv"))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), ":")
+	require.False(t, strings.HasSuffix(string(data), ","))
+
+	// both families are output when both or neither are selected
+	os.Args = []string{"ip-fetcher", "gcp", "--Path", tDir, "--lines", "--ipv4", "--ipv6"}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err = os.ReadFile(filepath.Join(tDir, "gcp-prefixes.txt"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), "34.80.0.0/15")
+	require.Contains(t, string(data), "2600:1900:4180::/44")
+
+	// the family is selected in output written to stdout
+	old := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	os.Stdout = w
+
+	outC := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		outC <- buf.String()
+	}()
+
+	os.Args = []string{"ip-fetcher", "zscaler", "--stdout", "--ipv6"}
+	runErr := app.Run(os.Args)
+
+	_ = w.Close()
+	os.Stdout = old
+	out := <-outC
+
+	require.NoError(t, runErr)
+	require.Contains(t, out, "2a03:eec0:3400::/40")
+	require.NotContains(t, out, "87.58.66.0/23")
+}
+
+func TestAtlassianCmdExcludesOnce(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	t.Setenv("IP_FETCHER_MOCK_ATLASSIAN", "true")
+
+	tDir := t.TempDir()
+	exclude := filepath.Join(tDir, "exclude.txt")
+	require.NoError(t, os.WriteFile(exclude, []byte("13.52.5.0/24\n"), 0o600))
+
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	rOut, wOut, err := os.Pipe()
+	require.NoError(t, err)
+	rErr, wErr, err := os.Pipe()
+	require.NoError(t, err)
+
+	os.Stdout, os.Stderr = wOut, wErr
+
+	read := func(r io.Reader) chan string {
+		c := make(chan string)
+		go func() {
+			var buf bytes.Buffer
+			_, _ = io.Copy(&buf, r)
+			c <- buf.String()
+		}()
+
+		return c
+	}
+	outC, errC := read(rOut), read(rErr)
+
+	app := mainpkg.GetApp()
+	os.Args = []string{"ip-fetcher", "atlassian", "--lines", "--stdout", "--Path", tDir, "--exclude-file", exclude}
+	runErr := app.Run(os.Args)
+
+	_ = wOut.Close()
+	_ = wErr.Close()
+	os.Stdout, os.Stderr = oldStdout, oldStderr
+	stdout, stderr := <-outC, <-errC
+
+	require.NoError(t, runErr)
+	require.NotContains(t, stdout, "13.52.5.0/24")
+	require.Contains(t, stdout, "18.205.93.0/25")
+	require.Equal(t, 1, strings.Count(stderr, "excluded"))
+	require.Contains(t, stderr, "excluded 1 bogon or listed prefixes")
+}
@@ -111,16 +111,12 @@ func fastlyOutput(doc fastly.Doc, format string, stdout bool, path string) error
 		}
 	}
 
-	if stdout {
-		fmt.Printf("%s\n\n", data)
-	}
-
 	defaultName := fileNameOutputFastly
 	if format == formatLines {
 		defaultName = fileNameLines
 	}
 
-	return writeOutputs(path, false, SaveFileInput{
+	return writeOutputs(path, stdout, SaveFileInput{
 		Provider:        providerNameFastly,
 		DefaultFileName: defaultName,
 		Data:            data,
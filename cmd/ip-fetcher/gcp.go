@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/jonhadfield/ip-fetcher/filter"
 	"github.com/jonhadfield/ip-fetcher/providers/gcp"
 	"github.com/urfave/cli/v2"
 	"gopkg.in/h2non/gock.v1"
@@ -20,12 +22,14 @@ const (
 	fileNameLinesGCP  = "gcp-prefixes.txt"
 )
 
+var gcpFilterKeys = []string{"scope", "service"}
+
 func gcpCmd() *cli.Command {
 	return &cli.Command{
 		Name:      providerNameGCP,
 		HelpName:  "- fetch GCP prefixes",
 		Usage:     "Google Cloud Platform",
-		UsageText: "ip-fetcher gcp {--stdout | --Path FILE} [--lines]",
+		UsageText: "ip-fetcher gcp {--stdout | --Path FILE} [--lines] [--filter EXPRESSION]",
 		OnUsageError: func(cCtx *cli.Context, err error, isSubcommand bool) error {
 			_ = cli.ShowSubcommandHelp(cCtx)
 
@@ -48,6 +52,7 @@ func gcpCmd() *cli.Command {
 				Name:  formatLines,
 				Usage: usageLinesOutput,
 			},
+			attributeFilterFlag(gcpFilterKeys...),
 		},
 		Action: func(c *cli.Context) error {
 			path, stdout, err := resolveOutputTargets(c)
@@ -55,6 +60,11 @@ func gcpCmd() *cli.Command {
 				return err
 			}
 
+			sel, err := selectorFromContext(c, gcpFilterKeys...)
+			if err != nil {
+				return err
+			}
+
 			a := gcp.New()
 
 			if isEnvEnabled("IP_FETCHER_MOCK_GCP") {
@@ -79,6 +89,16 @@ func gcpCmd() *cli.Command {
 				format = formatLines
 			}
 
+			doc.IPv4Prefixes = slices.DeleteFunc(doc.IPv4Prefixes, func(e gcp.IPv4Entry) bool {
+				return !sel.Match(filter.Attributes{"scope": {e.Scope}, "service": {e.Service}})
+			})
+			doc.IPv6Prefixes = slices.DeleteFunc(doc.IPv6Prefixes, func(e gcp.IPv6Entry) bool {
+				return !sel.Match(filter.Attributes{"scope": {e.Scope}, "service": {e.Service}})
+			})
+
+			// select the family before formatting, as csv can't be filtered by line
+			families.Doc(&doc)
+
 			return output(doc, format, stdout, path)
 		},
 	}
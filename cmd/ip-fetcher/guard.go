@@ -0,0 +1,198 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jonhadfield/ip-fetcher/guard"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	flagStateDir       = "state-dir"
+	flagMaxDropPercent = "max-drop-percent"
+	flagMaxDrop        = "max-drop"
+	flagForce          = "force"
+
+	envStateDir       = "IP_FETCHER_STATE_DIR"
+	envMaxDropPercent = "IP_FETCHER_MAX_DROP_PERCENT"
+	envMaxDrop        = "IP_FETCHER_MAX_DROP"
+	envForce          = "IP_FETCHER_FORCE"
+
+	usageForce = "write or publish even if the number of prefixes dropped by more than allowed"
+)
+
+// keyedFlags are the flags selecting which prefixes a command outputs. Output is only compared
+// with earlier output made with the same values, and the same arguments.
+//
+//nolint:gochecknoglobals
+var keyedFlags = []string{flagFilter, flagSelector, flagSourceFormat, flagIPv4, flagIPv6, flagExcludeBogons, flagExcludeFile}
+
+// shrinkGuard refuses output of the running command that has shrunk too far since the last
+// output recorded in its state directory. Nothing is checked without a state directory.
+type shrinkGuard struct {
+	state      *guard.State
+	thresholds guard.Thresholds
+	force      bool
+	// options identifies the keyed flags and arguments the command was run with. It is empty
+	// when none were given.
+	options string
+}
+
+var safety shrinkGuard //nolint:gochecknoglobals
+
+// thresholdFlags returns the flags setting how far the number of prefixes may drop.
+func thresholdFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.Float64Flag{
+			Name:    flagMaxDropPercent,
+			Usage:   "refuse to write or publish if the number of prefixes drops by more than this percentage (0 to disable)",
+			Value:   guard.DefaultMaxDropPercent,
+			EnvVars: []string{envMaxDropPercent},
+		},
+		&cli.IntFlag{
+			Name:    flagMaxDrop,
+			Usage:   "refuse to write or publish if the number of prefixes drops by more than this many (0 to disable)",
+			EnvVars: []string{envMaxDrop},
+		},
+		&cli.BoolFlag{
+			Name:    flagForce,
+			Usage:   usageForce,
+			EnvVars: []string{envForce},
+		},
+	}
+}
+
+func thresholdsFromContext(c *cli.Context) guard.Thresholds {
+	return guard.Thresholds{
+		MaxDropPercent: c.Float64(flagMaxDropPercent),
+		MaxDrop:        c.Int(flagMaxDrop),
+	}
+}
+
+// withShrinkGuard adds the state directory and threshold flags to a command and, before it
+// runs, configures the checks made on its output.
+func withShrinkGuard(cmd *cli.Command) {
+	cmd.Flags = append(cmd.Flags, &cli.StringFlag{
+		Name:      flagStateDir,
+		Usage:     "directory recording the number of prefixes last written, to compare new output with",
+		EnvVars:   []string{envStateDir},
+		TakesFile: true,
+	})
+	cmd.Flags = append(cmd.Flags, thresholdFlags()...)
+
+	before := cmd.Before
+
+	cmd.Before = func(c *cli.Context) error {
+		safety = shrinkGuard{thresholds: thresholdsFromContext(c), force: c.Bool(flagForce), options: optionsKey(c)}
+
+		if dir := strings.TrimSpace(c.String(flagStateDir)); dir != "" {
+			safety.state = &guard.State{Dir: dir}
+		}
+
+		if before != nil {
+			return before(c)
+		}
+
+		return nil
+	}
+}
+
+// optionsKey returns a short hash of the keyed flags set for the command and its arguments, or
+// an empty string if there are none.
+func optionsKey(c *cli.Context) string {
+	var options []string
+
+	for _, name := range keyedFlags {
+		if !c.IsSet(name) {
+			continue
+		}
+
+		value := c.Value(name)
+		if files, ok := value.(cli.StringSlice); ok {
+			value = files.Value()
+		}
+
+		options = append(options, fmt.Sprintf("--%s=%v", name, value))
+	}
+
+	options = append(options, c.Args().Slice()...)
+
+	if len(options) == 0 {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(options, "\n")))
+
+	return hex.EncodeToString(sum[:6])
+}
+
+// key returns the name the output with the given name is recorded under, for the options the
+// command was run with.
+func (g shrinkGuard) key(name string) string {
+	if g.options == "" {
+		return name
+	}
+
+	return name + "-" + g.options
+}
+
+// check returns an error if data holds too few prefixes compared to the last output recorded
+// with the given name, unless forced.
+func (g shrinkGuard) check(name string, data []byte) error {
+	if g.state == nil {
+		return nil
+	}
+
+	snap, ok, err := g.state.Load(g.key(name))
+	if err != nil || !ok {
+		return err
+	}
+
+	err = g.thresholds.Check(name, snap.Prefixes, guard.Count(data))
+	if !errors.Is(err, guard.ErrShrink) {
+		return err
+	}
+
+	if g.force {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+
+		return nil
+	}
+
+	return fmt.Errorf("refusing to write: %w (use --%s to override)", err, flagForce)
+}
+
+// record saves the number of prefixes in the output with the given name, for the options the
+// command was run with.
+func (g shrinkGuard) record(name string, data []byte) error {
+	if g.state == nil {
+		return nil
+	}
+
+	if err := g.state.Save(g.key(name), guard.Snapshot{Prefixes: guard.Count(data), Updated: time.Now().UTC()}); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+
+	return nil
+}
+
+// prepareOutput removes the excluded prefixes from the output with the given name and checks
+// it has not shrunk too far.
+func prepareOutput(name string, data []byte) ([]byte, error) {
+	data, err := excludeData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = safety.check(name, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
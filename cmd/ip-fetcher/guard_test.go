@@ -0,0 +1,80 @@
+package main_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mainpkg "github.com/jonhadfield/ip-fetcher/cmd/ip-fetcher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLCmdRefusesShrinkage(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	body := "192.0.2.0/24\n198.51.100.0/24\n203.0.113.0/24\n192.0.2.1\n"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	tDir := t.TempDir()
+	stateDir := filepath.Join(tDir, "state")
+	out := filepath.Join(tDir, "ips.txt")
+
+	app := mainpkg.GetApp()
+	run := func(args ...string) error {
+		os.Args = append([]string{"ip-fetcher", "url", "--Path", tDir, "--state-dir", stateDir}, args...)
+
+		return app.Run(os.Args)
+	}
+
+	require.NoError(t, run(ts.URL))
+
+	states, err := filepath.Glob(filepath.Join(stateDir, "url-*.json"))
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+
+	// a drop of 25% is allowed by default
+	body = "192.0.2.0/24\n198.51.100.0/24\n203.0.113.0/24\n"
+	require.NoError(t, run(ts.URL))
+
+	// a drop of two thirds is not, and the previous output is kept
+	body = "192.0.2.0/24\n"
+	err = run(ts.URL)
+	require.ErrorContains(t, err, "refusing to write")
+	require.ErrorContains(t, err, "dropped from 3 to 1")
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, 3, strings.Count(string(data), "/24"))
+
+	// thresholds are configurable
+	require.ErrorContains(t, run("--max-drop-percent", "0", "--max-drop", "1", ts.URL), "allowed 1 prefixes")
+	require.NoError(t, run("--max-drop-percent", "70", ts.URL))
+
+	// and overridden
+	body = "198.51.100.0/24\n203.0.113.0/24\n"
+	require.NoError(t, run(ts.URL))
+
+	body = "198.51.100.0/24\n"
+	require.ErrorContains(t, run("--max-drop-percent", "10", ts.URL), "refusing to write")
+	require.NoError(t, run("--max-drop-percent", "10", "--force", ts.URL))
+
+	data, err = os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, "198.51.100.0/24", string(data))
+
+	// output made with other options is compared with earlier output made with the same options
+	body = "192.0.2.0/24\n198.51.100.0/24\n203.0.113.0/24\n"
+	require.NoError(t, run(ts.URL))
+	require.NoError(t, run("--exclude-bogons", ts.URL))
+
+	states, err = filepath.Glob(filepath.Join(stateDir, "url-*.json"))
+	require.NoError(t, err)
+	require.Len(t, states, 2)
+}
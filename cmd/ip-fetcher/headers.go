@@ -0,0 +1,231 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jonhadfield/ip-fetcher/internal/web"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	flagUserAgent = "user-agent"
+	flagHeader    = "header"
+	flagURLHeader = "url-header"
+
+	envUserAgent = "IP_FETCHER_USER_AGENT"
+	envHeaders   = "IP_FETCHER_HEADERS"
+
+	usageHeader = "header to send, as 'Name: value' (repeatable)"
+)
+
+const (
+	// configKey is the key the loaded Config is stored under in the app metadata.
+	configKey = "config"
+	// configPathKey is the key the path of the loaded Config is stored under in the app metadata.
+	configPathKey = "configPath"
+)
+
+func globalFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:      flagConfig,
+			Usage:     "path to a YAML configuration file (default: " + defaultConfigPath + ")",
+			EnvVars:   []string{envConfig},
+			TakesFile: true,
+		},
+		&cli.StringFlag{
+			Name:    flagUserAgent,
+			Usage:   "User-Agent to send with every request",
+			EnvVars: []string{envUserAgent},
+		},
+		&cli.StringSliceFlag{
+			Name:    flagHeader,
+			Usage:   usageHeader + " with every request",
+			Aliases: []string{"H"},
+			EnvVars: []string{envHeaders},
+		},
+	}
+}
+
+// providerHeaderFlag returns the flag used to set headers for a single provider's requests.
+func providerHeaderFlag(provider string) cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:    flagHeader,
+		Usage:   usageHeader + " with " + provider + " requests",
+		Aliases: []string{"H"},
+		EnvVars: []string{providerHeadersEnv(provider)},
+	}
+}
+
+func providerHeadersEnv(provider string) string {
+	return "IP_FETCHER_" + strings.ToUpper(strings.ReplaceAll(provider, "-", "_")) + "_HEADERS"
+}
+
+// configureRequests loads the configuration file and sets the User-Agent and headers
+// sent with every request, and those sent by each provider.
+func configureRequests(c *cli.Context) error {
+	path := configPath(c)
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+
+	c.App.Metadata[configKey] = cfg
+	c.App.Metadata[configPathKey] = path
+
+	exclusions = nil
+	families = nil
+	safety = shrinkGuard{}
+
+	web.ResetHeaders()
+
+	ua := c.String(flagUserAgent)
+	if ua == "" {
+		ua = cfg.UserAgent
+	}
+
+	if ua == "" {
+		ua = web.UserAgentForVersion(appVersion())
+	}
+
+	web.SetUserAgent(ua)
+
+	headers, err := mergeHeaders(cfg.Headers, c.StringSlice(flagHeader))
+	if err != nil {
+		return err
+	}
+
+	web.SetHeaders(headers)
+
+	for name, pc := range cfg.Providers {
+		if headers, err = mergeHeaders(pc.Headers, nil); err != nil {
+			return fmt.Errorf("provider %s: %w", name, err)
+		}
+
+		web.SetProviderHeaders(name, headers)
+	}
+
+	return nil
+}
+
+// withProviderHeaders adds the header flag to a provider command and, before it runs,
+// adds the header values to those configured for the provider.
+func withProviderHeaders(cmd *cli.Command) {
+	cmd.Flags = append(cmd.Flags, providerHeaderFlag(cmd.Name))
+
+	before := cmd.Before
+
+	cmd.Before = func(c *cli.Context) error {
+		cfg := configFromContext(c)
+
+		headers, err := mergeHeaders(cfg.Providers[cmd.Name].Headers, c.StringSlice(flagHeader))
+		if err != nil {
+			return err
+		}
+
+		web.SetProviderHeaders(cmd.Name, headers)
+
+		if before != nil {
+			return before(c)
+		}
+
+		return nil
+	}
+}
+
+func configFromContext(c *cli.Context) Config {
+	if c.App == nil || c.App.Metadata == nil {
+		return Config{}
+	}
+
+	cfg, _ := c.App.Metadata[configKey].(Config)
+
+	return cfg
+}
+
+func appVersion() string {
+	if tag != "" {
+		return tag
+	}
+
+	return version
+}
+
+// mergeHeaders returns the configured headers overridden by any given as 'Name: value' strings.
+func mergeHeaders(configured map[string]string, values []string) (http.Header, error) {
+	res := http.Header{}
+
+	for k, v := range configured {
+		if !validHeaderName(k) {
+			return nil, fmt.Errorf("invalid header name: %q", k)
+		}
+
+		res.Set(k, v)
+	}
+
+	overrides := http.Header{}
+
+	for _, v := range values {
+		name, value, err := parseHeader(v)
+		if err != nil {
+			return nil, err
+		}
+
+		overrides.Add(name, value)
+	}
+
+	for k, v := range overrides {
+		res[k] = v
+	}
+
+	return res, nil
+}
+
+// parseHeader splits a header given as 'Name: value'.
+func parseHeader(s string) (string, string, error) {
+	name, value, found := strings.Cut(s, ":")
+	if !found {
+		return "", "", errors.New("invalid header: expected 'Name: value'")
+	}
+
+	name = strings.TrimSpace(name)
+	if !validHeaderName(name) {
+		return "", "", fmt.Errorf("invalid header name: %q", name)
+	}
+
+	return name, strings.TrimSpace(value), nil
+}
+
+// parseURLHeader splits a header for a single url given as 'URL=Name: value'.
+// Header names cannot contain '=', so the first '=' followed by a valid header ends the url.
+func parseURLHeader(s string) (string, string, string, error) {
+	for i, r := range s {
+		if r != '=' || i == 0 {
+			continue
+		}
+
+		if name, value, err := parseHeader(s[i+1:]); err == nil {
+			return s[:i], name, value, nil
+		}
+	}
+
+	return "", "", "", errors.New("invalid url header: expected 'URL=Name: value'")
+}
+
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,100 @@
+package main_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	mainpkg "github.com/jonhadfield/ip-fetcher/cmd/ip-fetcher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLCmdRequestHeaders(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	var (
+		mu       sync.Mutex
+		received = map[string]http.Header{}
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received[r.URL.Path] = r.Header.Clone()
+		mu.Unlock()
+
+		_, _ = w.Write([]byte("9.9.9.0/24\n"))
+	}))
+	defer ts.Close()
+
+	// the server's url without its port, which prefixes the urls requested
+	lookalike := ts.URL[:strings.LastIndex(ts.URL, ":")]
+
+	tDir := t.TempDir()
+	configPath := filepath.Join(tDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`user_agent: config-agent
+headers:
+  X-Global: config
+providers:
+  url:
+    headers:
+      X-All-URLs: config
+urls:
+  - url: `+ts.URL+`/a
+    headers:
+      X-Source: a
+      X-All-URLs: a
+  - url: `+lookalike+`
+    headers:
+      X-Lookalike: host
+`), 0o600))
+
+	app := mainpkg.GetApp()
+	os.Args = []string{
+		"ip-fetcher", "--config", configPath, "--header", "X-Global: flag",
+		"url", "--Path", filepath.Join(tDir, "ips.txt"),
+		"--header", "X-Common: flag",
+		"--url-header", ts.URL + "/b?key=value=X-Source: b",
+		ts.URL + "/a", ts.URL + "/b?key=value", ts.URL + "/ab",
+	}
+	require.NoError(t, app.Run(os.Args))
+
+	// entries match whole path segments of the same scheme and host
+	require.Empty(t, received["/ab"].Get("X-Source"))
+
+	for _, h := range received {
+		require.Empty(t, h.Get("X-Lookalike"))
+	}
+
+	a, b := received["/a"], received["/b"]
+	require.NotNil(t, a)
+	require.NotNil(t, b)
+
+	require.Equal(t, "config-agent", a.Get("User-Agent"))
+	require.Equal(t, "flag", a.Get("X-Global"))
+	require.Equal(t, "flag", a.Get("X-Common"))
+	require.Equal(t, "flag", b.Get("X-Common"))
+	require.Equal(t, "a", a.Get("X-Source"))
+	require.Equal(t, "b", b.Get("X-Source"))
+	require.Equal(t, "a", a.Get("X-All-URLs"))
+	require.Equal(t, "config", b.Get("X-All-URLs"))
+
+	// the user agent flag overrides the config file
+	os.Args = []string{
+		"ip-fetcher", "--config", configPath, "--user-agent", "flag-agent",
+		"url", "--Path", filepath.Join(tDir, "ips.txt"), ts.URL + "/c",
+	}
+	require.NoError(t, app.Run(os.Args))
+	require.Equal(t, "flag-agent", received["/c"].Get("User-Agent"))
+}
+
+func TestInvalidHeader(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	app := mainpkg.GetApp()
+	os.Args = []string{"ip-fetcher", "--header", "missing-separator", "aws", "--stdout"}
+	require.ErrorContains(t, app.Run(os.Args), "invalid header")
+}
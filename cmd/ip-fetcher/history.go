@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/jonhadfield/ip-fetcher/publisher"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	historyCmdName = "history"
+	flagDate       = "date"
+	dateLayout     = "2006-01-02"
+)
+
+func historyCmd() *cli.Command {
+	return &cli.Command{
+		Name:      historyCmdName,
+		HelpName:  "- fetch a provider's data as published on a date",
+		Usage:     "fetch a snapshot archived by publish --history",
+		UsageText: "ip-fetcher history PROVIDER [--date YYYY-MM-DD] [--destination DESTINATION] {--stdout | --Path FILE}",
+		OnUsageError: func(cCtx *cli.Context, err error, isSubcommand bool) error {
+			_ = cli.ShowSubcommandHelp(cCtx)
+
+			return err
+		},
+		Flags: append(destinationFlags(),
+			&cli.StringFlag{
+				Name:  flagDate,
+				Usage: "day, as YYYY-MM-DD in UTC, returning the latest snapshot on or before it (default: today)",
+			},
+			&cli.StringFlag{
+				Name:    flagBranch,
+				Usage:   "branch of a git destination published to, in place of its default branch",
+				EnvVars: []string{envBranch},
+			},
+			&cli.StringFlag{
+				Name:  flagPath,
+				Usage: usageWhereToSaveFile, Aliases: []string{"p"},
+			},
+			&cli.BoolFlag{
+				Name:  flagStdout,
+				Usage: usageWriteToStdout, Aliases: []string{"s"},
+			},
+		),
+		Action: func(c *cli.Context) error {
+			provider := strings.ToLower(strings.TrimSpace(c.Args().First()))
+			if provider == "" {
+				_ = cli.ShowSubcommandHelp(c)
+
+				return cli.Exit("error: a provider is required", 1)
+			}
+
+			day := time.Now().UTC()
+
+			if d := c.String(flagDate); d != "" {
+				var err error
+				if day, err = time.Parse(dateLayout, d); err != nil {
+					return fmt.Errorf("invalid date %q: expected YYYY-MM-DD", d)
+				}
+			}
+
+			out, stdout, err := resolveOutputTargets(c)
+			if err != nil {
+				return err
+			}
+
+			dest, err := historyDestination(c)
+			if err != nil {
+				return err
+			}
+
+			if err = dest.Open(); err != nil {
+				return err
+			}
+
+			snapshot, data, err := publisher.ReadHistory(dest, provider, day)
+			if err != nil {
+				return err
+			}
+
+			if err = dest.Close(); err != nil {
+				return err
+			}
+
+			if snapshot.Date != day.Format(dateLayout) {
+				_, _ = fmt.Fprintf(os.Stderr, "no snapshot of %s on %s: using %s\n", provider, day.Format(dateLayout), snapshot.Date)
+			}
+
+			if out != "" {
+				written, err := SaveFile(SaveFileInput{
+					Provider:        provider,
+					Data:            data,
+					Path:            out,
+					DefaultFileName: provider + "-" + snapshot.Date + path.Ext(strings.TrimSuffix(snapshot.Name, ".gz")),
+				})
+				if err != nil {
+					return err
+				}
+
+				_, _ = fmt.Fprintf(os.Stderr, fmtDataWrittenTo, written)
+			}
+
+			if stdout {
+				fmt.Printf("%s\n", data)
+			}
+
+			return nil
+		},
+	}
+}
+
+// historyDestination returns the destination set by flags or the configuration file or, failing
+// those, the repository at GITHUB_PUBLISH_URL, for the branch published to.
+func historyDestination(c *cli.Context) (publisher.Destination, error) {
+	pc := configFromContext(c).Publish
+
+	dest, err := publishDestination(c, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	if dest == nil {
+		repo := firstNonEmpty(pc.RepoURL, os.Getenv("GITHUB_PUBLISH_URL"))
+		if repo == "" {
+			return nil, errors.New("a destination is required: use --" + flagDestination + " or set GITHUB_PUBLISH_URL")
+		}
+
+		var auth transport.AuthMethod
+
+		if auth, err = gitAuth(c, pc, repo); err != nil {
+			return nil, err
+		}
+
+		dest = publisher.NewGitDestination(repo, auth)
+	}
+
+	if g, ok := dest.(*publisher.GitDestination); ok {
+		g.Base = firstNonEmpty(c.String(flagBranch), pc.Branch)
+	}
+
+	return dest, nil
+}
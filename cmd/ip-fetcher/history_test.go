@@ -0,0 +1,55 @@
+package main_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mainpkg "github.com/jonhadfield/ip-fetcher/cmd/ip-fetcher"
+	"github.com/jonhadfield/ip-fetcher/publisher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryCmd(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	published := t.TempDir()
+	tDir := t.TempDir()
+	data := `{"prefixes":["192.0.2.0/24"]}`
+
+	_, err := publisher.Publish(
+		publisher.WithDestination(publisher.NewDirDestination(published)),
+		publisher.WithProviders(publisher.Provider{
+			FetchFunc: func() ([]byte, http.Header, error) { return []byte(data), nil, nil },
+			ShortName: "test",
+			File:      "test.json",
+		}),
+		publisher.WithHistory(0),
+	)
+	require.NoError(t, err)
+
+	today := time.Now().UTC().Format("2006-01-02")
+
+	app := mainpkg.GetApp()
+
+	os.Args = []string{"ip-fetcher", "history", "--destination", published, "--Path", tDir, "test"}
+	require.NoError(t, app.Run(os.Args))
+
+	out, err := os.ReadFile(filepath.Join(tDir, "test-"+today+".json"))
+	require.NoError(t, err)
+	require.Equal(t, data, string(out))
+
+	// later days return the latest snapshot before them
+	os.Args = []string{"ip-fetcher", "history", "--destination", published, "--date", "2999-01-01",
+		"--Path", filepath.Join(tDir, "later.json"), "test"}
+	require.NoError(t, app.Run(os.Args))
+	require.FileExists(t, filepath.Join(tDir, "later.json"))
+
+	os.Args = []string{"ip-fetcher", "history", "--destination", published, "--date", "2000-01-01", "--Path", tDir, "test"}
+	require.ErrorContains(t, app.Run(os.Args), "no snapshot of test on or before 2000-01-01")
+
+	os.Args = []string{"ip-fetcher", "history", "--destination", published, "--date", "01/01/2000", "--Path", tDir, "test"}
+	require.ErrorContains(t, app.Run(os.Args), "invalid date")
+}
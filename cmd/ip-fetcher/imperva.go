@@ -108,16 +108,12 @@ func impervaOutput(doc imperva.Doc, format string, stdout bool, path string) err
 		}
 	}
 
-	if stdout {
-		fmt.Printf("%s\n\n", data)
-	}
-
 	defaultName := fileNameOutputImperva
 	if format == formatLines {
 		defaultName = fileNameLinesImperva
 	}
 
-	return writeOutputs(path, false, SaveFileInput{
+	return writeOutputs(path, stdout, SaveFileInput{
 		Provider:        providerNameImperva,
 		DefaultFileName: defaultName,
 		Data:            data,
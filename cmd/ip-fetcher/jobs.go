@@ -0,0 +1,311 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jonhadfield/ip-fetcher/filter"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	runCmdName    = "run"
+	configCmdName = "config"
+)
+
+// JobConfig is a command run by the run command, such as fetching a provider's prefixes to a file.
+type JobConfig struct {
+	// Name identifies the job, defaulting to the provider.
+	Name string `yaml:"name"`
+	// Provider is the command to run, e.g. aws, url or publish.
+	Provider string `yaml:"provider"`
+	// Sources are the urls, paths or providers read by the url and overlaps commands.
+	Sources []string `yaml:"sources"`
+	// Path and Stdout set where the output is written.
+	Path   string `yaml:"path"`
+	Stdout bool   `yaml:"stdout"`
+	// Format and Lines set the output format, for providers supporting them.
+	Format string `yaml:"format"`
+	Lines  bool   `yaml:"lines"`
+	// Filter selects prefixes by their attributes, e.g. region=eu-west-1.
+	Filter string `yaml:"filter"`
+	// IPv4 and IPv6 select a single address family.
+	IPv4 bool `yaml:"ipv4"`
+	IPv6 bool `yaml:"ipv6"`
+	// ExcludeBogons and ExcludeFiles exclude bogons and listed ranges.
+	ExcludeBogons bool     `yaml:"exclude_bogons"`
+	ExcludeFiles  []string `yaml:"exclude_files"`
+	// StateDir records the number of prefixes written, to refuse output that shrinks too far.
+	StateDir string `yaml:"state_dir"`
+	// Args are further arguments to the command, e.g. ["--max-drop", "1000"].
+	Args []string `yaml:"args"`
+}
+
+// name returns the job's name or, if unnamed, its provider.
+func (j JobConfig) name() string {
+	if j.Name != "" {
+		return j.Name
+	}
+
+	return j.Provider
+}
+
+// flags returns the names of the flags set by the job, excluding its args.
+func (j JobConfig) flags() []string {
+	var names []string
+
+	for name, set := range map[string]bool{
+		flagPath:          j.Path != "",
+		flagStdout:        j.Stdout,
+		flagFormat:        j.Format != "",
+		formatLines:       j.Lines,
+		flagFilter:        j.Filter != "",
+		flagIPv4:          j.IPv4,
+		flagIPv6:          j.IPv6,
+		flagExcludeBogons: j.ExcludeBogons,
+		flagExcludeFile:   len(j.ExcludeFiles) > 0,
+		flagStateDir:      j.StateDir != "",
+	} {
+		if set {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// args returns the command line running the job.
+func (j JobConfig) args() []string {
+	args := []string{j.Provider}
+
+	add := func(name string, values ...string) {
+		args = append(args, "--"+name)
+		args = append(args, values...)
+	}
+
+	if j.Path != "" {
+		add(flagPath, j.Path)
+	}
+
+	if j.Stdout {
+		add(flagStdout)
+	}
+
+	if j.Format != "" {
+		add(flagFormat, j.Format)
+	}
+
+	if j.Lines {
+		add(formatLines)
+	}
+
+	if j.Filter != "" {
+		add(flagFilter, j.Filter)
+	}
+
+	if j.IPv4 {
+		add(flagIPv4)
+	}
+
+	if j.IPv6 {
+		add(flagIPv6)
+	}
+
+	if j.ExcludeBogons {
+		add(flagExcludeBogons)
+	}
+
+	for _, f := range j.ExcludeFiles {
+		add(flagExcludeFile, f)
+	}
+
+	if j.StateDir != "" {
+		add(flagStateDir, j.StateDir)
+	}
+
+	args = append(args, j.Args...)
+
+	return append(args, j.Sources...)
+}
+
+// validateJobs checks each job runs a command, with flags, that cmds defines.
+func validateJobs(jobs []JobConfig, cmds []*cli.Command) error {
+	var errs []error
+
+	names := make(map[string]bool)
+
+	for i, j := range jobs {
+		label := fmt.Sprintf("job %d", i+1)
+		if j.name() != "" {
+			label += " (" + j.name() + ")"
+		}
+
+		if err := validateJob(j, cmds); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", label, err))
+		}
+
+		if names[j.name()] {
+			errs = append(errs, fmt.Errorf("%s: name is not unique", label))
+		}
+
+		names[j.name()] = true
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateJob(j JobConfig, cmds []*cli.Command) error {
+	if j.Provider == "" {
+		return errors.New("provider is required")
+	}
+
+	idx := slices.IndexFunc(cmds, func(c *cli.Command) bool { return c.HasName(j.Provider) })
+	if idx < 0 || j.Provider == runCmdName || j.Provider == configCmdName {
+		return fmt.Errorf("unknown provider: %s", j.Provider)
+	}
+
+	cmd := cmds[idx]
+
+	for _, name := range j.flags() {
+		if !slices.ContainsFunc(cmd.Flags, func(f cli.Flag) bool { return slices.Contains(f.Names(), name) }) {
+			return fmt.Errorf("%s does not support %s", j.Provider, name)
+		}
+	}
+
+	// commands writing output must be told where
+	if slices.ContainsFunc(cmd.Flags, func(f cli.Flag) bool { return slices.Contains(f.Names(), flagStdout) }) &&
+		j.Path == "" && !j.Stdout && !slices.Contains(j.Args, "--"+flagStdout) {
+		return errors.New("path or stdout is required")
+	}
+
+	if j.Filter != "" {
+		if _, err := filter.ParseExpression(j.Filter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func runCmd() *cli.Command {
+	return &cli.Command{
+		Name:      runCmdName,
+		Usage:     "run the jobs in the configuration file",
+		HelpName:  "- run configured jobs",
+		UsageText: "ip-fetcher [--config FILE] run [JOB...]",
+		OnUsageError: func(cCtx *cli.Context, err error, isSubcommand bool) error {
+			_ = cli.ShowSubcommandHelp(cCtx)
+
+			return err
+		},
+		Action: func(c *cli.Context) error {
+			cfg, path, err := jobsConfig(c)
+			if err != nil {
+				return err
+			}
+
+			jobs := cfg.Jobs
+
+			if c.Args().Present() {
+				jobs = slices.DeleteFunc(slices.Clone(jobs), func(j JobConfig) bool {
+					return !slices.Contains(c.Args().Slice(), j.name())
+				})
+
+				if len(jobs) != c.Args().Len() {
+					return fmt.Errorf("unknown job in: %s", strings.Join(c.Args().Slice(), ", "))
+				}
+			}
+
+			// global flags apply to every job
+			base := []string{c.App.Name, "--" + flagConfig, path}
+			if ua := c.String(flagUserAgent); ua != "" {
+				base = append(base, "--"+flagUserAgent, ua)
+			}
+
+			for _, h := range c.StringSlice(flagHeader) {
+				base = append(base, "--"+flagHeader, h)
+			}
+
+			var failed []string
+
+			for _, j := range jobs {
+				_, _ = fmt.Fprintf(os.Stderr, "running job %s\n", j.name())
+
+				// each job runs with a new app, as commands keep per-run state
+				if err = GetApp().Run(append(slices.Clone(base), j.args()...)); err != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "job %s failed: %v\n", j.name(), err)
+
+					failed = append(failed, j.name())
+				}
+			}
+
+			if len(failed) > 0 {
+				return fmt.Errorf("%d of %d jobs failed: %s", len(failed), len(jobs), strings.Join(failed, ", "))
+			}
+
+			return nil
+		},
+	}
+}
+
+func configCmd() *cli.Command {
+	return &cli.Command{
+		Name:      configCmdName,
+		Usage:     "check the configuration file",
+		HelpName:  "- manage the configuration file",
+		UsageText: "ip-fetcher [--config FILE] config validate",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "validate",
+				Usage:     "check the configuration file can be read and its jobs run",
+				UsageText: "ip-fetcher [--config FILE] config validate",
+				Action: func(c *cli.Context) error {
+					cfg, path, err := jobsConfig(c)
+					if err != nil {
+						return err
+					}
+
+					if cfg.Publish.Token != "" {
+						if _, err = readSecret(cfg.Publish.Token); err != nil {
+							return fmt.Errorf("publish token: %w", err)
+						}
+					}
+
+					if _, err = publishFormats(cfg.Publish.Formats); err != nil {
+						return err
+					}
+
+					if _, err = publishProviders(cfg.Publish.Providers, cfg.Publish.ExcludeProviders, cfg.Publish.Files); err != nil {
+						return err
+					}
+
+					_, _ = fmt.Fprintf(os.Stderr, "%s is valid with %d jobs\n", path, len(cfg.Jobs))
+
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// jobsConfig returns the loaded configuration, and its path, once its jobs are validated.
+func jobsConfig(c *cli.Context) (Config, string, error) {
+	path, _ := c.App.Metadata[configPathKey].(string)
+	if path == "" {
+		return Config{}, "", fmt.Errorf("no configuration file found: use --%s, %s or create %s",
+			flagConfig, envConfig, defaultConfigPath)
+	}
+
+	cfg := configFromContext(c)
+
+	if err := validateJobs(cfg.Jobs, c.App.Commands); err != nil {
+		return Config{}, "", fmt.Errorf("invalid configuration %s:\n%w", path, err)
+	}
+
+	return cfg, path, nil
+}
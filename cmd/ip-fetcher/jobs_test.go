@@ -0,0 +1,132 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	mainpkg "github.com/jonhadfield/ip-fetcher/cmd/ip-fetcher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCmd(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	t.Setenv("IP_FETCHER_MOCK_AWS", "true")
+	t.Setenv("IP_FETCHER_MOCK_GCP", "true")
+
+	tDir := t.TempDir()
+	configPath := filepath.Join(tDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`jobs:
+  - name: aws-api
+    provider: aws
+    lines: true
+    filter: region=us-east-2,service=API_GATEWAY
+    path: `+filepath.Join(tDir, "aws-api.txt")+`
+  - provider: gcp
+    format: csv
+    ipv4: true
+    path: `+filepath.Join(tDir, "gcp.csv")+`
+`), 0o600))
+
+	app := mainpkg.GetApp()
+
+	os.Args = []string{"ip-fetcher", "--config", configPath, "run", "aws-api"}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err := os.ReadFile(filepath.Join(tDir, "aws-api.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "3.145.220.0/22\n3.145.230.0/24\n", string(data))
+	require.NoFileExists(t, filepath.Join(tDir, "gcp.csv"))
+
+	os.Args = []string{"ip-fetcher", "--config", configPath, "run"}
+	require.NoError(t, app.Run(os.Args))
+	require.FileExists(t, filepath.Join(tDir, "gcp.csv"))
+
+	os.Args = []string{"ip-fetcher", "--config", configPath, "run", "missing"}
+	require.ErrorContains(t, app.Run(os.Args), "unknown job in: missing")
+}
+
+func TestRunCmdReportsFailedJobs(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	t.Setenv("IP_FETCHER_MOCK_AWS", "true")
+
+	tDir := t.TempDir()
+	configPath := filepath.Join(tDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`jobs:
+  - name: empty
+    provider: aws
+    lines: true
+    filter: region=nowhere
+    path: `+filepath.Join(tDir, "empty.txt")+`
+  - provider: aws
+    path: `+tDir+`
+`), 0o600))
+
+	app := mainpkg.GetApp()
+
+	os.Args = []string{"ip-fetcher", "--config", configPath, "run"}
+	require.ErrorContains(t, app.Run(os.Args), "1 of 2 jobs failed: empty")
+	require.FileExists(t, filepath.Join(tDir, "ip-ranges.json"))
+}
+
+func TestConfigValidateCmd(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	tDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tDir)
+
+	app := mainpkg.GetApp()
+
+	os.Args = []string{"ip-fetcher", "config", "validate"}
+	require.ErrorContains(t, app.Run(os.Args), "no configuration file found")
+
+	// the configuration file is found in the user configuration directory
+	require.NoError(t, os.MkdirAll(filepath.Join(tDir, "ip-fetcher"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(tDir, "ip-fetcher", "config.yaml"), []byte(`jobs:
+  - provider: oci
+    stdout: true
+    filter: tag=OSN
+publish:
+  repo_url: https://github.com/example/ranges.git
+  token: env:TEST_PUBLISH_TOKEN
+`), 0o600))
+
+	t.Setenv("TEST_PUBLISH_TOKEN", "secret")
+	require.NoError(t, app.Run(os.Args))
+
+	t.Setenv("TEST_PUBLISH_TOKEN", "")
+	require.ErrorContains(t, app.Run(os.Args), "publish token: environment variable TEST_PUBLISH_TOKEN is not set")
+
+	invalid := filepath.Join(tDir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte(`jobs:
+  - provider: nope
+    stdout: true
+  - provider: oci
+    lines: true
+    stdout: true
+  - provider: aws
+  - provider: gcp
+    stdout: true
+    filter: scope
+`), 0o600))
+
+	os.Args = []string{"ip-fetcher", "--config", invalid, "config", "validate"}
+	err := app.Run(os.Args)
+	require.ErrorContains(t, err, "job 1 (nope): unknown provider: nope")
+	require.ErrorContains(t, err, "job 2 (oci): oci does not support lines")
+	require.ErrorContains(t, err, "job 3 (aws): path or stdout is required")
+	require.ErrorContains(t, err, "job 4 (gcp): invalid filter")
+
+	// unknown settings are rejected
+	require.NoError(t, os.WriteFile(invalid, []byte("jobs:\n  - provider: aws\n    output: x\n"), 0o600))
+	require.ErrorContains(t, app.Run(os.Args), "field output not found")
+
+	// publish settings are checked
+	require.NoError(t, os.WriteFile(invalid, []byte("publish:\n  exclude_providers: [nope]\n"), 0o600))
+	require.ErrorContains(t, app.Run(os.Args), `unknown provider "nope"`)
+
+	require.NoError(t, os.WriteFile(invalid, []byte("publish:\n  files:\n    aws: ../aws.json\n"), 0o600))
+	require.ErrorContains(t, app.Run(os.Args), `invalid file name "../aws.json" for aws`)
+}
@@ -41,6 +41,9 @@ func GetApp() *cli.App {
 		},
 	}
 	app.Usage = "Download and display ips for various cloud providers and services"
+	app.Metadata = map[string]any{}
+	app.Flags = globalFlags()
+	app.Before = configureRequests
 	app.Commands = []*cli.Command{
 		abuseipdbCmd(),
 		akamaiCmd(),
@@ -52,6 +55,7 @@ func GetApp() *cli.App {
 		bunnyCmd(),
 		cdn77Cmd(),
 		cloudflareCmd(),
+		configCmd(),
 		contaboCmd(),
 		datadogCmd(),
 		digitaloceanCmd(),
@@ -65,6 +69,7 @@ func GetApp() *cli.App {
 		googlescCmd(),
 		googleutfCmd(),
 		hetznerCmd(),
+		historyCmd(),
 		iCloudPRCmd(),
 		ibmcloudCmd(),
 		impervaCmd(),
@@ -72,9 +77,11 @@ func GetApp() *cli.App {
 		linodeCmd(),
 		m247Cmd(),
 		ociCmd(),
+		overlapsCmd(),
 		ovhCmd(),
 		publishCmd(),
 		renderCmd(),
+		runCmd(),
 		scalewayCmd(),
 		stripeCmd(),
 		tencentCmd(),
@@ -83,5 +90,28 @@ func GetApp() *cli.App {
 		zscalerCmd(),
 	}
 
+	for _, cmd := range app.Commands {
+		// run and config run or check other commands, and history reads what was published,
+		// rather than fetching
+		if cmd.Name == runCmdName || cmd.Name == configCmdName || cmd.Name == historyCmdName {
+			continue
+		}
+
+		// publish and overlaps fetch from several providers and url sets headers per request
+		if cmd.Name != "publish" && cmd.Name != overlapsCmdName && cmd.Name != "url" {
+			withProviderHeaders(cmd)
+		}
+
+		// publish commits the providers' documents as published and geoip writes databases
+		if cmd.Name != "publish" && cmd.Name != "geoip" {
+			withExclusions(cmd)
+		}
+
+		// overlaps writes a report rather than a list
+		if cmd.Name != "publish" && cmd.Name != "geoip" && cmd.Name != overlapsCmdName {
+			withShrinkGuard(cmd)
+		}
+	}
+
 	return app
 }
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/url"
+	"slices"
 
+	"github.com/jonhadfield/ip-fetcher/filter"
 	"github.com/jonhadfield/ip-fetcher/providers/oci"
 	"github.com/urfave/cli/v2"
 	"gopkg.in/h2non/gock.v1"
@@ -11,6 +14,8 @@ import (
 
 const sOCI = "oci"
 
+var ociFilterKeys = []string{"region", "tag"}
+
 func ociCmd() *cli.Command {
 	const (
 		providerName = sOCI
@@ -21,7 +26,7 @@ func ociCmd() *cli.Command {
 		Name:      providerName,
 		Usage:     "Oracle Cloud Infrastructure",
 		HelpName:  "- fetch OCI (Oracle Cloud Infrastructure) prefixes",
-		UsageText: "ip-fetcher oci {--stdout | --Path FILE}",
+		UsageText: "ip-fetcher oci {--stdout | --Path FILE} [--filter EXPRESSION]",
 		OnUsageError: func(cCtx *cli.Context, err error, isSubcommand bool) error {
 			_ = cli.ShowSubcommandHelp(cCtx)
 
@@ -36,6 +41,7 @@ func ociCmd() *cli.Command {
 				Name:  flagStdout,
 				Usage: usageWriteToStdout, Aliases: []string{"s"},
 			},
+			attributeFilterFlag(ociFilterKeys...),
 		},
 		Action: func(c *cli.Context) error {
 			path, stdout, err := resolveOutputTargets(c)
@@ -43,6 +49,11 @@ func ociCmd() *cli.Command {
 				return err
 			}
 
+			sel, err := selectorFromContext(c, ociFilterKeys...)
+			if err != nil {
+				return err
+			}
+
 			a := oci.New()
 
 			if isEnvEnabled("IP_FETCHER_MOCK_OCI") {
@@ -61,6 +72,10 @@ func ociCmd() *cli.Command {
 				return err
 			}
 
+			if data, err = ociFilter(data, sel); err != nil {
+				return err
+			}
+
 			return writeOutputs(path, stdout, SaveFileInput{
 				Provider:        providerName,
 				DefaultFileName: fileName,
@@ -69,3 +84,29 @@ func ociCmd() *cli.Command {
 		},
 	}
 }
+
+// ociFilter removes the ranges not matching sel from an OCI document, and the regions left empty.
+func ociFilter(data []byte, sel filter.Selector) ([]byte, error) {
+	if len(sel) == 0 {
+		return data, nil
+	}
+
+	var raw oci.RawDoc
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	for i := range raw.RawRegions {
+		r := &raw.RawRegions[i]
+
+		r.CIDRS = slices.DeleteFunc(r.CIDRS, func(cidr oci.RawCIDR) bool {
+			return !sel.Match(filter.Attributes{"region": {r.Region}, "tag": cidr.Tags})
+		})
+	}
+
+	raw.RawRegions = slices.DeleteFunc(raw.RawRegions, func(r oci.RawRegion) bool {
+		return len(r.CIDRS) == 0
+	})
+
+	return json.MarshalIndent(raw, "", "  ")
+}
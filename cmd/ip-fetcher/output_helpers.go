@@ -23,6 +23,13 @@ func resolveOutputTargets(c *cli.Context) (string, bool, error) {
 }
 
 func writeOutputs(path string, stdout bool, input SaveFileInput) error {
+	data, err := prepareOutput(input.Provider, input.Data)
+	if err != nil {
+		return err
+	}
+
+	input.Data = data
+
 	if path != "" {
 		input.Path = path
 		out, err := SaveFile(input)
@@ -37,5 +44,5 @@ func writeOutputs(path string, stdout bool, input SaveFileInput) error {
 		fmt.Printf("%s\n", input.Data)
 	}
 
-	return nil
+	return safety.record(input.Provider, input.Data)
 }
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jonhadfield/ip-fetcher/overlap"
+	"github.com/jonhadfield/ip-fetcher/providers/abuseipdb"
+	_url "github.com/jonhadfield/ip-fetcher/providers/url"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	overlapsCmdName = "overlaps"
+
+	formatText = "text"
+
+	flagAbuseIPDBKey = "abuseipdb-key"
+
+	overlapsFileNameText = "overlaps.txt"
+	overlapsFileNameJSON = "overlaps.json"
+)
+
+var overlapsFormats = []string{formatText, formatJSON} //nolint:gochecknoglobals
+
+func overlapsCmd() *cli.Command {
+	return &cli.Command{
+		Name:     overlapsCmdName,
+		HelpName: "- report overlapping prefixes",
+		Usage:    "Report prefixes that overlap between providers and lists",
+		UsageText: "ip-fetcher overlaps {--stdout | --Path FILE} [--format FORMAT] " +
+			"{PROVIDER | URL | PATH | -} {PROVIDER | URL | PATH | -} [...]",
+		Description: "Sources are provider names, such as aws or cloudflare, or urls, paths or - for stdin, read as " +
+			"by the url command.\n\nProviders: " + strings.Join(append(providerNames(), abuseipdb.ShortName), ", "),
+		OnUsageError: func(cCtx *cli.Context, err error, isSubcommand bool) error {
+			_ = cli.ShowSubcommandHelp(cCtx)
+
+			return err
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  flagPath,
+				Usage: usageWhereToSaveFile, Aliases: []string{"p"}, TakesFile: true,
+			},
+			&cli.BoolFlag{
+				Name:  flagStdout,
+				Usage: usageWriteToStdout, Aliases: []string{"s"},
+			},
+			&cli.StringFlag{
+				Name:  flagFormat,
+				Usage: strings.Join(overlapsFormats, ", "), Value: formatText, Aliases: []string{"f"},
+			},
+			&cli.StringFlag{
+				Name:    flagAbuseIPDBKey,
+				Usage:   "api key used to fetch the abuseipdb source",
+				EnvVars: []string{"IP_FETCHER_ABUSEIPDB_KEY"},
+			},
+		},
+		Action: func(c *cli.Context) error {
+			path, stdout, err := resolveOutputTargets(c)
+			if err != nil {
+				return err
+			}
+
+			format := c.String(flagFormat)
+			if !slices.Contains(overlapsFormats, format) {
+				return fmt.Errorf("invalid format: %s\n       choose from: %s",
+					format, strings.Join(overlapsFormats, ", "))
+			}
+
+			sources := c.Args().Slice()
+			if len(slices.Compact(slices.Sorted(slices.Values(sources)))) < 2 {
+				return errors.New("at least two different sources are required")
+			}
+
+			if err := checkStdinOnce(sources); err != nil {
+				return err
+			}
+
+			sets, err := readOverlapSources(c, sources)
+			if err != nil {
+				return err
+			}
+
+			data, fileName, err := overlapsOutput(sources, sets, overlap.Find(sets...), format)
+			if err != nil {
+				return err
+			}
+
+			return writeOutputs(path, stdout, SaveFileInput{
+				Provider:        overlapsCmdName,
+				DefaultFileName: fileName,
+				Data:            data,
+			})
+		},
+	}
+}
+
+// readOverlapSources fetches and reads each source, without the excluded prefixes.
+func readOverlapSources(c *cli.Context, sources []string) ([][]overlap.Entry, error) {
+	sets := make([][]overlap.Entry, len(sources))
+
+	var g errgroup.Group
+
+	for i, source := range sources {
+		fetch, err := overlapSource(c, source)
+		if err != nil {
+			return nil, err
+		}
+
+		g.Go(func() error {
+			data, fetchErr := fetch()
+			if fetchErr != nil {
+				return fmt.Errorf("failed to fetch %s: %w", source, fetchErr)
+			}
+
+			entries, readErr := overlap.ReadEntries(source, data)
+			if readErr != nil {
+				return fmt.Errorf("failed to read %s: %w", source, readErr)
+			}
+
+			sets[i] = slices.DeleteFunc(entries, func(e overlap.Entry) bool {
+				return families.Excluded(e.Prefix) || exclusions.Excluded(e.Prefix)
+			})
+
+			return nil
+		})
+	}
+
+	return sets, g.Wait()
+}
+
+// overlapSource returns the function fetching a source: a provider or a url, path or stdin.
+func overlapSource(c *cli.Context, source string) (func() ([]byte, error), error) {
+	if fetch, ok := providerFetchers()[source]; ok {
+		return fetch, nil
+	}
+
+	if source == abuseipdb.ShortName {
+		key := c.String(flagAbuseIPDBKey)
+		if key == "" {
+			return nil, fmt.Errorf("--%s is required to fetch %s", flagAbuseIPDBKey, source)
+		}
+
+		return func() ([]byte, error) {
+			a := abuseipdb.New()
+			a.APIKey = key
+
+			return fetchData(&a)
+		}, nil
+	}
+
+	u, err := _url.ParseSource(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source %s: %w", source, err)
+	}
+
+	return func() ([]byte, error) {
+		responses, getErr := _url.New().Get([]_url.Request{{URL: u, Method: http.MethodGet}})
+		if getErr != nil {
+			return nil, getErr
+		}
+
+		return (*responses)[0].Data, nil
+	}, nil
+}
+
+type overlapsSource struct {
+	Source   string `json:"source"`
+	Prefixes int    `json:"prefixes"`
+}
+
+type overlapsDoc struct {
+	Sources  []overlapsSource  `json:"sources"`
+	Overlaps []overlap.Overlap `json:"overlaps"`
+}
+
+// overlapsOutput renders the overlaps in the given format, returning the data and default file name.
+func overlapsOutput(sources []string, sets [][]overlap.Entry, overlaps []overlap.Overlap, format string) ([]byte, string, error) {
+	if format == formatJSON {
+		doc := overlapsDoc{Sources: make([]overlapsSource, len(sources)), Overlaps: overlaps}
+		if doc.Overlaps == nil {
+			doc.Overlaps = []overlap.Overlap{}
+		}
+
+		for i, s := range sources {
+			doc.Sources[i] = overlapsSource{Source: s, Prefixes: len(sets[i])}
+		}
+
+		data, err := json.MarshalIndent(doc, "", " ")
+
+		return data, overlapsFileNameJSON, err
+	}
+
+	if len(overlaps) == 0 {
+		return []byte("no overlapping prefixes found"), overlapsFileNameText, nil
+	}
+
+	var buf bytes.Buffer
+
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "SOURCE\tPREFIX\tRELATION\tSOURCE\tPREFIX")
+
+	for _, o := range overlaps {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			o.A.Source, describeEntry(o.A), o.Relation, o.B.Source, describeEntry(o.B))
+	}
+
+	_ = tw.Flush()
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), overlapsFileNameText, nil
+}
+
+// describeEntry renders a prefix followed by its non-empty attributes.
+func describeEntry(e overlap.Entry) string {
+	var attrs []string
+
+	for _, k := range slices.Sorted(maps.Keys(e.Attributes)) {
+		if v := e.Attributes[k]; v != "" {
+			attrs = append(attrs, k+"="+v)
+		}
+	}
+
+	if len(attrs) == 0 {
+		return e.Prefix.String()
+	}
+
+	return e.Prefix.String() + " [" + strings.Join(attrs, " ") + "]"
+}
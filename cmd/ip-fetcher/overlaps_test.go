@@ -0,0 +1,85 @@
+package main_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mainpkg "github.com/jonhadfield/ip-fetcher/cmd/ip-fetcher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverlapsCmd(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"prefixes":[{"ip_prefix":"104.16.0.0/13","region":"eu"},{"ip_prefix":"10.0.0.0/8","region":"x"}]}`))
+	}))
+	defer ts.Close()
+
+	tDir := t.TempDir()
+	deny := filepath.Join(tDir, "deny.txt")
+	require.NoError(t, os.WriteFile(deny, []byte("# deny\n104.16.1.1\n8.8.8.8\n10.1.1.1\n"), 0o600))
+
+	internal := filepath.Join(tDir, "internal.txt")
+	require.NoError(t, os.WriteFile(internal, []byte("8.8.8.0/24\n"), 0o600))
+
+	app := mainpkg.GetApp()
+
+	os.Args = []string{"ip-fetcher", "overlaps", "--Path", tDir, ts.URL, deny, internal}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err := os.ReadFile(filepath.Join(tDir, "overlaps.txt"))
+	require.NoError(t, err)
+	require.Regexp(t, `(?m)^SOURCE\s+PREFIX\s+RELATION\s+SOURCE\s+PREFIX$`, string(data))
+	require.Regexp(t, `(?m)^`+ts.URL+`\s+10\.0\.0\.0/8 \[region=x\]\s+contains\s+`+deny+`\s+10\.1\.1\.1/32$`, string(data))
+	require.Regexp(t, `(?m)^`+internal+`\s+8\.8\.8\.0/24\s+contains\s+`+deny+`\s+8\.8\.8\.8/32$`, string(data))
+
+	// excluded prefixes are not compared
+	os.Args = []string{"ip-fetcher", "overlaps", "--Path", tDir, "--format", "json", "--exclude-bogons", ts.URL, deny, internal}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err = os.ReadFile(filepath.Join(tDir, "overlaps.json"))
+	require.NoError(t, err)
+
+	var doc struct {
+		Sources []struct {
+			Source   string `json:"source"`
+			Prefixes int    `json:"prefixes"`
+		} `json:"sources"`
+		Overlaps []struct {
+			A struct {
+				Prefix     string            `json:"prefix"`
+				Source     string            `json:"source"`
+				Attributes map[string]string `json:"attributes"`
+			} `json:"a"`
+			B struct {
+				Prefix string `json:"prefix"`
+			} `json:"b"`
+			Relation string `json:"relation"`
+		} `json:"overlaps"`
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	require.Len(t, doc.Sources, 3)
+	require.Equal(t, 1, doc.Sources[0].Prefixes)
+	require.Len(t, doc.Overlaps, 2)
+	require.Equal(t, "104.16.0.0/13", doc.Overlaps[1].A.Prefix)
+	require.Equal(t, map[string]string{"region": "eu"}, doc.Overlaps[1].A.Attributes)
+	require.Equal(t, "104.16.1.1/32", doc.Overlaps[1].B.Prefix)
+	require.Equal(t, "contains", doc.Overlaps[1].Relation)
+
+	os.Args = []string{"ip-fetcher", "overlaps", "--stdout", deny}
+	require.ErrorContains(t, app.Run(os.Args), "at least two different sources")
+
+	os.Args = []string{"ip-fetcher", "overlaps", "--stdout", "-", deny, "-"}
+	require.ErrorContains(t, app.Run(os.Args), "standard input (-) can only be given once")
+
+	os.Args = []string{"ip-fetcher", "overlaps", "--stdout", "abuseipdb", deny}
+	require.ErrorContains(t, app.Run(os.Args), "--abuseipdb-key is required")
+
+	os.Args = []string{"ip-fetcher", "overlaps", "--stdout", deny, filepath.Join(tDir, "missing.txt")}
+	require.ErrorContains(t, app.Run(os.Args), "failed to fetch")
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/jonhadfield/ip-fetcher/fetchers"
+	"github.com/jonhadfield/ip-fetcher/providers/akamai"
+	"github.com/jonhadfield/ip-fetcher/providers/alibaba"
+	"github.com/jonhadfield/ip-fetcher/providers/atlassian"
+	"github.com/jonhadfield/ip-fetcher/providers/aws"
+	"github.com/jonhadfield/ip-fetcher/providers/azure"
+	"github.com/jonhadfield/ip-fetcher/providers/bingbot"
+	"github.com/jonhadfield/ip-fetcher/providers/bunny"
+	"github.com/jonhadfield/ip-fetcher/providers/cdn77"
+	"github.com/jonhadfield/ip-fetcher/providers/cloudflare"
+	"github.com/jonhadfield/ip-fetcher/providers/contabo"
+	"github.com/jonhadfield/ip-fetcher/providers/datadog"
+	"github.com/jonhadfield/ip-fetcher/providers/digitalocean"
+	"github.com/jonhadfield/ip-fetcher/providers/fastly"
+	"github.com/jonhadfield/ip-fetcher/providers/flyio"
+	"github.com/jonhadfield/ip-fetcher/providers/gcp"
+	"github.com/jonhadfield/ip-fetcher/providers/github"
+	"github.com/jonhadfield/ip-fetcher/providers/google"
+	"github.com/jonhadfield/ip-fetcher/providers/googlebot"
+	"github.com/jonhadfield/ip-fetcher/providers/googlesc"
+	"github.com/jonhadfield/ip-fetcher/providers/googleutf"
+	"github.com/jonhadfield/ip-fetcher/providers/hetzner"
+	"github.com/jonhadfield/ip-fetcher/providers/ibmcloud"
+	"github.com/jonhadfield/ip-fetcher/providers/icloudpr"
+	"github.com/jonhadfield/ip-fetcher/providers/imperva"
+	"github.com/jonhadfield/ip-fetcher/providers/leaseweb"
+	"github.com/jonhadfield/ip-fetcher/providers/linode"
+	"github.com/jonhadfield/ip-fetcher/providers/m247"
+	"github.com/jonhadfield/ip-fetcher/providers/oci"
+	"github.com/jonhadfield/ip-fetcher/providers/ovh"
+	"github.com/jonhadfield/ip-fetcher/providers/render"
+	"github.com/jonhadfield/ip-fetcher/providers/scaleway"
+	"github.com/jonhadfield/ip-fetcher/providers/stripe"
+	"github.com/jonhadfield/ip-fetcher/providers/tencent"
+	"github.com/jonhadfield/ip-fetcher/providers/vultr"
+	"github.com/jonhadfield/ip-fetcher/providers/zscaler"
+)
+
+// providerFetchers returns the functions fetching the document of each provider that needs no
+// credentials, by short name.
+func providerFetchers() map[string]func() ([]byte, error) {
+	return map[string]func() ([]byte, error){
+		akamai.ShortName: func() ([]byte, error) {
+			p := akamai.New()
+
+			return fetchData(&p)
+		},
+		alibaba.ShortName: func() ([]byte, error) {
+			p := alibaba.New()
+
+			return fetchData(&p)
+		},
+		atlassian.ShortName: func() ([]byte, error) {
+			p := atlassian.New()
+
+			return fetchData(&p)
+		},
+		aws.ShortName: func() ([]byte, error) {
+			p := aws.New()
+
+			return fetchData(&p)
+		},
+		azure.ShortName: func() ([]byte, error) {
+			p := azure.New()
+
+			return fetchData(&p)
+		},
+		bingbot.ShortName: func() ([]byte, error) {
+			p := bingbot.New()
+
+			return fetchData(&p)
+		},
+		bunny.ShortName: func() ([]byte, error) {
+			p := bunny.New()
+
+			return fetchData(&p)
+		},
+		cdn77.ShortName: func() ([]byte, error) {
+			p := cdn77.New()
+
+			return fetchData(&p)
+		},
+		contabo.ShortName: func() ([]byte, error) {
+			p := contabo.New()
+
+			return fetchData(&p)
+		},
+		datadog.ShortName: func() ([]byte, error) {
+			p := datadog.New()
+
+			return fetchData(&p)
+		},
+		digitalocean.ShortName: func() ([]byte, error) {
+			p := digitalocean.New()
+
+			return fetchData(&p)
+		},
+		fastly.ShortName: func() ([]byte, error) {
+			p := fastly.New()
+
+			return fetchData(&p)
+		},
+		flyio.ShortName: func() ([]byte, error) {
+			p := flyio.New()
+
+			return fetchData(&p)
+		},
+		gcp.ShortName: func() ([]byte, error) {
+			p := gcp.New()
+
+			return fetchData(&p)
+		},
+		github.ShortName: func() ([]byte, error) {
+			p := github.New()
+
+			return fetchData(&p)
+		},
+		google.ShortName: func() ([]byte, error) {
+			p := google.New()
+
+			return fetchData(&p)
+		},
+		googlebot.ShortName: func() ([]byte, error) {
+			p := googlebot.New()
+
+			return fetchData(&p)
+		},
+		googlesc.ShortName: func() ([]byte, error) {
+			p := googlesc.New()
+
+			return fetchData(&p)
+		},
+		googleutf.ShortName: func() ([]byte, error) {
+			p := googleutf.New()
+
+			return fetchData(&p)
+		},
+		hetzner.ShortName: func() ([]byte, error) {
+			p := hetzner.New()
+
+			return fetchData(&p)
+		},
+		ibmcloud.ShortName: func() ([]byte, error) {
+			p := ibmcloud.New()
+
+			return fetchData(&p)
+		},
+		icloudpr.ShortName: func() ([]byte, error) {
+			p := icloudpr.New()
+
+			return fetchData(&p)
+		},
+		imperva.ShortName: func() ([]byte, error) {
+			p := imperva.New()
+
+			return fetchData(&p)
+		},
+		leaseweb.ShortName: func() ([]byte, error) {
+			p := leaseweb.New()
+
+			return fetchData(&p)
+		},
+		linode.ShortName: func() ([]byte, error) {
+			p := linode.New()
+
+			return fetchData(&p)
+		},
+		m247.ShortName: func() ([]byte, error) {
+			p := m247.New()
+
+			return fetchData(&p)
+		},
+		oci.ShortName: func() ([]byte, error) {
+			p := oci.New()
+
+			return fetchData(&p)
+		},
+		ovh.ShortName: func() ([]byte, error) {
+			p := ovh.New()
+
+			return fetchData(&p)
+		},
+		render.ShortName: func() ([]byte, error) {
+			p := render.New()
+
+			return fetchData(&p)
+		},
+		scaleway.ShortName: func() ([]byte, error) {
+			p := scaleway.New()
+
+			return fetchData(&p)
+		},
+		stripe.ShortName: func() ([]byte, error) {
+			p := stripe.New()
+
+			return fetchData(&p)
+		},
+		tencent.ShortName: func() ([]byte, error) {
+			p := tencent.New()
+
+			return fetchData(&p)
+		},
+		vultr.ShortName: func() ([]byte, error) {
+			p := vultr.New()
+
+			return fetchData(&p)
+		},
+		zscaler.ShortName: func() ([]byte, error) {
+			p := zscaler.New()
+
+			return fetchData(&p)
+		},
+		cloudflare.ShortName: fetchCloudflare,
+	}
+}
+
+// providerNames returns the names of the providers that can be fetched without credentials.
+func providerNames() []string {
+	return slices.Sorted(maps.Keys(providerFetchers()))
+}
+
+func fetchData(f fetchers.WebFileFetcher) ([]byte, error) {
+	data, _, _, err := f.FetchData()
+
+	return data, err
+}
+
+// fetchCloudflare fetches Cloudflare's IPv4 and IPv6 lists as a single list.
+func fetchCloudflare() ([]byte, error) {
+	cf := cloudflare.New()
+
+	ipv4, _, _, err := cf.FetchIPv4Data()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cloudflare ipv4 data: %w", err)
+	}
+
+	ipv6, _, _, err := cf.FetchIPv6Data()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cloudflare ipv6 data: %w", err)
+	}
+
+	return bytes.Join([][]byte{bytes.TrimSpace(ipv4), bytes.TrimSpace(ipv6)}, []byte("\n")), nil
+}
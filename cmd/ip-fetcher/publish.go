@@ -1,25 +1,353 @@
 package main
 
 import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+
 	"github.com/jonhadfield/ip-fetcher/publisher"
 	"github.com/urfave/cli/v2"
 )
 
+const (
+	flagSingleCommit         = "single-commit"
+	flagDryRun               = "dry-run"
+	flagOutputDir            = "output-dir"
+	flagFormats              = "formats"
+	flagSigningKey           = "signing-key"
+	flagSigningKeyPassphrase = "signing-key-passphrase"
+	flagPullRequest          = "pull-request"
+	flagGitHubAPIURL         = "github-api-url"
+	flagStaleAfter           = "stale-after"
+	flagProviders            = "providers"
+	flagExcludeProviders     = "exclude-providers"
+	flagBranch               = "branch"
+	flagHistory              = "history"
+	flagHistoryRetention     = "history-retention-days"
+	envSigningKey            = "IP_FETCHER_SIGNING_KEY"
+	envSingleCommit          = "IP_FETCHER_SINGLE_COMMIT"
+	envFormats               = "IP_FETCHER_PUBLISH_FORMATS"
+	envPullRequest           = "IP_FETCHER_PULL_REQUEST"
+	envGitHubAPIURL          = "GITHUB_API_URL"
+	envStaleAfter            = "IP_FETCHER_STALE_AFTER"
+	envProviders             = "IP_FETCHER_PUBLISH_PROVIDERS"
+	envExcludeProviders      = "IP_FETCHER_PUBLISH_EXCLUDE_PROVIDERS"
+	envBranch                = "IP_FETCHER_PUBLISH_BRANCH"
+	envHistory               = "IP_FETCHER_PUBLISH_HISTORY"
+	envHistoryRetention      = "IP_FETCHER_HISTORY_RETENTION_DAYS"
+)
+
 func publishCmd() *cli.Command {
 	return &cli.Command{
 		Name:      "publish",
 		Usage:     "publishes the Data to a remote location",
 		HelpName:  "- fetch and deploy ranges to a git repo",
-		UsageText: "ip-fetcher publish",
+		UsageText: "ip-fetcher publish [--destination DESTINATION] [--dry-run] [--output-dir DIR]",
 		OnUsageError: func(cCtx *cli.Context, err error, isSubcommand bool) error {
 			_ = cli.ShowSubcommandHelp(cCtx)
 
 			return err
 		},
+		Flags: append(append(thresholdFlags(), destinationFlags()...),
+			&cli.BoolFlag{
+				Name:    flagSingleCommit,
+				Usage:   "publish every change in one commit summarizing the prefixes added and removed",
+				EnvVars: []string{envSingleCommit},
+			},
+			&cli.StringSliceFlag{
+				Name:    flagFormats,
+				Usage:   "formats to publish for each provider alongside its data: json, txt or csv",
+				EnvVars: []string{envFormats},
+			},
+			&cli.StringFlag{
+				Name:      flagSigningKey,
+				Usage:     "OpenPGP or SSH private key to sign commits with and write detached signatures of published files",
+				EnvVars:   []string{envSigningKey},
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:  flagSigningKeyPassphrase,
+				Usage: "passphrase of an encrypted signing key, as env:NAME or file:PATH",
+			},
+			&cli.StringSliceFlag{
+				Name:    flagProviders,
+				Usage:   "providers to publish, such as aws,gcp, defaulting to all of them",
+				EnvVars: []string{envProviders},
+			},
+			&cli.StringSliceFlag{
+				Name:    flagExcludeProviders,
+				Usage:   "providers not to publish",
+				EnvVars: []string{envExcludeProviders},
+			},
+			&cli.StringFlag{
+				Name:    flagBranch,
+				Usage:   "branch of a git destination to publish to, created if missing, in place of its default branch",
+				EnvVars: []string{envBranch},
+			},
+			&cli.BoolFlag{
+				Name:    flagHistory,
+				Usage:   "archive a daily snapshot of each provider's data under history/PROVIDER/YYYY/MM/DD",
+				EnvVars: []string{envHistory},
+			},
+			&cli.IntFlag{
+				Name:    flagHistoryRetention,
+				Usage:   "remove archived snapshots older than this many days, or 0 to keep them all",
+				EnvVars: []string{envHistoryRetention},
+			},
+			&cli.DurationFlag{
+				Name:    flagStaleAfter,
+				Usage:   "flag providers not successfully updated within this period as stale, or 0 to never",
+				Value:   publisher.DefaultStaleAfter,
+				EnvVars: []string{envStaleAfter},
+			},
+			&cli.BoolFlag{
+				Name:    flagPullRequest,
+				Usage:   "push changes to a branch and open a pull request, or update the one already open, on GitHub",
+				EnvVars: []string{envPullRequest},
+			},
+			&cli.StringFlag{
+				Name:    flagGitHubAPIURL,
+				Usage:   "url of the GitHub REST API, for GitHub Enterprise Server",
+				Value:   "https://api.github.com",
+				EnvVars: []string{envGitHubAPIURL},
+			},
+			&cli.BoolFlag{
+				Name:  flagDryRun,
+				Usage: "report the changes that would be published, without publishing them",
+			},
+			&cli.StringFlag{
+				Name:      flagOutputDir,
+				Usage:     "write the files that would be published to a directory, without publishing them",
+				TakesFile: true,
+			},
+		),
 		Action: func(c *cli.Context) error {
-			publisher.Publish()
+			opts, err := publishOptions(c)
+			if err != nil {
+				return err
+			}
+
+			summary, err := publisher.Publish(opts...)
+			if err != nil {
+				return err
+			}
+
+			if c.Bool(flagDryRun) || c.String(flagOutputDir) != "" {
+				printSummary(summary)
+			}
 
 			return nil
 		},
 	}
 }
+
+// publishOptions returns the publisher options set by flags or, failing that, the configuration file.
+func publishOptions(c *cli.Context) ([]publisher.Option, error) {
+	pc := configFromContext(c).Publish
+
+	thresholds := thresholdsFromContext(c)
+
+	if pc.MaxDropPercent != nil && !c.IsSet(flagMaxDropPercent) {
+		thresholds.MaxDropPercent = *pc.MaxDropPercent
+	}
+
+	if pc.MaxDrop != nil && !c.IsSet(flagMaxDrop) {
+		thresholds.MaxDrop = *pc.MaxDrop
+	}
+
+	staleAfter := c.Duration(flagStaleAfter)
+	if pc.StaleAfter != nil && !c.IsSet(flagStaleAfter) {
+		staleAfter = *pc.StaleAfter
+	}
+
+	opts := []publisher.Option{
+		publisher.WithThresholds(thresholds),
+		publisher.WithForce(c.Bool(flagForce)),
+		publisher.WithDryRun(c.Bool(flagDryRun)),
+		publisher.WithOutputDir(c.String(flagOutputDir)),
+		publisher.WithStaleAfter(staleAfter),
+		publisher.WithSingleCommit(c.Bool(flagSingleCommit) || (pc.SingleCommit && !c.IsSet(flagSingleCommit))),
+	}
+
+	formats := pc.Formats
+	if c.IsSet(flagFormats) {
+		formats = c.StringSlice(flagFormats)
+	}
+
+	parsed, err := publishFormats(formats)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts, publisher.WithFormats(parsed...))
+
+	include, exclude := pc.Providers, pc.ExcludeProviders
+
+	if c.IsSet(flagProviders) {
+		include = c.StringSlice(flagProviders)
+	}
+
+	if c.IsSet(flagExcludeProviders) {
+		exclude = c.StringSlice(flagExcludeProviders)
+	}
+
+	providers, err := publishProviders(include, exclude, pc.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts,
+		publisher.WithProviders(providers...),
+		publisher.WithBranch(cmp.Or(c.String(flagBranch), pc.Branch)),
+		publisher.WithAuthor(pc.AuthorName, pc.AuthorEmail),
+	)
+
+	if c.Bool(flagHistory) || (pc.History && !c.IsSet(flagHistory)) {
+		retention := pc.HistoryRetentionDays
+		if c.IsSet(flagHistoryRetention) {
+			retention = c.Int(flagHistoryRetention)
+		}
+
+		if retention < 0 {
+			return nil, fmt.Errorf("invalid history retention %d: must not be negative", retention)
+		}
+
+		opts = append(opts, publisher.WithHistory(retention))
+	}
+
+	if pc.ReadMeTemplate != "" {
+		tmpl, err := os.ReadFile(pc.ReadMeTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read README template: %w", err)
+		}
+
+		opts = append(opts, publisher.WithReadMeTemplate(string(tmpl)))
+	}
+
+	signer, err := publishSigner(c, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	if signer != nil {
+		opts = append(opts, publisher.WithSigner(signer))
+	}
+
+	pr, err := pullRequestOption(c, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	if pr != nil {
+		opts = append(opts, pr)
+	}
+
+	dest, err := publishDestination(c, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	if dest != nil {
+		return append(opts, publisher.WithDestination(dest)), nil
+	}
+
+	if pc.RepoURL != "" {
+		opts = append(opts, publisher.WithRepoURL(pc.RepoURL))
+	}
+
+	if pc.Token != "" {
+		token, err := publishToken(pc)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, publisher.WithToken(token))
+	}
+
+	return opts, nil
+}
+
+// pullRequestOption returns the option proposing changes in a pull request, if set by flag or
+// the configuration file.
+func pullRequestOption(c *cli.Context, pc PublishConfig) (publisher.Option, error) {
+	if !c.Bool(flagPullRequest) && (!pc.PullRequest || c.IsSet(flagPullRequest)) {
+		return nil, nil
+	}
+
+	token, err := publishToken(pc)
+	if err != nil {
+		return nil, err
+	}
+
+	return publisher.WithPullRequest(publisher.PullRequestConfig{
+		APIURL:       c.String(flagGitHubAPIURL),
+		Token:        token,
+		BranchPrefix: pc.BranchPrefix,
+	}), nil
+}
+
+// publishProviders returns the providers named by include, or every provider, except those
+// named by exclude, with the names of the files they publish to replaced by those in files.
+func publishProviders(include, exclude []string, files map[string]string) ([]publisher.Provider, error) {
+	providers, err := publisher.SelectProviders(include, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	return publisher.RenameFiles(providers, files)
+}
+
+// publishFormats parses the names of formats to publish.
+func publishFormats(names []string) ([]publisher.Format, error) {
+	formats := make([]publisher.Format, 0, len(names))
+
+	for _, name := range names {
+		f, err := publisher.ParseFormat(name)
+		if err != nil {
+			return nil, fmt.Errorf("publish formats: %w", err)
+		}
+
+		if !slices.Contains(formats, f) {
+			formats = append(formats, f)
+		}
+	}
+
+	return formats, nil
+}
+
+// publishSigner returns the signer of the key set by flag or the configuration file, if any.
+func publishSigner(c *cli.Context, pc PublishConfig) (publisher.Signer, error) {
+	path := firstNonEmpty(c.String(flagSigningKey), pc.SigningKey)
+	if path == "" {
+		return nil, nil
+	}
+
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("signing key: %w", err)
+	}
+
+	var passphrase string
+
+	if ref := firstNonEmpty(c.String(flagSigningKeyPassphrase), pc.SigningKeyPassphrase); ref != "" {
+		if passphrase, err = readSecret(ref); err != nil {
+			return nil, fmt.Errorf("signing key passphrase: %w", err)
+		}
+	}
+
+	return publisher.NewSigner(key, passphrase)
+}
+
+// printSummary writes the prefixes each provider would add and remove.
+func printSummary(summary publisher.Summary) {
+	if len(summary) == 0 {
+		fmt.Println("no changes")
+
+		return
+	}
+
+	for _, c := range summary {
+		fmt.Printf("%s: +%d -%d\n", c.File, c.Added, c.Removed)
+	}
+}
@@ -116,16 +116,12 @@ func stripeOutput(doc stripe.Doc, format string, stdout bool, path string) error
 		}
 	}
 
-	if stdout {
-		fmt.Printf("%s\n\n", data)
-	}
-
 	defaultName := fileNameOutputStripe
 	if format == formatLines {
 		defaultName = fileNameLinesStripe
 	}
 
-	return writeOutputs(path, false, SaveFileInput{
+	return writeOutputs(path, stdout, SaveFileInput{
 		Provider:        providerNameStripe,
 		DefaultFileName: defaultName,
 		Data:            data,
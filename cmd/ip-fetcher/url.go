@@ -2,8 +2,11 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"os"
+	"slices"
 	"strings"
 
 	_url "github.com/jonhadfield/ip-fetcher/providers/url"
@@ -12,22 +15,19 @@ import (
 )
 
 func urlCmd() *cli.Command {
-	const (
-		providerName = "url"
-		fileName     = "ips.txt"
-	)
+	const providerName = "url"
 
 	return &cli.Command{
 		Name:      providerName,
 		HelpName:  "- fetch prefixes from URLs",
-		Usage:     "Read prefixes from a web URL",
-		UsageText: "ip-fetcher url {--stdout | --Path FILE} URL [URL...]",
+		Usage:     "Read prefixes from web URLs, local files or stdin",
+		UsageText: "ip-fetcher url {--stdout | --Path FILE} [--format FORMAT] [--summary] [--strict] {URL | PATH | -} [{URL | PATH | -}...]",
 		OnUsageError: func(cCtx *cli.Context, err error, isSubcommand bool) error {
 			_ = cli.ShowSubcommandHelp(cCtx)
 
 			return err
 		},
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:  flagPath,
 				Usage: usageWhereToSaveFile, Aliases: []string{"p"}, TakesFile: true,
@@ -36,7 +36,38 @@ func urlCmd() *cli.Command {
 				Name:  flagStdout,
 				Usage: usageWriteToStdout, Aliases: []string{"s"},
 			},
-		},
+			&cli.StringFlag{
+				Name:  flagFormat,
+				Usage: strings.Join(urlOutputFormats, ", "), Value: formatLines, Aliases: []string{"f"},
+			},
+			&cli.BoolFlag{
+				Name:  flagSummary,
+				Usage: "write a summary of each source to stderr",
+			},
+			&cli.BoolFlag{
+				Name:  flagStrict,
+				Usage: "fail if any source cannot be fetched or parsed",
+			},
+			&cli.StringSliceFlag{
+				Name:    flagHeader,
+				Usage:   usageHeader + " with every url request",
+				Aliases: []string{"H"},
+				EnvVars: []string{providerHeadersEnv(providerName)},
+			},
+			&cli.StringSliceFlag{
+				Name:  flagURLHeader,
+				Usage: "header to send with a single url, as 'URL=Name: value' (repeatable)",
+			},
+			&cli.StringFlag{
+				Name:  flagSourceFormat,
+				Usage: "format of every url's content: " + strings.Join(urlFormatNames(), ", "),
+				Value: string(_url.FormatAuto),
+			},
+			&cli.StringFlag{
+				Name:  flagSelector,
+				Usage: "location of the prefixes: a JSON path, e.g. $.prefixes[*].ip_prefix, or a CSV column name or index",
+			},
+		}, urlAuthFlags()...),
 		Action: func(c *cli.Context) error {
 			urlList := c.Args().Slice()
 			path, stdout, err := resolveOutputTargets(c)
@@ -45,17 +76,10 @@ func urlCmd() *cli.Command {
 			}
 
 			h := _url.New()
-			var requests []_url.Request
-			for _, u := range urlList {
-				parsedURL, parseErr := url.Parse(u)
-				if parseErr != nil {
-					continue
-				}
-
-				requests = append(requests, _url.Request{
-					URL:    parsedURL,
-					Method: "GET",
-				})
+
+			requests, invalid, err := urlRequests(c, urlList)
+			if err != nil {
+				return err
 			}
 
 			if isEnvEnabled("IP_FETCHER_MOCK_URL") {
@@ -69,22 +93,175 @@ func urlCmd() *cli.Command {
 				gock.InterceptClient(h.HTTPClient.HTTPClient)
 			}
 
-			prefixes, err := h.FetchPrefixesAsText(requests)
-			if err != nil {
+			result := h.FetchSources(requests)
+			result.Sources = append(result.Sources, invalid...)
+
+			if err = checkURLSources(result, c.Bool(flagStrict)); err != nil {
 				return err
 			}
 
-			if len(prefixes) == 0 {
-				return errors.New("no prefixes found")
+			if c.Bool(flagSummary) {
+				writeURLSummary(os.Stderr, result)
 			}
 
-			joined := strings.Join(prefixes, "\n")
+			data, fileName, err := urlOutput(result, c.String(flagFormat))
+			if err != nil {
+				return err
+			}
 
 			return writeOutputs(path, stdout, SaveFileInput{
 				Provider:        providerName,
 				DefaultFileName: fileName,
-				Data:            []byte(joined),
+				Data:            data,
 			})
 		},
 	}
 }
+
+// checkStdinOnce returns an error if standard input is given as more than one source, as it can only be read once.
+func checkStdinOnce(sources []string) error {
+	if i := slices.Index(sources, _url.Stdin); i != -1 && slices.Contains(sources[i+1:], _url.Stdin) {
+		return fmt.Errorf("standard input (%s) can only be given once", _url.Stdin)
+	}
+
+	return nil
+}
+
+// urlRequests builds a request for each url with the format, headers and authentication configured
+// for it. Flags take precedence over the configuration file and headers for a single url
+// take precedence over those for every url. Sources that cannot be parsed are reported as failed.
+func urlRequests(c *cli.Context, urlList []string) ([]_url.Request, []_url.SourceReport, error) {
+	cfg := configFromContext(c)
+
+	configured, err := mergeHeaders(cfg.Providers[c.Command.Name].Headers, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	common, err := mergeHeaders(nil, c.StringSlice(flagHeader))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	flagAuth, err := authFromFlags(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	perURL := make(map[string]http.Header)
+
+	for _, v := range c.StringSlice(flagURLHeader) {
+		u, name, value, parseErr := parseURLHeader(v)
+		if parseErr != nil {
+			return nil, nil, parseErr
+		}
+
+		if perURL[u] == nil {
+			perURL[u] = http.Header{}
+		}
+
+		perURL[u].Add(name, value)
+	}
+
+	if err = checkStdinOnce(urlList); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		requests []_url.Request
+		invalid  []_url.SourceReport
+	)
+
+	for _, u := range urlList {
+		parsedURL, parseErr := _url.ParseSource(u)
+		if parseErr != nil {
+			invalid = append(invalid, invalidSource(u, parseErr))
+
+			continue
+		}
+
+		header := configured.Clone()
+		for _, h := range []http.Header{cfg.urlHeaders(u), common, perURL[u]} {
+			for k, v := range h {
+				header[k] = v
+			}
+		}
+
+		format, selector, formatErr := urlFormat(c, cfg, u)
+		if formatErr != nil {
+			return nil, nil, formatErr
+		}
+
+		req := _url.Request{
+			URL:      parsedURL,
+			Method:   "GET",
+			Header:   header,
+			Format:   format,
+			Selector: selector,
+		}
+
+		if err = applyAuth(&req, mergeAuth(cfg.urlAuth(u), flagAuth)); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", req.URL.Redacted(), err)
+		}
+
+		requests = append(requests, req)
+	}
+
+	return requests, invalid, nil
+}
+
+// invalidSource reports a source that could not be parsed, masking any password as
+// url.URL.Redacted does.
+func invalidSource(source string, err error) _url.SourceReport {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		err = ue.Err
+	}
+
+	err = fmt.Errorf("invalid url: %w", err)
+
+	if scheme, rest, ok := strings.Cut(source, "://"); ok {
+		end := len(rest)
+		if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+			end = i
+		}
+
+		if at := strings.LastIndex(rest[:end], "@"); at >= 0 {
+			if user, _, hasPassword := strings.Cut(rest[:at], ":"); hasPassword {
+				source = scheme + "://" + user + ":xxxxx" + rest[at:]
+			}
+		}
+	}
+
+	return _url.SourceReport{Source: source, Error: err.Error(), Err: err}
+}
+
+// urlFormat returns the format and selector for a url. Flags apply to every url and take
+// precedence over the configuration file.
+func urlFormat(c *cli.Context, cfg Config, u string) (_url.Format, string, error) {
+	name, selector := cfg.urlFormat(u)
+
+	if c.IsSet(flagSourceFormat) || name == "" {
+		name = c.String(flagSourceFormat)
+	}
+
+	if c.IsSet(flagSelector) {
+		selector = c.String(flagSelector)
+	}
+
+	format, err := _url.ParseFormat(name)
+	if err != nil {
+		return "", "", err
+	}
+
+	return format, selector, nil
+}
+
+func urlFormatNames() []string {
+	names := make([]string, len(_url.Formats))
+	for i, f := range _url.Formats {
+		names[i] = string(f)
+	}
+
+	return names
+}
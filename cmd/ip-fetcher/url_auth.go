@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	_url "github.com/jonhadfield/ip-fetcher/providers/url"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	flagBearerToken       = "bearer-token"
+	flagBasicAuthUser     = "basic-auth-user"
+	flagBasicAuthPassword = "basic-auth-password"
+	flagAuthHeader        = "auth-header"
+
+	secretEnvPrefix  = "env:"
+	secretFilePrefix = "file:"
+)
+
+var errSecretSource = errors.New("credentials must be given as env:NAME or file:PATH") //nolint:gochecknoglobals
+
+// AuthConfig authenticates requests made by the url command. Credentials are references,
+// as env:NAME or file:PATH, so that they never appear in the configuration or process args.
+type AuthConfig struct {
+	BearerToken string            `yaml:"bearer_token"`
+	Username    string            `yaml:"username"`
+	Password    string            `yaml:"password"`
+	Headers     map[string]string `yaml:"headers"`
+}
+
+func urlAuthFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  flagBearerToken,
+			Usage: "bearer token for every url, as env:NAME or file:PATH",
+		},
+		&cli.StringFlag{
+			Name:  flagBasicAuthUser,
+			Usage: "basic auth username for every url",
+		},
+		&cli.StringFlag{
+			Name:  flagBasicAuthPassword,
+			Usage: "basic auth password for every url, as env:NAME or file:PATH",
+		},
+		&cli.StringSliceFlag{
+			Name:  flagAuthHeader,
+			Usage: "secret header for every url, as 'Name: env:NAME' or 'Name: file:PATH' (repeatable)",
+		},
+	}
+}
+
+// authFromFlags returns the authentication given by flags, which applies to every url.
+func authFromFlags(c *cli.Context) (AuthConfig, error) {
+	auth := AuthConfig{
+		BearerToken: c.String(flagBearerToken),
+		Username:    c.String(flagBasicAuthUser),
+		Password:    c.String(flagBasicAuthPassword),
+	}
+
+	for _, v := range c.StringSlice(flagAuthHeader) {
+		name, ref, err := parseHeader(v)
+		if err != nil {
+			return AuthConfig{}, fmt.Errorf("%s: %w", flagAuthHeader, err)
+		}
+
+		if auth.Headers == nil {
+			auth.Headers = make(map[string]string)
+		}
+
+		auth.Headers[name] = ref
+	}
+
+	return auth, nil
+}
+
+// urlAuth returns the authentication for the configuration entry with the longest url matching u.
+func (c Config) urlAuth(u string) AuthConfig {
+	var (
+		auth    AuthConfig
+		matched int
+	)
+
+	for _, uc := range c.URLs {
+		if uc.Auth == nil || !matchSource(uc.URL, u) || len(uc.URL) < matched {
+			continue
+		}
+
+		auth, matched = *uc.Auth, len(uc.URL)
+	}
+
+	return auth
+}
+
+// mergeAuth returns base with each credential set in override replacing its own.
+func mergeAuth(base, override AuthConfig) AuthConfig {
+	if override.BearerToken != "" {
+		base.BearerToken = override.BearerToken
+	}
+
+	if override.Username != "" || override.Password != "" {
+		base.Username, base.Password = override.Username, override.Password
+	}
+
+	if len(override.Headers) > 0 {
+		headers := make(map[string]string, len(base.Headers)+len(override.Headers))
+		for k, v := range base.Headers {
+			headers[k] = v
+		}
+
+		for k, v := range override.Headers {
+			headers[k] = v
+		}
+
+		base.Headers = headers
+	}
+
+	return base
+}
+
+// applyAuth reads the referenced credentials and sets them on the request.
+func applyAuth(req *_url.Request, auth AuthConfig) error {
+	if auth.BearerToken != "" {
+		token, err := readSecret(auth.BearerToken)
+		if err != nil {
+			return fmt.Errorf("bearer token: %w", err)
+		}
+
+		req.SetBearerToken(token)
+	}
+
+	if auth.Username != "" || auth.Password != "" {
+		if auth.Username == "" || auth.Password == "" {
+			return errors.New("basic auth requires both a username and a password")
+		}
+
+		password, err := readSecret(auth.Password)
+		if err != nil {
+			return fmt.Errorf("basic auth password: %w", err)
+		}
+
+		req.SetBasicAuth(auth.Username, password)
+	}
+
+	for name, ref := range auth.Headers {
+		if !validHeaderName(name) {
+			return fmt.Errorf("invalid header name: %q", name)
+		}
+
+		value, err := readSecret(ref)
+		if err != nil {
+			return fmt.Errorf("header %s: %w", name, err)
+		}
+
+		req.SetSecretHeader(name, value)
+	}
+
+	return nil
+}
+
+// readSecret returns the credential referenced as env:NAME or file:PATH.
+// Trailing newlines are removed from file content.
+func readSecret(ref string) (string, error) {
+	var value string
+
+	switch {
+	case strings.HasPrefix(ref, secretEnvPrefix):
+		name := strings.TrimPrefix(ref, secretEnvPrefix)
+		value = os.Getenv(name)
+
+		if value == "" {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+	case strings.HasPrefix(ref, secretFilePrefix):
+		path := strings.TrimPrefix(ref, secretFilePrefix)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+
+		value = strings.TrimRight(string(data), "\r\n")
+
+		if value == "" {
+			return "", fmt.Errorf("file %s is empty", path)
+		}
+	default:
+		return "", errSecretSource
+	}
+
+	return value, nil
+}
@@ -0,0 +1,82 @@
+package main_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mainpkg "github.com/jonhadfield/ip-fetcher/cmd/ip-fetcher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLCmdAuthentication(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+
+		switch {
+		case r.URL.Path == "/lookalike" && r.Header.Get("Authorization") == "":
+			_, _ = w.Write([]byte("9.9.9.0/24\n"))
+		case r.URL.Path == "/bearer" && r.Header.Get("Authorization") == "Bearer env-token",
+			r.URL.Path == "/basic" && user == "feed" && pass == "file-password",
+			r.URL.Path == "/key" && r.Header.Get("X-Api-Key") == "file-key":
+			_, _ = w.Write([]byte("9.9.9.0/24\n"))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer ts.Close()
+
+	tDir := t.TempDir()
+	passwordFile := filepath.Join(tDir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("file-password\n"), 0o600))
+
+	keyFile := filepath.Join(tDir, "key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("file-key"), 0o600))
+
+	// the server's url without its port, which prefixes the urls requested
+	lookalike := ts.URL[:strings.LastIndex(ts.URL, ":")]
+
+	configPath := filepath.Join(tDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`urls:
+  - url: `+ts.URL+`/basic
+    auth:
+      username: feed
+      password: file:`+passwordFile+`
+  - url: `+lookalike+`
+    auth:
+      bearer_token: env:TEST_FEED_TOKEN
+`), 0o600))
+
+	t.Setenv("TEST_FEED_TOKEN", "env-token")
+
+	outPath := filepath.Join(tDir, "ips.txt")
+
+	app := mainpkg.GetApp()
+
+	os.Args = []string{"ip-fetcher", "url", "--Path", outPath, "--bearer-token", "env:TEST_FEED_TOKEN", ts.URL + "/bearer"}
+	require.NoError(t, app.Run(os.Args))
+
+	os.Args = []string{"ip-fetcher", "--config", configPath, "url", "--Path", outPath, ts.URL + "/basic"}
+	require.NoError(t, app.Run(os.Args))
+
+	// credentials aren't sent to other hosts whose urls the configured url prefixes
+	os.Args = []string{"ip-fetcher", "--config", configPath, "url", "--Path", outPath, "--strict", ts.URL + "/lookalike"}
+	require.NoError(t, app.Run(os.Args))
+
+	os.Args = []string{"ip-fetcher", "url", "--Path", outPath, "--auth-header", "X-Api-Key: file:" + keyFile, ts.URL + "/key"}
+	require.NoError(t, app.Run(os.Args))
+
+	// credentials given directly would be visible in the process args
+	os.Args = []string{"ip-fetcher", "url", "--Path", outPath, "--bearer-token", "literal-token", ts.URL + "/bearer"}
+	err := app.Run(os.Args)
+	require.ErrorContains(t, err, "env:NAME or file:PATH")
+	require.NotContains(t, err.Error(), "literal-token")
+
+	os.Args = []string{"ip-fetcher", "url", "--Path", outPath, "--bearer-token", "env:TEST_FEED_TOKEN_UNSET", ts.URL + "/bearer"}
+	require.ErrorContains(t, app.Run(os.Args), "TEST_FEED_TOKEN_UNSET is not set")
+}
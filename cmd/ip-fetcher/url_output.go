@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	encsv "encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	_url "github.com/jonhadfield/ip-fetcher/providers/url"
+)
+
+const (
+	flagSummary = "summary"
+	flagStrict  = "strict"
+
+	urlFileNameLines = "ips.txt"
+	urlFileNameJSON  = "ips.json"
+	urlFileNameCSV   = "ips.csv"
+)
+
+var urlOutputFormats = []string{formatLines, formatJSON, formatCSV} //nolint:gochecknoglobals
+
+// urlPrefix is a prefix and the sources it was read from.
+type urlPrefix struct {
+	Prefix  string   `json:"prefix"`
+	Sources []string `json:"sources"`
+}
+
+// urlDoc is the JSON output of the url command.
+type urlDoc struct {
+	Prefixes []urlPrefix         `json:"prefixes"`
+	Sources  []_url.SourceReport `json:"sources"`
+}
+
+// checkURLSources warns of each source that failed and, in strict mode, fails if any did.
+// It also fails when no prefixes were read.
+func checkURLSources(result _url.Result, strict bool) error {
+	failed := result.Failed()
+
+	errs := make([]error, len(failed))
+	for i, f := range failed {
+		errs[i] = fmt.Errorf("%s: %w", f.Source, f.Err)
+	}
+
+	switch {
+	case strict && len(failed) > 0:
+		return fmt.Errorf("%d of %d sources failed: %w", len(failed), len(result.Sources), errors.Join(errs...))
+	case len(failed) == len(result.Sources) && len(failed) > 0:
+		return fmt.Errorf("all sources failed: %w", errors.Join(errs...))
+	}
+
+	for _, err := range errs {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+	}
+
+	if len(result.Prefixes) == 0 {
+		return errNoPrefixes
+	}
+
+	return nil
+}
+
+// writeURLSummary writes a table of what was read from each source followed by the invalid
+// values read, with their line numbers where known.
+func writeURLSummary(w io.Writer, result _url.Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "SOURCE\tPREFIXES\tINVALID\tCOMMENTED\tSTATUS")
+
+	for _, s := range result.Sources {
+		status := "ok"
+		if s.Err != nil {
+			status = "failed: " + s.Error
+		}
+
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\n", s.Source, s.Prefixes, s.Invalid, s.Commented, status)
+	}
+
+	_, _ = fmt.Fprintf(tw, "total\t%d unique\t\t\t\n", len(result.Prefixes))
+
+	_ = tw.Flush()
+
+	for _, s := range result.Sources {
+		for _, e := range s.Errors {
+			_, _ = fmt.Fprintf(w, "%s: %s\n", s.Source, e.Error())
+		}
+
+		if omitted := s.Invalid - int64(len(s.Errors)); omitted > 0 {
+			_, _ = fmt.Fprintf(w, "%s: %d more invalid\n", s.Source, omitted)
+		}
+	}
+}
+
+// urlOutput renders the result in the given format, returning the data and default file name.
+func urlOutput(result _url.Result, format string) ([]byte, string, error) {
+	if !slices.Contains(urlOutputFormats, format) {
+		return nil, "", fmt.Errorf("invalid format: %s\n       choose from: %s",
+			format, strings.Join(urlOutputFormats, ", "))
+	}
+
+	prefixes := result.SortedPrefixes()
+
+	switch format {
+	case formatJSON:
+		doc := urlDoc{
+			Prefixes: make([]urlPrefix, len(prefixes)),
+			Sources:  result.Sources,
+		}
+
+		for i, p := range prefixes {
+			doc.Prefixes[i] = urlPrefix{Prefix: p.String(), Sources: result.Prefixes[p]}
+		}
+
+		data, err := json.MarshalIndent(doc, "", " ")
+
+		return data, urlFileNameJSON, err
+	case formatCSV:
+		var buf bytes.Buffer
+
+		cw := encsv.NewWriter(&buf)
+		_ = cw.Write([]string{"prefix", "source"})
+
+		for _, p := range prefixes {
+			for _, s := range result.Prefixes[p] {
+				_ = cw.Write([]string{p.String(), s})
+			}
+		}
+
+		cw.Flush()
+
+		return buf.Bytes(), urlFileNameCSV, cw.Error()
+	default:
+		lines := make([]string, len(prefixes))
+		for i, p := range prefixes {
+			lines[i] = p.String()
+		}
+
+		return []byte(strings.Join(lines, "\n")), urlFileNameLines, nil
+	}
+}
@@ -2,11 +2,16 @@ package main_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
 	_ "github.com/agiledragon/gomonkey/v2"
@@ -145,3 +150,188 @@ func TestUrlCmdStdOutAndFile(t *testing.T) {
 	require.Contains(t, out, "9.9.9.0/24")
 	require.FileExists(t, filepath.Join(tDir, "ips.txt"))
 }
+
+func TestURLCmdSourceFormats(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed.json":
+			_, _ = w.Write([]byte(`{"ranges":[{"cidr":"192.0.2.0/24","note":"198.51.100.0/24"}]}`))
+		case "/feed.csv", "/feed.json.csv":
+			_, _ = w.Write([]byte("name,cidr\nweb,203.0.113.0/24\n"))
+		}
+	}))
+	defer ts.Close()
+
+	tDir := t.TempDir()
+	configPath := filepath.Join(tDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`urls:
+  - url: `+ts.URL+`/feed.json
+    format: json
+    selector: $.ranges[*].cidr
+`), 0o600))
+
+	outPath := filepath.Join(tDir, "ips.txt")
+
+	app := mainpkg.GetApp()
+	os.Args = []string{"ip-fetcher", "--config", configPath, "url", "--Path", outPath, ts.URL + "/feed.json", ts.URL + "/feed.csv"}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err := os.ReadFile(outPath)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"192.0.2.0/24", "203.0.113.0/24"}, strings.Split(string(data), "\n"))
+
+	// entries match whole path segments, so a url the entry's url prefixes keeps its own format
+	os.Args = []string{"ip-fetcher", "--config", configPath, "url", "--Path", outPath, "--strict", ts.URL + "/feed.json.csv"}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err = os.ReadFile(outPath)
+	require.NoError(t, err)
+	require.Equal(t, "203.0.113.0/24", string(data))
+
+	os.Args = []string{"ip-fetcher", "url", "--Path", outPath, "--source-format", "csv", "--selector", "cidr", ts.URL + "/feed.csv"}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err = os.ReadFile(outPath)
+	require.NoError(t, err)
+	require.Equal(t, "203.0.113.0/24", string(data))
+
+	os.Args = []string{"ip-fetcher", "url", "--Path", outPath, "--source-format", "xml", ts.URL + "/feed.csv"}
+	require.ErrorContains(t, app.Run(os.Args), "unsupported format")
+}
+
+func TestURLCmdLocalSources(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	tDir := t.TempDir()
+	localPath := filepath.Join(tDir, "allow.txt")
+	require.NoError(t, os.WriteFile(localPath, []byte("192.0.2.0/24\n"), 0o600))
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	oldStdin := os.Stdin
+	os.Stdin = r
+
+	defer func() { os.Stdin = oldStdin }()
+
+	_, err = w.WriteString("203.0.113.0/24\n")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	outPath := filepath.Join(tDir, "ips.txt")
+
+	app := mainpkg.GetApp()
+	os.Args = []string{"ip-fetcher", "url", "--Path", outPath, localPath, "-"}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err := os.ReadFile(outPath)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"192.0.2.0/24", "203.0.113.0/24"}, strings.Split(string(data), "\n"))
+
+	// standard input can only be read once
+	os.Args = []string{"ip-fetcher", "url", "--Path", outPath, "-", localPath, "-"}
+	require.ErrorContains(t, app.Run(os.Args), "standard input (-) can only be given once")
+}
+
+func TestURLCmdOutputFormatsAndStrict(t *testing.T) {
+	defer testCleanUp(os.Args)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			_, _ = w.Write([]byte("# a\n192.0.2.0/24\n198.51.100.0/24\nnonsense\n"))
+		case "/b":
+			_, _ = w.Write([]byte("198.51.100.0/24\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	tDir := t.TempDir()
+	a, b, missing := ts.URL+"/a", ts.URL+"/b", ts.URL+"/missing"
+
+	app := mainpkg.GetApp()
+
+	// json includes the sources of each prefix and a summary of each source
+	os.Args = []string{"ip-fetcher", "url", "--Path", tDir, "--format", "json", a, b, missing}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err := os.ReadFile(filepath.Join(tDir, "ips.json"))
+	require.NoError(t, err)
+
+	var doc struct {
+		Prefixes []struct {
+			Prefix  string   `json:"prefix"`
+			Sources []string `json:"sources"`
+		} `json:"prefixes"`
+		Sources []struct {
+			Source    string `json:"source"`
+			Prefixes  int    `json:"prefixes"`
+			Invalid   int    `json:"invalid"`
+			Commented int    `json:"commented"`
+			Error     string `json:"error"`
+		} `json:"sources"`
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+
+	require.Len(t, doc.Prefixes, 2)
+	require.Equal(t, "192.0.2.0/24", doc.Prefixes[0].Prefix)
+	require.Equal(t, []string{a}, doc.Prefixes[0].Sources)
+	require.Equal(t, []string{a, b}, doc.Prefixes[1].Sources)
+
+	require.Len(t, doc.Sources, 3)
+	require.Equal(t, 2, doc.Sources[0].Prefixes)
+	require.Equal(t, 1, doc.Sources[0].Invalid)
+	require.Equal(t, 1, doc.Sources[0].Commented)
+	require.Contains(t, doc.Sources[2].Error, "404")
+
+	// csv has a row for each prefix and source
+	os.Args = []string{"ip-fetcher", "url", "--Path", tDir, "--format", "csv", a, b}
+	require.NoError(t, app.Run(os.Args))
+
+	data, err = os.ReadFile(filepath.Join(tDir, "ips.csv"))
+	require.NoError(t, err)
+	require.Equal(t, "prefix,source\n192.0.2.0/24,"+a+"\n198.51.100.0/24,"+a+"\n198.51.100.0/24,"+b+"\n", string(data))
+
+	// strict mode fails when any source fails
+	os.Args = []string{"ip-fetcher", "url", "--Path", tDir, "--strict", a, missing}
+	err = app.Run(os.Args)
+	require.ErrorContains(t, err, "1 of 2 sources failed")
+	require.ErrorContains(t, err, missing)
+
+	// as do sources that cannot be parsed, with any password masked
+	invalid := "https://user:secret@[::1/ips.txt"
+	os.Args = []string{"ip-fetcher", "url", "--Path", tDir, "--strict", a, invalid}
+	err = app.Run(os.Args)
+	require.ErrorContains(t, err, "1 of 2 sources failed")
+	require.ErrorContains(t, err, "https://user:xxxxx@[::1/ips.txt: invalid url: missing ']' in host")
+	require.NotContains(t, err.Error(), "secret")
+
+	os.Args = []string{"ip-fetcher", "url", "--Path", tDir, a, invalid}
+	require.NoError(t, app.Run(os.Args))
+
+	// the summary is written to stderr
+	oldStderr := os.Stderr
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	os.Stderr = w
+
+	os.Args = []string{"ip-fetcher", "url", "--Path", tDir, "--summary", a, missing}
+	runErr := app.Run(os.Args)
+
+	_ = w.Close()
+	os.Stderr = oldStderr
+
+	require.NoError(t, runErr)
+
+	summary, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Contains(t, string(summary), "warning: "+missing)
+	require.Regexp(t, `(?m)^`+regexp.QuoteMeta(a)+`\s+2\s+1\s+1\s+ok$`, string(summary))
+	require.Contains(t, string(summary), "total")
+	require.Contains(t, string(summary), a+": line 4: \"nonsense\"")
+}
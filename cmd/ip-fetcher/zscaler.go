@@ -1,12 +1,9 @@
 package main
 
 import (
-	"fmt"
 	"net/http"
 	"net/url"
 
-	output2 "github.com/jonhadfield/ip-fetcher/internal/output"
-
 	"github.com/jonhadfield/ip-fetcher/providers/zscaler"
 	"github.com/urfave/cli/v2"
 	"gopkg.in/h2non/gock.v1"
@@ -61,13 +58,7 @@ func zscalerCmd() *cli.Command {
 				return err
 			}
 
-			if stdout {
-				if err = output2.PrettyPrintJSON(data); err != nil {
-					return fmt.Errorf("error printing data to stdout: %w", err)
-				}
-			}
-
-			return writeOutputs(path, false, SaveFileInput{
+			return writeOutputs(path, stdout, SaveFileInput{
 				Provider:        providerName,
 				DefaultFileName: fileName,
 				Data:            data,
@@ -0,0 +1,21 @@
+package fetchers
+
+import "github.com/jonhadfield/ip-fetcher/internal/web"
+
+// Fetch failure categories returned by the providers. Use errors.Is to test for a
+// category and errors.As with *StatusError or *ParseError to inspect the details.
+var (
+	ErrNetwork          = web.ErrNetwork          //nolint:gochecknoglobals
+	ErrUnexpectedStatus = web.ErrUnexpectedStatus //nolint:gochecknoglobals
+	ErrRateLimited      = web.ErrRateLimited      //nolint:gochecknoglobals
+	ErrAuth             = web.ErrAuth             //nolint:gochecknoglobals
+	ErrParse            = web.ErrParse            //nolint:gochecknoglobals
+	ErrEmptyResult      = web.ErrEmptyResult      //nolint:gochecknoglobals
+)
+
+type (
+	// StatusError reports a non-2xx response, including the status code.
+	StatusError = web.StatusError
+	// ParseError reports a response that could not be parsed.
+	ParseError = web.ParseError
+)
@@ -0,0 +1,144 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Attributes describe a prefix, such as its region or service, by lower case name. An attribute
+// may have several values, such as a range's tags.
+type Attributes map[string][]string
+
+// Condition holds when an attribute has a value matching one of its patterns or, if negated,
+// when it has none. Patterns are case-insensitive and may contain '*' wildcards.
+type Condition struct {
+	Key      string
+	Patterns []string
+	Negate   bool
+}
+
+func (c Condition) match(attrs Attributes) bool {
+	for _, v := range attrs[c.Key] {
+		v = strings.ToLower(v)
+
+		for _, p := range c.Patterns {
+			if ok, _ := path.Match(p, v); ok {
+				return !c.Negate
+			}
+		}
+	}
+
+	return c.Negate
+}
+
+// Expression matches attributes meeting all of its conditions.
+type Expression []Condition
+
+// ParseExpression parses comma separated conditions of the form key=value or key!=value, where
+// value may list alternatives separated by '|', e.g. region=eu-west-1|eu-west-2,service=EC2 or
+// tag!=OSN.
+func ParseExpression(s string) (Expression, error) {
+	var e Expression
+
+	for term := range strings.SplitSeq(s, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(term, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid filter %q: expected key=value", term)
+		}
+
+		c := Condition{Key: strings.ToLower(strings.TrimSpace(key))}
+
+		if strings.HasSuffix(c.Key, "!") {
+			c.Key = strings.TrimSpace(strings.TrimSuffix(c.Key, "!"))
+			c.Negate = true
+		}
+
+		if c.Key == "" {
+			return nil, fmt.Errorf("invalid filter %q: missing key", term)
+		}
+
+		for p := range strings.SplitSeq(value, "|") {
+			p = strings.ToLower(strings.TrimSpace(p))
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("invalid filter %q: %w", term, err)
+			}
+
+			c.Patterns = append(c.Patterns, p)
+		}
+
+		e = append(e, c)
+	}
+
+	if len(e) == 0 {
+		return nil, errors.New("empty filter")
+	}
+
+	return e, nil
+}
+
+// Match reports whether attrs meet all of the expression's conditions.
+func (e Expression) Match(attrs Attributes) bool {
+	for _, c := range e {
+		if !c.match(attrs) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Selector matches attributes matched by any of its expressions. An empty Selector matches
+// everything.
+type Selector []Expression
+
+// ParseSelector parses each expression of a Selector.
+func ParseSelector(expressions []string) (Selector, error) {
+	var s Selector
+
+	for _, v := range expressions {
+		e, err := ParseExpression(v)
+		if err != nil {
+			return nil, err
+		}
+
+		s = append(s, e)
+	}
+
+	return s, nil
+}
+
+// Match reports whether attrs are matched by any of the selector's expressions.
+func (s Selector) Match(attrs Attributes) bool {
+	if len(s) == 0 {
+		return true
+	}
+
+	for _, e := range s {
+		if e.Match(attrs) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate returns an error if the selector has conditions on attributes other than keys.
+func (s Selector) Validate(keys ...string) error {
+	for _, e := range s {
+		for _, c := range e {
+			if !slices.Contains(keys, c.Key) {
+				return fmt.Errorf("unknown filter key: %s\n       choose from: %s", c.Key, strings.Join(keys, ", "))
+			}
+		}
+	}
+
+	return nil
+}
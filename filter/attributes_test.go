@@ -0,0 +1,48 @@
+package filter_test
+
+import (
+	"testing"
+
+	"github.com/jonhadfield/ip-fetcher/filter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelector(t *testing.T) {
+	euEC2 := filter.Attributes{"region": {"eu-west-1"}, "service": {"EC2"}}
+	usCloudfront := filter.Attributes{"region": {"us-east-1"}, "service": {"CLOUDFRONT"}}
+	tagged := filter.Attributes{"region": {"uk-london-1"}, "tag": {"OCI", "OSN"}}
+
+	tests := []struct {
+		expressions []string
+		want        []bool
+	}{
+		{nil, []bool{true, true, true}},
+		{[]string{"region=eu-west-1,service=ec2"}, []bool{true, false, false}},
+		{[]string{"region=eu-west-1,service=CLOUDFRONT"}, []bool{false, false, false}},
+		{[]string{"region=eu-*|uk-*"}, []bool{true, false, true}},
+		{[]string{"region!=us-*"}, []bool{true, false, true}},
+		{[]string{"tag=OSN"}, []bool{false, false, true}},
+		{[]string{"tag!=osn"}, []bool{true, true, false}},
+		{[]string{"service=EC2", "service=CLOUDFRONT"}, []bool{true, true, false}},
+	}
+
+	for _, tt := range tests {
+		s, err := filter.ParseSelector(tt.expressions)
+		require.NoError(t, err)
+
+		got := []bool{s.Match(euEC2), s.Match(usCloudfront), s.Match(tagged)}
+		require.Equal(t, tt.want, got, tt.expressions)
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	for _, s := range []string{"", ",", "region", "=eu-west-1", "region=[", "!=x"} {
+		_, err := filter.ParseExpression(s)
+		require.Error(t, err, s)
+	}
+
+	s, err := filter.ParseSelector([]string{"Region=eu-west-1", "zone=a"})
+	require.NoError(t, err)
+	require.NoError(t, s[:1].Validate("region", "service"))
+	require.ErrorContains(t, s.Validate("region", "service"), "unknown filter key: zone")
+}
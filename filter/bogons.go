@@ -0,0 +1,51 @@
+package filter
+
+import "net/netip"
+
+// bogons are the ranges that are never globally routable: those in the IANA IPv4 and IPv6
+// special-purpose address registries that are not globally reachable, plus multicast.
+//
+// https://www.iana.org/assignments/iana-ipv4-special-registry
+// https://www.iana.org/assignments/iana-ipv6-special-registry
+var bogons = []Entry{ //nolint:gochecknoglobals
+	{netip.MustParsePrefix("0.0.0.0/8"), "this network"},
+	{netip.MustParsePrefix("10.0.0.0/8"), "private-use"},
+	{netip.MustParsePrefix("100.64.0.0/10"), "shared address space"},
+	{netip.MustParsePrefix("127.0.0.0/8"), "loopback"},
+	{netip.MustParsePrefix("169.254.0.0/16"), "link local"},
+	{netip.MustParsePrefix("172.16.0.0/12"), "private-use"},
+	{netip.MustParsePrefix("192.0.0.0/24"), "IETF protocol assignments"},
+	{netip.MustParsePrefix("192.0.2.0/24"), "documentation"},
+	{netip.MustParsePrefix("192.88.99.0/24"), "deprecated 6to4 relay anycast"},
+	{netip.MustParsePrefix("192.168.0.0/16"), "private-use"},
+	{netip.MustParsePrefix("198.18.0.0/15"), "benchmarking"},
+	{netip.MustParsePrefix("198.51.100.0/24"), "documentation"},
+	{netip.MustParsePrefix("203.0.113.0/24"), "documentation"},
+	{netip.MustParsePrefix("224.0.0.0/4"), "multicast"},
+	{netip.MustParsePrefix("240.0.0.0/4"), "reserved"},
+	{netip.MustParsePrefix("255.255.255.255/32"), "limited broadcast"},
+	{netip.MustParsePrefix("::/128"), "unspecified address"},
+	{netip.MustParsePrefix("::1/128"), "loopback"},
+	{netip.MustParsePrefix("::/96"), "deprecated IPv4-compatible address"},
+	{netip.MustParsePrefix("::ffff:0:0/96"), "IPv4-mapped address"},
+	{netip.MustParsePrefix("64:ff9b:1::/48"), "local-use IPv4/IPv6 translation"},
+	{netip.MustParsePrefix("100::/64"), "discard-only"},
+	{netip.MustParsePrefix("2001:2::/48"), "benchmarking"},
+	{netip.MustParsePrefix("2001:10::/28"), "deprecated ORCHID"},
+	{netip.MustParsePrefix("2001:db8::/32"), "documentation"},
+	{netip.MustParsePrefix("3fff::/20"), "documentation"},
+	{netip.MustParsePrefix("5f00::/16"), "segment routing SIDs"},
+	{netip.MustParsePrefix("fc00::/7"), "unique-local"},
+	{netip.MustParsePrefix("fe80::/10"), "link-local unicast"},
+	{netip.MustParsePrefix("fec0::/10"), "deprecated site-local"},
+	{netip.MustParsePrefix("ff00::/8"), "multicast"},
+}
+
+// Bogons returns the built-in ranges that are never globally routable: private, shared (CGNAT),
+// loopback, link local, documentation, benchmarking, multicast and reserved ranges.
+func Bogons() []Entry {
+	res := make([]Entry, len(bogons))
+	copy(res, bogons)
+
+	return res
+}
@@ -0,0 +1,304 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/netip"
+	"strings"
+)
+
+// Data removes the excluded prefixes from a document, returning the document and the number
+// of values removed. The document is returned unchanged when nothing is excluded.
+//
+// In JSON documents, array elements that are excluded prefixes or addresses, or objects with a
+// member that is, are removed. Other documents are read as lines, such as text, CSV and YAML
+// lists, and lines starting with an excluded prefix or address are removed.
+func (f *Filter) Data(data []byte) ([]byte, int, error) {
+	if f.Len() == 0 {
+		return data, 0, nil
+	}
+
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		return f.jsonData(data, trimmed)
+	}
+
+	return f.lineData(data)
+}
+
+// DataPrefixes returns the prefixes in a document, found as Data finds them: every JSON string
+// that is a prefix or address, or the prefix or address starting each line of other documents.
+func DataPrefixes(data []byte) []netip.Prefix {
+	var res []netip.Prefix
+
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+
+		var err error
+		for tok := json.Token(nil); err == nil; tok, err = dec.Token() {
+			if s, ok := tok.(string); ok {
+				if p, ok := parsePrefix(strings.TrimSpace(s)); ok {
+					res = append(res, p)
+				}
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return res
+		}
+
+		res = nil
+	}
+
+	for line := range bytes.Lines(data) {
+		if p, ok := linePrefix(string(line)); ok {
+			res = append(res, p)
+		}
+	}
+
+	return res
+}
+
+func (f *Filter) lineData(data []byte) ([]byte, int, error) {
+	var (
+		res     bytes.Buffer
+		removed int
+	)
+
+	for line := range bytes.Lines(data) {
+		if p, ok := linePrefix(string(line)); ok && f.Excluded(p) {
+			removed++
+
+			continue
+		}
+
+		res.Write(line)
+	}
+
+	if removed == 0 {
+		return data, 0, nil
+	}
+
+	out := res.Bytes()
+
+	// keep a missing final newline missing when the last line is removed
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		out = bytes.TrimSuffix(bytes.TrimSuffix(out, []byte("\n")), []byte("\r"))
+	}
+
+	return out, removed, nil
+}
+
+// linePrefix returns the prefix or address at the start of a line, ignoring any YAML list
+// marker and quotes.
+func linePrefix(line string) (netip.Prefix, bool) {
+	line = strings.TrimLeft(line, " \t-")
+
+	end := strings.IndexAny(line, " \t\r\n,;")
+	if end >= 0 {
+		line = line[:end]
+	}
+
+	return parsePrefix(strings.Trim(line, `"'`))
+}
+
+// parsePrefix parses a prefix or, as a single address prefix, an address.
+func parsePrefix(s string) (netip.Prefix, bool) {
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+
+		return p, err == nil
+	}
+
+	a, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+
+	return netip.PrefixFrom(a, a.BitLen()), true
+}
+
+func (f *Filter) jsonData(data, trimmed []byte) ([]byte, int, error) {
+	res, removed, err := f.jsonValue(trimmed)
+	if err != nil || removed == 0 {
+		return data, 0, err
+	}
+
+	// keep documents that were indented readable
+	if indent, ok := jsonIndent(trimmed); ok {
+		var buf bytes.Buffer
+		if err = json.Indent(&buf, res, "", indent); err != nil {
+			return data, 0, err
+		}
+
+		res = buf.Bytes()
+	}
+
+	return res, removed, nil
+}
+
+// jsonIndent returns the indent of the first indented line of an indented document.
+func jsonIndent(data []byte) (string, bool) {
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 {
+		return "", false
+	}
+
+	line := data[i+1:]
+	indent := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]
+
+	if len(indent) == 0 {
+		return "  ", true
+	}
+
+	return string(indent), true
+}
+
+// jsonValue removes excluded elements from the arrays in v, keeping the order of object members.
+func (f *Filter) jsonValue(v json.RawMessage) (json.RawMessage, int, error) {
+	v = bytes.TrimSpace(v)
+	if len(v) == 0 {
+		return v, 0, nil
+	}
+
+	switch v[0] {
+	case '[':
+		return f.jsonArray(v)
+	case '{':
+		return f.jsonObject(v)
+	default:
+		return v, 0, nil
+	}
+}
+
+func (f *Filter) jsonArray(v json.RawMessage) (json.RawMessage, int, error) {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(v, &elems); err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		buf     bytes.Buffer
+		removed int
+		n       int
+	)
+
+	buf.WriteByte('[')
+
+	for _, e := range elems {
+		if f.excludedElement(e) {
+			removed++
+
+			continue
+		}
+
+		e, r, err := f.jsonValue(e)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		removed += r
+
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+
+		buf.Write(e)
+		n++
+	}
+
+	buf.WriteByte(']')
+
+	return buf.Bytes(), removed, nil
+}
+
+func (f *Filter) jsonObject(v json.RawMessage) (json.RawMessage, int, error) {
+	dec := json.NewDecoder(bytes.NewReader(v))
+	if _, err := dec.Token(); err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		buf     bytes.Buffer
+		removed int
+	)
+
+	buf.WriteByte('{')
+
+	for n := 0; dec.More(); n++ {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var member json.RawMessage
+		if err = dec.Decode(&member); err != nil {
+			return nil, 0, err
+		}
+
+		member, r, err := f.jsonValue(member)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		removed += r
+
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(member)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), removed, nil
+}
+
+// excludedElement reports whether an array element is an excluded prefix or address, or an
+// object with a member that is.
+func (f *Filter) excludedElement(e json.RawMessage) bool {
+	e = bytes.TrimSpace(e)
+	if len(e) == 0 {
+		return false
+	}
+
+	switch e[0] {
+	case '"':
+		return f.excludedString(e)
+	case '{':
+		var members map[string]json.RawMessage
+		if err := json.Unmarshal(e, &members); err != nil {
+			return false
+		}
+
+		for _, m := range members {
+			if f.excludedString(m) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (f *Filter) excludedString(v json.RawMessage) bool {
+	var s string
+	if err := json.Unmarshal(v, &s); err != nil {
+		return false
+	}
+
+	p, ok := parsePrefix(strings.TrimSpace(s))
+
+	return ok && f.Excluded(p)
+}
@@ -0,0 +1,161 @@
+package filter
+
+import (
+	"net/netip"
+	"reflect"
+)
+
+//nolint:gochecknoglobals
+var (
+	prefixType = reflect.TypeFor[netip.Prefix]()
+	addrType   = reflect.TypeFor[netip.Addr]()
+)
+
+// Doc removes the excluded prefixes from a parsed document, such as a provider's Doc, given as
+// a pointer. It returns the number of values removed.
+//
+// Slice elements that are excluded prefixes or addresses, including strings holding them, are
+// removed, as are structs with a field that is, such as an entry's prefix and its region.
+// Exported fields, slices and map values are searched at any depth.
+func (f *Filter) Doc(doc any) int {
+	if f.Len() == 0 || doc == nil {
+		return 0
+	}
+
+	return f.walk(reflect.ValueOf(doc))
+}
+
+func (f *Filter) walk(v reflect.Value) int {
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			return f.walk(v.Elem())
+		}
+	case reflect.Struct:
+		if v.Type() == prefixType || v.Type() == addrType {
+			return 0
+		}
+
+		removed := 0
+
+		for i := range v.NumField() {
+			if v.Field(i).CanSet() {
+				removed += f.walk(v.Field(i))
+			}
+		}
+
+		return removed
+	case reflect.Slice:
+		return f.walkSlice(v)
+	case reflect.Map:
+		removed := 0
+
+		iter := v.MapRange()
+		for iter.Next() {
+			// map values aren't addressable, so are filtered as copies
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+
+			if n := f.walk(elem); n > 0 {
+				removed += n
+
+				v.SetMapIndex(iter.Key(), elem)
+			}
+		}
+
+		return removed
+	}
+
+	return 0
+}
+
+func (f *Filter) walkSlice(v reflect.Value) int {
+	if !v.CanSet() {
+		return 0
+	}
+
+	removed := 0
+	kept := 0
+
+	for i := range v.Len() {
+		elem := v.Index(i)
+
+		if f.excludedValue(elem) {
+			removed++
+
+			continue
+		}
+
+		removed += f.walk(elem)
+
+		if kept != i {
+			v.Index(kept).Set(elem)
+		}
+
+		kept++
+	}
+
+	if kept < v.Len() {
+		// clear the tail, as slices.Delete does, so removed elements can be collected
+		for i := kept; i < v.Len(); i++ {
+			v.Index(i).SetZero()
+		}
+
+		v.SetLen(kept)
+	}
+
+	return removed
+}
+
+// excludedValue reports whether v is an excluded prefix or address, or a struct with a field
+// that is.
+func (f *Filter) excludedValue(v reflect.Value) bool {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return false
+		}
+
+		v = v.Elem()
+	}
+
+	if p, ok := valuePrefix(v); ok {
+		return f.Excluded(p)
+	}
+
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := range v.NumField() {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+
+		if p, ok := valuePrefix(v.Field(i)); ok && f.Excluded(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// valuePrefix returns the prefix held by a prefix, address or string value.
+func valuePrefix(v reflect.Value) (netip.Prefix, bool) {
+	switch {
+	case v.Type() == prefixType:
+		p, _ := v.Interface().(netip.Prefix)
+
+		return p, p.IsValid()
+	case v.Type() == addrType:
+		a, _ := v.Interface().(netip.Addr)
+		if !a.IsValid() {
+			return netip.Prefix{}, false
+		}
+
+		return netip.PrefixFrom(a, a.BitLen()), true
+	case v.Kind() == reflect.String:
+		return parsePrefix(v.String())
+	}
+
+	return netip.Prefix{}, false
+}
@@ -0,0 +1,67 @@
+package filter
+
+import "net/netip"
+
+// Family is an address family selected for output.
+type Family int
+
+const (
+	// AnyFamily selects both IPv4 and IPv6 prefixes.
+	AnyFamily Family = iota
+	IPv4
+	IPv6
+)
+
+// FamilyOf returns the family selected by flags for each family, where selecting both or
+// neither selects any.
+func FamilyOf(ipv4, ipv6 bool) Family {
+	switch {
+	case ipv4 && !ipv6:
+		return IPv4
+	case ipv6 && !ipv4:
+		return IPv6
+	default:
+		return AnyFamily
+	}
+}
+
+func (f Family) String() string {
+	switch f {
+	case IPv4:
+		return "ipv4"
+	case IPv6:
+		return "ipv6"
+	default:
+		return "any"
+	}
+}
+
+// Contains reports whether p is in the family.
+func (f Family) Contains(p netip.Prefix) bool {
+	switch f {
+	case IPv4:
+		return p.Addr().Is4()
+	case IPv6:
+		return p.Addr().Is6()
+	default:
+		return true
+	}
+}
+
+// Entries returns the entries excluding every prefix outside the family.
+func (f Family) Entries() []Entry {
+	switch f {
+	case IPv4:
+		return []Entry{{Prefix: netip.MustParsePrefix("::/0"), Reason: "not ipv4"}}
+	case IPv6:
+		return []Entry{{Prefix: netip.MustParsePrefix("0.0.0.0/0"), Reason: "not ipv6"}}
+	default:
+		return nil
+	}
+}
+
+// NewFamily returns a Filter excluding prefixes outside family, and any additional entries,
+// for use with Data on line output and documents, or Doc on parsed documents.
+func NewFamily(family Family, entries ...Entry) *Filter {
+	return New(append(family.Entries(), entries...)...)
+}
@@ -0,0 +1,68 @@
+package filter_test
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/jonhadfield/ip-fetcher/filter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFamily(t *testing.T) {
+	require.Equal(t, filter.IPv4, filter.FamilyOf(true, false))
+	require.Equal(t, filter.IPv6, filter.FamilyOf(false, true))
+	require.Equal(t, filter.AnyFamily, filter.FamilyOf(true, true))
+	require.Equal(t, filter.AnyFamily, filter.FamilyOf(false, false))
+
+	v4, v6 := netip.MustParsePrefix("192.0.2.0/24"), netip.MustParsePrefix("2001:db8::/32")
+
+	require.True(t, filter.IPv4.Contains(v4))
+	require.False(t, filter.IPv4.Contains(v6))
+	require.True(t, filter.AnyFamily.Contains(v6))
+
+	require.Equal(t, 0, filter.NewFamily(filter.AnyFamily).Len())
+	require.Equal(t, []netip.Prefix{v6}, filter.NewFamily(filter.IPv6).Prefixes([]netip.Prefix{v4, v6}))
+
+	data, removed, err := filter.NewFamily(filter.IPv4).Data([]byte("192.0.2.0/24\n2001:db8::/32\n198.51.100.0/24\n"))
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+	require.Equal(t, "192.0.2.0/24\n198.51.100.0/24\n", string(data))
+}
+
+func TestDoc(t *testing.T) {
+	type entry struct {
+		Prefix netip.Prefix
+		Region string
+	}
+
+	type properties struct {
+		AddressPrefixes []string
+	}
+
+	doc := struct {
+		Entries    []entry
+		Prefixes   []netip.Prefix
+		Properties []properties
+		ByRegion   map[string][]netip.Addr
+		CreatedBy  string
+	}{
+		Entries: []entry{
+			{netip.MustParsePrefix("2001:db8::/32"), "eu-west-1"},
+			{netip.MustParsePrefix("192.0.2.0/24"), "eu-west-1"},
+			{netip.MustParsePrefix("2001:db8:1::/48"), "us-east-1"},
+		},
+		Prefixes:   []netip.Prefix{netip.MustParsePrefix("2001:db8::/32"), netip.MustParsePrefix("198.51.100.0/24")},
+		Properties: []properties{{AddressPrefixes: []string{"203.0.113.0/24", "2001:db8::/48"}}},
+		ByRegion:   map[string][]netip.Addr{"eu-west-1": {netip.MustParseAddr("2001:db8::1"), netip.MustParseAddr("192.0.2.1")}},
+		CreatedBy:  "2001:db8::1",
+	}
+
+	require.Equal(t, 0, filter.NewFamily(filter.AnyFamily).Doc(&doc))
+	require.Equal(t, 5, filter.NewFamily(filter.IPv4).Doc(&doc))
+
+	require.Equal(t, []entry{{netip.MustParsePrefix("192.0.2.0/24"), "eu-west-1"}}, doc.Entries)
+	require.Equal(t, []netip.Prefix{netip.MustParsePrefix("198.51.100.0/24")}, doc.Prefixes)
+	require.Equal(t, []string{"203.0.113.0/24"}, doc.Properties[0].AddressPrefixes)
+	require.Equal(t, []netip.Addr{netip.MustParseAddr("192.0.2.1")}, doc.ByRegion["eu-west-1"])
+	require.Equal(t, "2001:db8::1", doc.CreatedBy, "only slice elements are removed")
+}
@@ -0,0 +1,169 @@
+// Package filter excludes bogons, and any other listed ranges, from sets of prefixes and from
+// the documents that contain them, and selects prefixes by their attributes, such as region.
+package filter
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/jonhadfield/ip-fetcher/providers/url"
+)
+
+// Entry is a range to exclude and why.
+type Entry struct {
+	Prefix netip.Prefix
+	Reason string
+}
+
+// span is a run of addresses covered by one or more overlapping entries.
+type span struct {
+	start, end netip.Addr
+	entry      Entry
+}
+
+// Filter excludes prefixes that overlap any of its entries. A nil Filter excludes nothing.
+// A Filter is safe for concurrent use.
+type Filter struct {
+	spans   []span
+	entries int
+}
+
+// New returns a Filter excluding prefixes that overlap any of the given entries.
+func New(entries ...Entry) *Filter {
+	spans := make([]span, 0, len(entries))
+
+	for _, e := range entries {
+		if !e.Prefix.IsValid() {
+			continue
+		}
+
+		p := e.Prefix.Masked()
+		spans = append(spans, span{start: p.Addr(), end: lastAddr(p), entry: e})
+	}
+
+	slices.SortFunc(spans, func(a, b span) int {
+		return a.start.Compare(b.start)
+	})
+
+	merged := spans[:0]
+
+	for _, s := range spans {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+
+			if last.end.BitLen() == s.start.BitLen() && (s.start.Compare(last.end) <= 0 || last.end.Next() == s.start) {
+				if s.end.Compare(last.end) > 0 {
+					last.end = s.end
+				}
+
+				continue
+			}
+		}
+
+		merged = append(merged, s)
+	}
+
+	return &Filter{spans: merged, entries: len(entries)}
+}
+
+// NewBogons returns a Filter excluding the built-in bogons and any additional entries.
+func NewBogons(entries ...Entry) *Filter {
+	return New(append(Bogons(), entries...)...)
+}
+
+// Len returns the number of entries the Filter was created with.
+func (f *Filter) Len() int {
+	if f == nil {
+		return 0
+	}
+
+	return f.entries
+}
+
+// Match returns an entry overlapping p, if any.
+func (f *Filter) Match(p netip.Prefix) (Entry, bool) {
+	if f == nil || !p.IsValid() {
+		return Entry{}, false
+	}
+
+	p = p.Masked()
+	start, end := p.Addr(), lastAddr(p)
+
+	i := sort.Search(len(f.spans), func(i int) bool {
+		return f.spans[i].end.Compare(start) >= 0
+	})
+
+	if i < len(f.spans) && f.spans[i].start.BitLen() == start.BitLen() && f.spans[i].start.Compare(end) <= 0 {
+		return f.spans[i].entry, true
+	}
+
+	return Entry{}, false
+}
+
+// Excluded reports whether p overlaps any entry.
+func (f *Filter) Excluded(p netip.Prefix) bool {
+	_, ok := f.Match(p)
+
+	return ok
+}
+
+// Prefixes returns the prefixes that are not excluded, in their original order.
+func (f *Filter) Prefixes(prefixes []netip.Prefix) []netip.Prefix {
+	res := make([]netip.Prefix, 0, len(prefixes))
+
+	for _, p := range prefixes {
+		if !f.Excluded(p) {
+			res = append(res, p)
+		}
+	}
+
+	return res
+}
+
+// ReadEntries reads the prefixes to exclude from r, such as a full bogon list, giving each the
+// reason. Lines may hold any notation read by url sources and may be commented with '#'.
+func ReadEntries(r io.Reader, reason string) ([]Entry, error) {
+	var entries []Entry
+
+	err := url.ReadPrefixes(r, url.FormatText, "", func(p netip.Prefix) error {
+		entries = append(entries, Entry{Prefix: p, Reason: reason})
+
+		return nil
+	})
+
+	return entries, err
+}
+
+// ReadFile reads the prefixes to exclude from the file at path, such as a full bogon list.
+func ReadFile(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries, err := ReadEntries(f, "listed in "+filepath.Base(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return entries, nil
+}
+
+// lastAddr returns the last address in p.
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().AsSlice()
+
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+
+	a, _ := netip.AddrFromSlice(b)
+
+	return a
+}
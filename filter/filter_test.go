@@ -0,0 +1,128 @@
+package filter_test
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jonhadfield/ip-fetcher/filter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBogons(t *testing.T) {
+	f := filter.NewBogons()
+	require.Equal(t, len(filter.Bogons()), f.Len())
+
+	for _, s := range []string{
+		"10.1.2.0/24", "100.64.0.1/32", "127.0.0.1/32", "192.168.0.0/16", "192.0.2.0/25", "198.51.100.7/32",
+		"203.0.113.0/24", "224.0.0.1/32", "255.255.255.255/32", "0.0.0.0/0", "8.0.0.0/6", "::1/128",
+		"2001:db8::/48", "fd00::/8", "fe80::1/128", "ff02::1/128", "::ffff:1.2.3.4/128",
+	} {
+		p := netip.MustParsePrefix(s)
+		e, ok := f.Match(p)
+		require.True(t, ok, s)
+		require.NotEmpty(t, e.Reason, s)
+	}
+
+	for _, s := range []string{
+		"1.1.1.0/24", "8.8.8.8/32", "100.63.255.255/32", "100.128.0.0/10", "172.32.0.0/16", "2606:4700::/32",
+		"2001:4860::/32",
+	} {
+		require.False(t, f.Excluded(netip.MustParsePrefix(s)), s)
+	}
+
+	var nilFilter *filter.Filter
+	require.False(t, nilFilter.Excluded(netip.MustParsePrefix("10.0.0.0/8")))
+}
+
+func TestFilterMergesEntries(t *testing.T) {
+	f := filter.New(
+		filter.Entry{Prefix: netip.MustParsePrefix("192.0.2.0/25"), Reason: "a"},
+		filter.Entry{Prefix: netip.MustParsePrefix("192.0.2.128/25"), Reason: "b"},
+		filter.Entry{Prefix: netip.MustParsePrefix("192.0.2.64/26"), Reason: "c"},
+		filter.Entry{Prefix: netip.MustParsePrefix("2001:db8::/32"), Reason: "d"},
+	)
+
+	require.True(t, f.Excluded(netip.MustParsePrefix("192.0.2.0/24")))
+	require.True(t, f.Excluded(netip.MustParsePrefix("192.0.2.200/32")))
+	require.False(t, f.Excluded(netip.MustParsePrefix("192.0.3.0/24")))
+	require.False(t, f.Excluded(netip.MustParsePrefix("192.0.1.255/32")))
+	require.True(t, f.Excluded(netip.MustParsePrefix("2001:db8:1::/48")))
+	require.False(t, f.Excluded(netip.MustParsePrefix("2001:db9::/32")))
+
+	prefixes := []netip.Prefix{
+		netip.MustParsePrefix("198.51.100.0/24"),
+		netip.MustParsePrefix("192.0.2.1/32"),
+		netip.MustParsePrefix("203.0.113.0/24"),
+	}
+	require.Equal(t, []netip.Prefix{prefixes[0], prefixes[2]}, f.Prefixes(prefixes))
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fullbogons.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# bogons\n41.0.0.0/8\n1.2.3.4-1.2.3.7\n"), 0o600))
+
+	entries, err := filter.ReadFile(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "listed in fullbogons.txt", entries[0].Reason)
+
+	f := filter.New(entries...)
+	require.True(t, f.Excluded(netip.MustParsePrefix("41.1.0.0/16")))
+	require.True(t, f.Excluded(netip.MustParsePrefix("1.2.3.5/32")))
+	require.False(t, f.Excluded(netip.MustParsePrefix("1.2.3.8/32")))
+
+	_, err = filter.ReadFile(filepath.Join(t.TempDir(), "missing.txt"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestData(t *testing.T) {
+	f := filter.NewBogons()
+
+	text := "# list\n1.1.1.0/24\n10.0.0.0/8 private\n192.168.1.1\n2606:4700::/32\n"
+	data, removed, err := f.Data([]byte(text))
+	require.NoError(t, err)
+	require.Equal(t, 2, removed)
+	require.Equal(t, "# list\n1.1.1.0/24\n2606:4700::/32\n", string(data))
+
+	csv := "prefix,source\n1.1.1.0/24,a\n127.0.0.0/8,b\n"
+	data, removed, err = f.Data([]byte(csv))
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+	require.Equal(t, "prefix,source\n1.1.1.0/24,a\n", string(data))
+
+	yaml := "ipv4:\n  - 1.1.1.0/24\n  - \"100.64.0.0/10\"\n"
+	data, removed, err = f.Data([]byte(yaml))
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+	require.Equal(t, "ipv4:\n  - 1.1.1.0/24\n", string(data))
+
+	doc := `{"syncToken":"1","prefixes":[{"ip_prefix":"1.1.1.0/24","region":"eu"},` +
+		`{"ip_prefix":"10.0.0.0/8","region":"eu"}],"values":[{"name":"x","properties":` +
+		`{"addressPrefixes":["fd00::/8","2606:4700::/32"]}}]}`
+	data, removed, err = f.Data([]byte(doc))
+	require.NoError(t, err)
+	require.Equal(t, 2, removed)
+	require.Equal(t, `{"syncToken":"1","prefixes":[{"ip_prefix":"1.1.1.0/24","region":"eu"}],`+
+		`"values":[{"name":"x","properties":{"addressPrefixes":["2606:4700::/32"]}}]}`, string(data))
+
+	// documents without exclusions are unchanged
+	indented := "{\n  \"prefixes\": [ \"1.1.1.0/24\" ]\n}\n"
+	data, removed, err = f.Data([]byte(indented))
+	require.NoError(t, err)
+	require.Zero(t, removed)
+	require.Equal(t, indented, string(data))
+
+	data, removed, err = f.Data([]byte("{\n \"prefixes\": [\"1.1.1.0/24\", \"10.0.0.1\"]\n}"))
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+	require.True(t, strings.HasPrefix(string(data), "{\n \"prefixes\": [\n  \"1.1.1.0/24\"\n ]"))
+
+	var nilFilter *filter.Filter
+	data, removed, err = nilFilter.Data([]byte(text))
+	require.NoError(t, err)
+	require.Zero(t, removed)
+	require.Equal(t, text, string(data))
+}
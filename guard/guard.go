@@ -0,0 +1,139 @@
+// Package guard refuses to replace a list of prefixes with one that has shrunk suspiciously,
+// such as when an upstream returns a partial or empty document.
+package guard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jonhadfield/ip-fetcher/filter"
+)
+
+// DefaultMaxDropPercent is the largest drop in the number of prefixes allowed by default.
+const DefaultMaxDropPercent = 50
+
+// ErrShrink is returned when the number of prefixes drops by more than is allowed.
+var ErrShrink = errors.New("prefix count dropped beyond the allowed threshold")
+
+// Thresholds limit how far the number of prefixes may drop from the previous snapshot.
+// A zero threshold is not checked.
+type Thresholds struct {
+	// MaxDropPercent is the largest drop allowed, as a percentage of the previous count.
+	MaxDropPercent float64
+	// MaxDrop is the largest drop allowed, as a number of prefixes.
+	MaxDrop int
+}
+
+// ShrinkError reports a drop in the number of prefixes beyond the thresholds.
+type ShrinkError struct {
+	Name     string
+	Previous int
+	Current  int
+	Thresholds
+}
+
+func (e *ShrinkError) Error() string {
+	var limits []string
+
+	if e.MaxDropPercent > 0 {
+		limits = append(limits, fmt.Sprintf("%g%%", e.MaxDropPercent))
+	}
+
+	if e.MaxDrop > 0 {
+		limits = append(limits, fmt.Sprintf("%d prefixes", e.MaxDrop))
+	}
+
+	return fmt.Sprintf("%s: prefix count dropped from %d to %d, more than the allowed %s",
+		e.Name, e.Previous, e.Current, strings.Join(limits, " or "))
+}
+
+func (e *ShrinkError) Unwrap() error {
+	return ErrShrink
+}
+
+// Check returns a *ShrinkError if the count of prefixes named name has dropped from previous
+// to current by more than either threshold.
+func (t Thresholds) Check(name string, previous, current int) error {
+	drop := previous - current
+	if drop <= 0 {
+		return nil
+	}
+
+	if (t.MaxDrop > 0 && drop > t.MaxDrop) ||
+		(t.MaxDropPercent > 0 && float64(drop)*100/float64(previous) > t.MaxDropPercent) {
+		return &ShrinkError{Name: name, Previous: previous, Current: current, Thresholds: t}
+	}
+
+	return nil
+}
+
+// CheckData returns a *ShrinkError if the document current holds fewer prefixes than the
+// document previous by more than either threshold.
+func (t Thresholds) CheckData(name string, previous, current []byte) error {
+	return t.Check(name, Count(previous), Count(current))
+}
+
+// Count returns the number of unique prefixes in a document.
+func Count(data []byte) int {
+	seen := make(map[netip.Prefix]struct{})
+
+	for _, p := range filter.DataPrefixes(data) {
+		seen[p.Masked()] = struct{}{}
+	}
+
+	return len(seen)
+}
+
+// Snapshot records what was last written.
+type Snapshot struct {
+	Prefixes int       `json:"prefixes"`
+	Updated  time.Time `json:"updated"`
+}
+
+// State keeps a snapshot of each list written in a directory.
+type State struct {
+	Dir string
+}
+
+func (s State) path(name string) string {
+	return filepath.Join(s.Dir, name+".json")
+}
+
+// Load returns the snapshot of the list with the given name, if one has been saved.
+func (s State) Load(name string) (Snapshot, bool, error) {
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{}, false, nil
+	}
+
+	if err != nil {
+		return Snapshot{}, false, err
+	}
+
+	var snap Snapshot
+	if err = json.Unmarshal(data, &snap); err != nil {
+		return Snapshot{}, false, fmt.Errorf("invalid state for %s: %w", name, err)
+	}
+
+	return snap, true, nil
+}
+
+// Save saves the snapshot of the list with the given name.
+func (s State) Save(name string, snap Snapshot) error {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path(name), data, 0o600)
+}
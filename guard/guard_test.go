@@ -0,0 +1,75 @@
+package guard_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jonhadfield/ip-fetcher/guard"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThresholdsCheck(t *testing.T) {
+	tests := []struct {
+		name              string
+		thresholds        guard.Thresholds
+		previous, current int
+		shrink            bool
+	}{
+		{"growth", guard.Thresholds{MaxDropPercent: 10}, 100, 150, false},
+		{"within percent", guard.Thresholds{MaxDropPercent: 10}, 100, 90, false},
+		{"beyond percent", guard.Thresholds{MaxDropPercent: 10}, 100, 89, true},
+		{"empty", guard.Thresholds{MaxDropPercent: 50}, 100, 0, true},
+		{"within count", guard.Thresholds{MaxDrop: 5}, 100, 95, false},
+		{"beyond count", guard.Thresholds{MaxDrop: 5}, 100, 94, true},
+		{"either", guard.Thresholds{MaxDropPercent: 50, MaxDrop: 5}, 100, 90, true},
+		{"disabled", guard.Thresholds{}, 100, 0, false},
+		{"no previous", guard.Thresholds{MaxDropPercent: 1}, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.thresholds.Check("aws", tt.previous, tt.current)
+			if !tt.shrink {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, guard.ErrShrink)
+
+			var se *guard.ShrinkError
+			require.ErrorAs(t, err, &se)
+			require.Equal(t, tt.previous, se.Previous)
+			require.Equal(t, tt.current, se.Current)
+			require.Contains(t, err.Error(), "aws: prefix count dropped")
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	require.Equal(t, 2, guard.Count([]byte("# list\n192.0.2.0/24\n198.51.100.0/24\n192.0.2.0/24\n")))
+	require.Equal(t, 3, guard.Count([]byte(`{"prefixes":[{"ip_prefix":"192.0.2.0/24","region":"eu"}],`+
+		`"ipv6_prefixes":[{"ipv6_prefix":"2001:db8::/32"}],"hosts":["198.51.100.1"]}`)))
+	require.Zero(t, guard.Count(nil))
+
+	err := guard.Thresholds{MaxDropPercent: 50}.CheckData("x",
+		[]byte("192.0.2.0/24\n198.51.100.0/24\n203.0.113.0/24\n"), []byte("192.0.2.0/24\n"))
+	require.ErrorIs(t, err, guard.ErrShrink)
+}
+
+func TestState(t *testing.T) {
+	s := guard.State{Dir: filepath.Join(t.TempDir(), "state")}
+
+	_, ok, err := s.Load("aws")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, s.Save("aws", guard.Snapshot{Prefixes: 10, Updated: now}))
+
+	snap, ok, err := s.Load("aws")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, guard.Snapshot{Prefixes: 10, Updated: now}, snap)
+}
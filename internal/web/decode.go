@@ -0,0 +1,267 @@
+package web
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// AcceptEncoding lists the content encodings NewResponseReader can decode.
+const AcceptEncoding = "gzip, deflate, br, zstd"
+
+// maxArchiveSize caps how much of a zip payload is buffered, as zip archives cannot be read as a stream.
+const maxArchiveSize = 512 * 1024 * 1024
+
+var (
+	// compressedTypes and compressedExts are the Content-Types and url file extensions of
+	// payloads that are decompressed.
+	compressedTypes = []string{ //nolint:gochecknoglobals
+		"application/gzip", "application/x-gzip", "application/x-bzip2", "application/zstd",
+		"application/zip", "application/x-zip-compressed",
+	}
+	compressedExts = []string{".gz", ".bz2", ".zst", ".zip"} //nolint:gochecknoglobals
+
+	magicGzip  = []byte{0x1f, 0x8b}             //nolint:gochecknoglobals
+	magicBzip2 = []byte("BZh")                  //nolint:gochecknoglobals
+	magicZstd  = []byte{0x28, 0xb5, 0x2f, 0xfd} //nolint:gochecknoglobals
+	magicZip   = []byte{'P', 'K', 0x03, 0x04}   //nolint:gochecknoglobals
+)
+
+// NewResponseReader returns a reader that decodes the response body according to its Content-Encoding
+// and then, if its Content-Type or the file extension of the url requested says the payload is
+// compressed, decompresses a gzip, bzip2, zstd or single file zip archive. Closing the returned
+// reader does not close the response body.
+func NewResponseReader(resp *http.Response) (io.ReadCloser, error) {
+	// responses to HEAD requests may declare an encoding without carrying a body
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return http.NoBody, nil
+	}
+
+	decoded, err := decodeContent(resp.Header.Get("Content-Encoding"), resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if !compressedPayload(resp) {
+		return decoded, nil
+	}
+
+	payload, err := decompressPayload(decoded)
+	if err != nil {
+		_ = decoded.Close()
+
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// compressedPayload reports whether the response's Content-Type or the file extension of the url
+// requested says its payload is compressed.
+func compressedPayload(resp *http.Response) bool {
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil &&
+		slices.Contains(compressedTypes, mediaType) {
+		return true
+	}
+
+	if resp.Request == nil || resp.Request.URL == nil {
+		return false
+	}
+
+	return slices.Contains(compressedExts, strings.ToLower(path.Ext(resp.Request.URL.Path)))
+}
+
+// decodeContent reverses each Content-Encoding in turn. Encodings are listed in the order they were applied.
+func decodeContent(header string, body io.Reader) (io.ReadCloser, error) {
+	rc := &readCloser{Reader: body}
+
+	if strings.TrimSpace(header) == "" {
+		return rc, nil
+	}
+
+	encodings := strings.Split(header, ",")
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+
+		var (
+			r       io.Reader
+			closeFn func() error
+			err     error
+		)
+
+		switch encoding {
+		case "", "identity":
+			continue
+		case "gzip", "x-gzip":
+			var gr *gzip.Reader
+			gr, err = gzip.NewReader(rc.Reader)
+			if err == nil {
+				r, closeFn = gr, gr.Close
+			}
+		case "deflate":
+			r, closeFn, err = newDeflateReader(rc.Reader)
+		case "br":
+			r = brotli.NewReader(rc.Reader)
+		case "zstd":
+			var zr *zstd.Decoder
+			zr, err = zstd.NewReader(rc.Reader)
+			if err == nil {
+				r, closeFn = zr, closeZstd(zr)
+			}
+		default:
+			err = fmt.Errorf("unsupported content encoding: %s", encoding)
+		}
+
+		if err != nil {
+			_ = rc.Close()
+
+			return nil, err
+		}
+
+		rc.Reader = r
+		if closeFn != nil {
+			rc.closers = append([]func() error{closeFn}, rc.closers...)
+		}
+	}
+
+	return rc, nil
+}
+
+// newDeflateReader handles both zlib wrapped (RFC 1950) and raw (RFC 1951) deflate streams,
+// as servers are inconsistent about which they send for the deflate encoding.
+func newDeflateReader(r io.Reader) (io.Reader, func() error, error) {
+	br := bufio.NewReader(r)
+
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// a zlib header has a deflate compression method and a checksum that is a multiple of 31
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		zr, zErr := zlib.NewReader(br)
+		if zErr != nil {
+			return nil, nil, zErr
+		}
+
+		return zr, zr.Close, nil
+	}
+
+	fr := flate.NewReader(br)
+
+	return fr, fr.Close, nil
+}
+
+func closeZstd(d *zstd.Decoder) func() error {
+	return func() error {
+		d.Close()
+
+		return nil
+	}
+}
+
+// decompressPayload inspects the leading bytes of the payload and transparently decompresses
+// files that were published compressed, e.g. a .gz, .bz2, .zst or .zip download.
+func decompressPayload(rc io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(rc)
+
+	// a short payload cannot carry a magic number, so errors here are not fatal
+	magic, _ := br.Peek(len(magicZstd))
+
+	out := &readCloser{Reader: br, closers: []func() error{rc.Close}}
+
+	switch {
+	case bytes.HasPrefix(magic, magicGzip):
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+
+		out.Reader = gr
+		out.closers = append([]func() error{gr.Close}, out.closers...)
+	case bytes.HasPrefix(magic, magicBzip2):
+		out.Reader = bzip2.NewReader(br)
+	case bytes.HasPrefix(magic, magicZstd):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+
+		out.Reader = zr
+		out.closers = append([]func() error{closeZstd(zr)}, out.closers...)
+	case bytes.HasPrefix(magic, magicZip):
+		r, err := openSingleFileZip(br)
+		if err != nil {
+			return nil, err
+		}
+
+		out.Reader = r
+		out.closers = append([]func() error{r.Close}, out.closers...)
+	}
+
+	return out, nil
+}
+
+// openSingleFileZip buffers a zip archive and returns a reader for the only file it contains.
+func openSingleFileZip(r io.Reader) (io.ReadCloser, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxArchiveSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxArchiveSize {
+		return nil, fmt.Errorf("zip archive exceeds %d bytes", maxArchiveSize)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*zip.File
+
+	for _, f := range zr.File {
+		if !f.FileInfo().IsDir() {
+			files = append(files, f)
+		}
+	}
+
+	if len(files) != 1 {
+		return nil, fmt.Errorf("zip archive contains %d files but exactly one is required", len(files))
+	}
+
+	return files[0].Open()
+}
+
+// readCloser combines a reader with the closers of every layer beneath it, closed in order.
+type readCloser struct {
+	io.Reader
+	closers []func() error
+}
+
+func (r *readCloser) Close() error {
+	var errs []error
+
+	for _, c := range r.closers {
+		if err := c(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,174 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors describing the category of a fetch failure. Errors returned
+// by this package and the providers match one of these with errors.Is so that
+// callers can decide whether to retry, alert or ignore.
+var (
+	// ErrNetwork indicates the request could not be completed, e.g. DNS, TLS or timeout failures.
+	ErrNetwork = errors.New("network failure")
+	// ErrUnexpectedStatus indicates the server responded with a non-2xx status code.
+	ErrUnexpectedStatus = errors.New("unexpected http status")
+	// ErrRateLimited indicates the upstream is throttling requests.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrAuth indicates the upstream rejected the supplied credentials.
+	ErrAuth = errors.New("authentication failed")
+	// ErrParse indicates the response was retrieved but could not be parsed.
+	ErrParse = errors.New("failed to parse response")
+	// ErrEmptyResult indicates the upstream returned no usable data.
+	ErrEmptyResult = errors.New("empty result")
+)
+
+// StatusError is returned when a response has a status code outside the 2xx range.
+// It matches ErrUnexpectedStatus and, depending on the code, ErrRateLimited or ErrAuth.
+type StatusError struct {
+	Provider   string
+	URL        string
+	StatusCode int
+	Detail     string
+}
+
+// NewStatusError returns a StatusError for the given provider, url and status code.
+func NewStatusError(provider, url string, statusCode int) *StatusError {
+	return &StatusError{
+		Provider:   provider,
+		URL:        url,
+		StatusCode: statusCode,
+	}
+}
+
+func (e *StatusError) Error() string {
+	var sb strings.Builder
+
+	sb.WriteString("failed to download ")
+
+	if e.Provider != "" {
+		sb.WriteString(e.Provider + " ")
+	}
+
+	sb.WriteString("prefixes")
+
+	if e.URL != "" {
+		sb.WriteString(" from " + e.URL)
+	}
+
+	fmt.Fprintf(&sb, ". http status code: %d", e.StatusCode)
+
+	if e.Detail != "" {
+		sb.WriteString(" - " + e.Detail)
+	}
+
+	return sb.String()
+}
+
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrUnexpectedStatus:
+		return true
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrAuth:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	default:
+		return false
+	}
+}
+
+// ParseError is returned when a provider document cannot be parsed. It matches ErrParse.
+type ParseError struct {
+	Provider string
+	Err      error
+}
+
+// NewParseError wraps err in a ParseError for the given provider.
+// A nil err results in a nil error.
+func NewParseError(provider string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		return err
+	}
+
+	return &ParseError{
+		Provider: provider,
+		Err:      err,
+	}
+}
+
+func (e *ParseError) Error() string {
+	if e.Provider == "" {
+		return fmt.Sprintf("failed to parse data: %s", e.Err)
+	}
+
+	return fmt.Sprintf("failed to parse %s data: %s", e.Provider, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ParseError) Is(target error) bool {
+	return target == ErrParse
+}
+
+// IsSuccessStatus reports whether the status code is in the 2xx range.
+func IsSuccessStatus(statusCode int) bool {
+	return statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
+}
+
+// classifyRequestError wraps transport errors with ErrNetwork unless they already
+// carry a more specific category, e.g. a rate limit surfaced by a retry policy.
+func classifyRequestError(err error) error {
+	if categorized(err) {
+		return err
+	}
+
+	return fmt.Errorf("%w: %w", ErrNetwork, err)
+}
+
+// categorized reports whether err matches one of the package's sentinel errors.
+func categorized(err error) bool {
+	for _, known := range []error{ErrNetwork, ErrUnexpectedStatus, ErrRateLimited, ErrAuth, ErrParse, ErrEmptyResult} {
+		if errors.Is(err, known) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keepLastResponse is a retryablehttp.ErrorHandler that, once retries are exhausted, returns the
+// final response rather than retryablehttp's "giving up" error, so that callers report its status
+// with a StatusError. An error from the retry policy is returned instead, wrapped in a StatusError
+// for the final status unless it is already categorized or the status was successful.
+func keepLastResponse(resp *http.Response, err error, _ int) (*http.Response, error) {
+	if err == nil {
+		return resp, nil
+	}
+
+	if resp == nil {
+		return nil, err
+	}
+
+	_ = resp.Body.Close()
+
+	if categorized(err) || IsSuccessStatus(resp.StatusCode) {
+		return nil, err
+	}
+
+	var u string
+	if resp.Request != nil {
+		u = resp.Request.URL.Redacted()
+	}
+
+	return nil, fmt.Errorf("%w: %w", NewStatusError("", u, resp.StatusCode), err)
+}
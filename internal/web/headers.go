@@ -0,0 +1,142 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultUserAgent identifies ip-fetcher to upstreams when no version is known.
+	DefaultUserAgent = "ip-fetcher"
+	projectURL       = "https://github.com/jonhadfield/ip-fetcher"
+)
+
+// headerConfig holds the headers added to every outgoing request. Headers are applied in
+// order of increasing precedence: user agent, global headers, provider headers and finally
+// any headers set on the request itself.
+type headerConfig struct {
+	mu        sync.RWMutex
+	userAgent string
+	global    http.Header
+	providers map[string]http.Header
+}
+
+var requestHeaders = &headerConfig{userAgent: DefaultUserAgent} //nolint:gochecknoglobals
+
+// UserAgentForVersion returns a User-Agent identifying the given ip-fetcher version.
+func UserAgentForVersion(version string) string {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return DefaultUserAgent + " (+" + projectURL + ")"
+	}
+
+	return DefaultUserAgent + "/" + version + " (+" + projectURL + ")"
+}
+
+// SetUserAgent sets the User-Agent sent with every request. An empty value restores the default.
+func SetUserAgent(ua string) {
+	ua = strings.TrimSpace(ua)
+	if ua == "" {
+		ua = DefaultUserAgent
+	}
+
+	requestHeaders.mu.Lock()
+	defer requestHeaders.mu.Unlock()
+
+	requestHeaders.userAgent = ua
+}
+
+// UserAgent returns the User-Agent sent with every request.
+func UserAgent() string {
+	requestHeaders.mu.RLock()
+	defer requestHeaders.mu.RUnlock()
+
+	return requestHeaders.userAgent
+}
+
+// SetHeaders sets headers sent with every request, replacing any previously set.
+func SetHeaders(h http.Header) {
+	requestHeaders.mu.Lock()
+	defer requestHeaders.mu.Unlock()
+
+	requestHeaders.global = h.Clone()
+}
+
+// SetProviderHeaders sets headers sent with every request made by clients created
+// with NewProviderHTTPClient for the named provider, replacing any previously set.
+func SetProviderHeaders(provider string, h http.Header) {
+	requestHeaders.mu.Lock()
+	defer requestHeaders.mu.Unlock()
+
+	if requestHeaders.providers == nil {
+		requestHeaders.providers = make(map[string]http.Header)
+	}
+
+	if len(h) == 0 {
+		delete(requestHeaders.providers, provider)
+
+		return
+	}
+
+	requestHeaders.providers[provider] = h.Clone()
+}
+
+// ResetHeaders restores the default User-Agent and removes all global and provider headers.
+func ResetHeaders() {
+	requestHeaders.mu.Lock()
+	defer requestHeaders.mu.Unlock()
+
+	requestHeaders.userAgent = DefaultUserAgent
+	requestHeaders.global = nil
+	requestHeaders.providers = nil
+}
+
+// apply adds the configured headers to h without replacing any already present.
+func (hc *headerConfig) apply(provider string, h http.Header) {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+
+	setMissing(h, hc.providers[provider])
+	setMissing(h, hc.global)
+
+	if h.Get("User-Agent") == "" {
+		h.Set("User-Agent", hc.userAgent)
+	}
+}
+
+// setMissing copies the values of each header in src that has no value in dst.
+// Headers are applied from highest to lowest precedence, so existing values win.
+func setMissing(dst, src http.Header) {
+	for k, v := range src {
+		k = http.CanonicalHeaderKey(k)
+		if _, ok := dst[k]; ok {
+			continue
+		}
+
+		dst[k] = append([]string(nil), v...)
+	}
+}
+
+// headerTransport adds the configured User-Agent and extra headers to each request.
+type headerTransport struct {
+	provider string
+	next     http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// a RoundTripper must not modify the caller's request
+	r := req.Clone(req.Context())
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+
+	requestHeaders.apply(t.provider, r.Header)
+
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return next.RoundTrip(r)
+}
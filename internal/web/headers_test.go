@@ -0,0 +1,80 @@
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jonhadfield/ip-fetcher/internal/web"
+
+	"github.com/stretchr/testify/require"
+)
+
+func headerEchoServer(t *testing.T) (*httptest.Server, *http.Header) {
+	t.Helper()
+
+	var received http.Header
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts, &received
+}
+
+func TestRequestDefaultUserAgent(t *testing.T) {
+	web.ResetHeaders()
+
+	ts, received := headerEchoServer(t)
+
+	_, _, _, err := web.Request(web.NewHTTPClient(), ts.URL, http.MethodGet, nil, nil, web.DefaultRequestTimeout)
+	require.NoError(t, err)
+	require.Equal(t, web.DefaultUserAgent, received.Get("User-Agent"))
+}
+
+func TestRequestHeaderPrecedence(t *testing.T) {
+	t.Cleanup(web.ResetHeaders)
+
+	web.SetUserAgent(web.UserAgentForVersion("1.2.3"))
+	web.SetHeaders(http.Header{"X-Global": {"global"}, "X-Shared": {"global"}})
+	web.SetProviderHeaders("aws", http.Header{"X-Provider": {"aws"}, "X-Shared": {"aws"}, "X-Request": {"aws"}})
+	web.SetProviderHeaders("gcp", http.Header{"X-Provider": {"gcp"}})
+
+	ts, received := headerEchoServer(t)
+
+	_, _, _, err := web.Request(
+		web.NewProviderHTTPClient("aws"),
+		ts.URL,
+		http.MethodGet,
+		http.Header{"X-Request": {"request"}},
+		nil,
+		web.DefaultRequestTimeout,
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, "ip-fetcher/1.2.3 (+https://github.com/jonhadfield/ip-fetcher)", received.Get("User-Agent"))
+	require.Equal(t, "global", received.Get("X-Global"))
+	require.Equal(t, "aws", received.Get("X-Provider"))
+	require.Equal(t, "aws", received.Get("X-Shared"))
+	require.Equal(t, "request", received.Get("X-Request"))
+
+	// headers set for a provider are not sent by clients for other providers
+	_, _, _, err = web.Request(web.NewHTTPClient(), ts.URL, http.MethodGet, nil, nil, web.DefaultRequestTimeout)
+	require.NoError(t, err)
+	require.Empty(t, received.Get("X-Provider"))
+	require.Equal(t, "global", received.Get("X-Shared"))
+
+	web.SetUserAgent("custom-agent")
+
+	_, _, _, err = web.Request(web.NewProviderHTTPClient("gcp"), ts.URL, http.MethodGet, nil, nil, web.DefaultRequestTimeout)
+	require.NoError(t, err)
+	require.Equal(t, "custom-agent", received.Get("User-Agent"))
+	require.Equal(t, "gcp", received.Get("X-Provider"))
+}
+
+func TestUserAgentForVersion(t *testing.T) {
+	require.Equal(t, "ip-fetcher (+https://github.com/jonhadfield/ip-fetcher)", web.UserAgentForVersion(""))
+	require.Equal(t, "ip-fetcher/v1.0.0 (+https://github.com/jonhadfield/ip-fetcher)", web.UserAgentForVersion("v1.0.0"))
+}
@@ -0,0 +1,134 @@
+package web
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// Secrets is a concurrency safe set of values, such as tokens and passwords,
+// that must be masked wherever they would otherwise appear in logs or errors.
+type Secrets struct {
+	mu     sync.RWMutex
+	values []string
+}
+
+// Add records values to be masked. Empty values are ignored.
+func (s *Secrets) Add(values ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, v := range values {
+		if v != "" {
+			s.values = append(s.values, v)
+		}
+	}
+}
+
+// Values returns a copy of the recorded secrets.
+func (s *Secrets) Values() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string(nil), s.values...)
+}
+
+// Mask replaces each recorded secret in content with asterisks.
+func (s *Secrets) Mask(content string) string {
+	return MaskSecrets(content, s.Values())
+}
+
+// URLSecrets returns the password embedded in a url, if any, so that it can be masked.
+func URLSecrets(u *url.URL) []string {
+	if u == nil || u.User == nil {
+		return nil
+	}
+
+	if p, ok := u.User.Password(); ok && p != "" {
+		return []string{p}
+	}
+
+	return nil
+}
+
+// maskedError masks secrets in the message of the wrapped error without hiding its category.
+type maskedError struct {
+	err     error
+	secrets []string
+}
+
+func (e *maskedError) Error() string {
+	return MaskSecrets(e.err.Error(), e.secrets)
+}
+
+func (e *maskedError) Unwrap() error {
+	return e.err
+}
+
+// MaskError returns an error whose message has the secrets masked. The original
+// error remains available to errors.Is and errors.As.
+func MaskError(err error, secrets []string) error {
+	if err == nil || len(secrets) == 0 {
+		return err
+	}
+
+	return &maskedError{err: err, secrets: secrets}
+}
+
+// MaskLogger wraps a retryablehttp logger so that secrets are masked in every message.
+// Loggers of an unknown type, including nil, are returned unchanged.
+func MaskLogger(logger any, s *Secrets) any {
+	switch l := logger.(type) {
+	case retryablehttp.LeveledLogger:
+		return &maskedLeveledLogger{next: l, secrets: s}
+	case retryablehttp.Logger:
+		return &maskedLogger{next: l, secrets: s}
+	default:
+		return logger
+	}
+}
+
+type maskedLogger struct {
+	next    retryablehttp.Logger
+	secrets *Secrets
+}
+
+func (l *maskedLogger) Printf(format string, args ...any) {
+	l.next.Printf("%s", l.secrets.Mask(fmt.Sprintf(format, args...)))
+}
+
+type maskedLeveledLogger struct {
+	next    retryablehttp.LeveledLogger
+	secrets *Secrets
+}
+
+func (l *maskedLeveledLogger) mask(msg string, keysAndValues []any) (string, []any) {
+	masked := make([]any, len(keysAndValues))
+	for i, v := range keysAndValues {
+		masked[i] = l.secrets.Mask(fmt.Sprint(v))
+	}
+
+	return l.secrets.Mask(msg), masked
+}
+
+func (l *maskedLeveledLogger) Error(msg string, keysAndValues ...any) {
+	msg, keysAndValues = l.mask(msg, keysAndValues)
+	l.next.Error(msg, keysAndValues...)
+}
+
+func (l *maskedLeveledLogger) Info(msg string, keysAndValues ...any) {
+	msg, keysAndValues = l.mask(msg, keysAndValues)
+	l.next.Info(msg, keysAndValues...)
+}
+
+func (l *maskedLeveledLogger) Debug(msg string, keysAndValues ...any) {
+	msg, keysAndValues = l.mask(msg, keysAndValues)
+	l.next.Debug(msg, keysAndValues...)
+}
+
+func (l *maskedLeveledLogger) Warn(msg string, keysAndValues ...any) {
+	msg, keysAndValues = l.mask(msg, keysAndValues)
+	l.next.Warn(msg, keysAndValues...)
+}
@@ -2,7 +2,6 @@ package web
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
@@ -49,16 +48,24 @@ const (
 )
 
 func NewHTTPClient() *retryablehttp.Client {
-	rc := &http.Client{Transport: &http.Transport{
-		MaxIdleConns:        defaultMaxIdleConns,
-		MaxIdleConnsPerHost: defaultMaxConnsPerHost,
-		IdleConnTimeout:     defaultIdleConnTimeout,
+	return newHTTPClient("")
+}
+
+func newHTTPClient(provider string) *retryablehttp.Client {
+	rc := &http.Client{Transport: &headerTransport{
+		provider: provider,
+		next: &http.Transport{
+			MaxIdleConns:        defaultMaxIdleConns,
+			MaxIdleConnsPerHost: defaultMaxConnsPerHost,
+			IdleConnTimeout:     defaultIdleConnTimeout,
+		},
 	}}
 	c := retryablehttp.NewClient()
 	c.HTTPClient = rc
 	c.RetryMax = defaultRetryMax
 	c.RetryWaitMin = defaultRetryWaitMin
 	c.RetryWaitMax = defaultRetryWaitMax
+	c.ErrorHandler = keepLastResponse
 
 	return c
 }
@@ -67,9 +74,15 @@ func NewHTTPClient() *retryablehttp.Client {
 // It sets the log level from environment and suppresses retryablehttp's logger
 // unless debug logging is enabled.
 func NewHTTPClientWithLogger() *retryablehttp.Client {
+	return NewProviderHTTPClient("")
+}
+
+// NewProviderHTTPClient creates an HTTP client with standard logging configuration
+// that adds the headers set with SetProviderHeaders for the named provider to each request.
+func NewProviderHTTPClient(provider string) *retryablehttp.Client {
 	pflog.SetLogLevel()
 
-	c := NewHTTPClient()
+	c := newHTTPClient(provider)
 
 	if logrus.GetLevel() < logrus.DebugLevel {
 		c.Logger = nil
@@ -80,6 +93,10 @@ func NewHTTPClientWithLogger() *retryablehttp.Client {
 
 func MaskSecrets(content string, secret []string) string {
 	for _, s := range secret {
+		if s == "" {
+			continue
+		}
+
 		content = strings.ReplaceAll(content, s, strings.Repeat("*", len(s)))
 	}
 
@@ -93,44 +110,112 @@ func Request(
 	secrets []string,
 	timeout time.Duration,
 ) ([]byte, http.Header, int, error) {
+	resp, cancel, err := doRequest(c, url, method, inHeaders, secrets, timeout)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer cancel()
+	defer resp.Body.Close()
+
+	headers := resp.Header
+
+	body, err := GetResponseBody(resp)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("%w", err)
+	}
+
+	return body, headers, resp.StatusCode, nil
+}
+
+// RequestStream makes an HTTP request and returns the decoded response body so that it can be
+// consumed incrementally rather than buffered in memory. The caller must close the returned body,
+// which also releases the request's timeout context.
+func RequestStream(
+	c *retryablehttp.Client,
+	url, method string,
+	inHeaders http.Header,
+	secrets []string,
+	timeout time.Duration,
+) (io.ReadCloser, http.Header, int, error) {
+	resp, cancel, err := doRequest(c, url, method, inHeaders, secrets, timeout)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	reader, err := NewResponseReader(resp)
+	if err != nil {
+		_ = resp.Body.Close()
+		cancel()
+
+		return nil, nil, 0, err
+	}
+
+	return &readCloser{
+		Reader: reader,
+		closers: []func() error{
+			reader.Close,
+			resp.Body.Close,
+			func() error {
+				cancel()
+
+				return nil
+			},
+		},
+	}, resp.Header, resp.StatusCode, nil
+}
+
+func doRequest(
+	c *retryablehttp.Client,
+	url, method string,
+	inHeaders http.Header,
+	secrets []string,
+	timeout time.Duration,
+) (*http.Response, context.CancelFunc, error) {
 	if c == nil {
-		return nil, nil, 0, errors.New("HTTP client is nil")
+		return nil, nil, errors.New("HTTP client is nil")
 	}
 
 	if method == "" {
-		return nil, nil, 0, errors.New("HTTP method not specified")
+		return nil, nil, errors.New("HTTP method not specified")
 	}
 
 	request, err := retryablehttp.NewRequest(method, url, nil)
 	if err != nil {
-		return nil, nil, 0, fmt.Errorf("failed to request %s: %w", MaskSecrets(url, secrets), err)
+		return nil, nil, fmt.Errorf("failed to request %s: %w", MaskSecrets(url, secrets), err)
+	}
+
+	request.Header = inHeaders.Clone()
+	if request.Header == nil {
+		request.Header = http.Header{}
+	}
+
+	// advertise every encoding NewResponseReader can decode. Setting this disables
+	// the transport's own gzip handling, so decoding is always done here.
+	if method != http.MethodHead && request.Header.Get("Accept-Encoding") == "" {
+		request.Header.Set("Accept-Encoding", AcceptEncoding)
 	}
 
-	request.Header = inHeaders
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
 
-	ctx := context.Background()
-	var cancel context.CancelFunc
 	if timeout != 0 {
 		ctx, cancel = context.WithTimeout(context.Background(), timeout)
-		defer cancel()
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
 	}
 
 	request = request.WithContext(ctx)
 
 	resp, err := c.Do(request)
 	if err != nil {
-		return nil, nil, 0, err
-	}
-	defer resp.Body.Close()
+		cancel()
 
-	headers := resp.Header
-
-	body, err := GetResponseBody(resp)
-	if err != nil {
-		return nil, nil, 0, fmt.Errorf("%w", err)
+		return nil, nil, classifyRequestError(MaskError(err, secrets))
 	}
 
-	return body, headers, resp.StatusCode, nil
+	return resp, cancel, nil
 }
 
 // GetResourceHeaderValue will make an HTTP request and return the value of the specified header.
@@ -222,12 +307,12 @@ func DownloadFile(client *retryablehttp.Client, u, path string) (string, error)
 
 	resp, err := client.Get(u)
 	if err != nil {
-		return "", err
+		return "", classifyRequestError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode > http.StatusMultipleChoices {
-		return "", fmt.Errorf("server responded with status %d", resp.StatusCode)
+		return "", NewStatusError("", u, resp.StatusCode)
 	}
 
 	logrus.Infof("writing to path: %s", path)
@@ -269,18 +354,11 @@ func RequestContentDispositionFileName(httpClient *retryablehttp.Client, url str
 }
 
 func GetResponseBody(resp *http.Response) ([]byte, error) {
-	var output io.ReadCloser
-
-	var err error
-	switch resp.Header.Get("Content-Encoding") {
-	case "gzip":
-		output, err = gzip.NewReader(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-	default:
-		output = resp.Body
+	output, err := NewResponseReader(resp)
+	if err != nil {
+		return nil, err
 	}
+	defer output.Close()
 
 	buf := new(bytes.Buffer)
 
@@ -0,0 +1,131 @@
+package web_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/jonhadfield/ip-fetcher/internal/web"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusErrorCategories(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", web.NewStatusError("aws", "https://example.com/ips.json", http.StatusTooManyRequests))
+	require.ErrorIs(t, err, web.ErrUnexpectedStatus)
+	require.ErrorIs(t, err, web.ErrRateLimited)
+	require.NotErrorIs(t, err, web.ErrAuth)
+	require.ErrorContains(t, err, "failed to download aws prefixes from https://example.com/ips.json. http status code: 429")
+
+	var statusErr *web.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	require.Equal(t, http.StatusTooManyRequests, statusErr.StatusCode)
+
+	err = web.NewStatusError("", "", http.StatusUnauthorized)
+	require.ErrorIs(t, err, web.ErrAuth)
+	require.NotErrorIs(t, err, web.ErrRateLimited)
+	require.EqualError(t, err, "failed to download prefixes. http status code: 401")
+
+	require.NotErrorIs(t, web.NewStatusError("", "", http.StatusNotFound), web.ErrAuth)
+}
+
+func TestParseError(t *testing.T) {
+	require.NoError(t, web.NewParseError("aws", nil))
+
+	cause := errors.New("unexpected end of JSON input")
+	err := web.NewParseError("aws", cause)
+	require.ErrorIs(t, err, web.ErrParse)
+	require.ErrorIs(t, err, cause)
+	require.EqualError(t, err, "failed to parse aws data: unexpected end of JSON input")
+
+	// wrapping an existing parse error keeps the original provider
+	require.Equal(t, err, web.NewParseError("other", err))
+}
+
+func TestPersistentErrorStatus(t *testing.T) {
+	for _, tc := range []struct {
+		status   int
+		category error
+	}{
+		{status: http.StatusTooManyRequests, category: web.ErrRateLimited},
+		{status: http.StatusServiceUnavailable, category: web.ErrUnexpectedStatus},
+	} {
+		t.Run(http.StatusText(tc.status), func(t *testing.T) {
+			var attempts int
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				attempts++
+
+				w.WriteHeader(tc.status)
+			}))
+			defer ts.Close()
+
+			c := web.NewHTTPClient()
+			c.RetryMax = 1
+			c.RetryWaitMin = time.Millisecond
+			c.RetryWaitMax = time.Millisecond
+
+			// the final response is returned once retries are exhausted
+			_, _, status, err := web.Request(c, ts.URL, http.MethodGet, nil, nil, web.DefaultRequestTimeout)
+			require.NoError(t, err)
+			require.Equal(t, tc.status, status)
+			require.Equal(t, 2, attempts)
+
+			_, err = web.DownloadFile(c, ts.URL+"/ips.txt", t.TempDir())
+			require.ErrorIs(t, err, tc.category)
+			require.NotErrorIs(t, err, web.ErrNetwork)
+
+			var statusErr *web.StatusError
+			require.ErrorAs(t, err, &statusErr)
+			require.Equal(t, tc.status, statusErr.StatusCode)
+		})
+	}
+}
+
+func TestRetryPolicyError(t *testing.T) {
+	status := http.StatusServiceUnavailable
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	policyErr := errors.New("upstream unavailable")
+
+	c := web.NewHTTPClient()
+	c.RetryMax = 1
+	c.RetryWaitMin = time.Millisecond
+	c.RetryWaitMax = time.Millisecond
+	c.CheckRetry = func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		if err != nil {
+			return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+		}
+
+		if resp.StatusCode == http.StatusNoContent {
+			return false, policyErr
+		}
+
+		return true, policyErr
+	}
+
+	// the policy's error is returned with the final status
+	_, _, _, err := web.Request(c, ts.URL, http.MethodGet, nil, nil, web.DefaultRequestTimeout)
+	require.ErrorIs(t, err, policyErr)
+	require.ErrorIs(t, err, web.ErrUnexpectedStatus)
+
+	var statusErr *web.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	require.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+
+	// or as a network failure if the status was successful
+	status = http.StatusNoContent
+
+	_, _, _, err = web.Request(c, ts.URL, http.MethodGet, nil, nil, web.DefaultRequestTimeout)
+	require.ErrorIs(t, err, policyErr)
+	require.ErrorIs(t, err, web.ErrNetwork)
+	require.NotErrorIs(t, err, web.ErrUnexpectedStatus)
+}
@@ -1,13 +1,19 @@
 package web_test
 
 import (
+	"archive/zip"
 	"bytes"
+	"compress/flate"
 	"compress/gzip"
+	"compress/zlib"
 	"io"
 	"net/http"
+	"os"
 	"testing"
 
+	"github.com/andybalholm/brotli"
 	"github.com/jonhadfield/ip-fetcher/internal/web"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/require"
 )
 
@@ -34,3 +40,190 @@ func TestGetResponseBodyGzip(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []byte("hello"), data)
 }
+
+func responseWithEncoding(body []byte, encoding string) *http.Response {
+	header := http.Header{}
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+	}
+
+	return &http.Response{Body: io.NopCloser(bytes.NewReader(body)), Header: header}
+}
+
+func TestGetResponseBodyContentEncodings(t *testing.T) {
+	var brBuf bytes.Buffer
+	bw := brotli.NewWriter(&brBuf)
+	_, err := bw.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, bw.Close())
+
+	zw, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	zstdData := zw.EncodeAll([]byte("hello"), nil)
+	require.NoError(t, zw.Close())
+
+	var zlibBuf bytes.Buffer
+	zlw := zlib.NewWriter(&zlibBuf)
+	_, err = zlw.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, zlw.Close())
+
+	var flateBuf bytes.Buffer
+	fw, err := flate.NewWriter(&flateBuf, flate.DefaultCompression)
+	require.NoError(t, err)
+	_, err = fw.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, fw.Close())
+
+	for _, tc := range []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{"brotli", "br", brBuf.Bytes()},
+		{"zstd", "zstd", zstdData},
+		{"zlib deflate", "deflate", zlibBuf.Bytes()},
+		{"raw deflate", "deflate", flateBuf.Bytes()},
+		{"identity", "identity", []byte("hello")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, bErr := web.GetResponseBody(responseWithEncoding(tc.body, tc.encoding))
+			require.NoError(t, bErr)
+			require.Equal(t, []byte("hello"), data)
+		})
+	}
+
+	_, err = web.GetResponseBody(responseWithEncoding([]byte("hello"), "compress"))
+	require.ErrorContains(t, err, "unsupported content encoding")
+}
+
+func TestGetResponseBodyStackedEncodings(t *testing.T) {
+	// gzip applied first, then brotli
+	var gzBuf bytes.Buffer
+	gz := gzip.NewWriter(&gzBuf)
+	_, err := gz.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	var brBuf bytes.Buffer
+	bw := brotli.NewWriter(&brBuf)
+	_, err = bw.Write(gzBuf.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, bw.Close())
+
+	data, err := web.GetResponseBody(responseWithEncoding(brBuf.Bytes(), "gzip, br"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), data)
+}
+
+func TestGetResponseBodyCompressedPayloads(t *testing.T) {
+	var gzBuf bytes.Buffer
+	gz := gzip.NewWriter(&gzBuf)
+	_, err := gz.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	bz2, err := os.ReadFile("testdata/hello.txt.bz2")
+	require.NoError(t, err)
+
+	zw, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	zstdData := zw.EncodeAll([]byte("hello"), nil)
+	require.NoError(t, zw.Close())
+
+	var zipBuf bytes.Buffer
+	zipW := zip.NewWriter(&zipBuf)
+	f, err := zipW.Create("hello.txt")
+	require.NoError(t, err)
+	_, err = f.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, zipW.Close())
+
+	for _, tc := range []struct {
+		name        string
+		body        []byte
+		contentType string
+		url         string
+	}{
+		{"gz file", gzBuf.Bytes(), "application/gzip", ""},
+		{"bz2 file", bz2, "", "https://example.com/hello.txt.bz2"},
+		{"zst file", zstdData, "application/zstd", ""},
+		{"zip file", zipBuf.Bytes(), "", "https://example.com/hello.ZIP?version=1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := compressedResponse(t, tc.body, tc.contentType, tc.url)
+
+			data, bErr := web.GetResponseBody(resp)
+			require.NoError(t, bErr)
+			require.Equal(t, []byte("hello"), data)
+		})
+	}
+
+	// payloads not said to be compressed are returned as they are, whatever they begin with
+	for _, body := range [][]byte{[]byte("BZh is not a bzip2 header"), zipBuf.Bytes()} {
+		resp := compressedResponse(t, body, "application/json", "https://example.com/ips.json")
+
+		data, bErr := web.GetResponseBody(resp)
+		require.NoError(t, bErr)
+		require.Equal(t, body, data)
+	}
+
+	// a gzip file served with gzip content encoding is decoded twice
+	var doubleBuf bytes.Buffer
+	gz = gzip.NewWriter(&doubleBuf)
+	_, err = gz.Write(gzBuf.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	resp := compressedResponse(t, doubleBuf.Bytes(), "application/gzip", "")
+	resp.Header.Set("Content-Encoding", "gzip")
+
+	data, err := web.GetResponseBody(resp)
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), data)
+}
+
+// compressedResponse returns a response with the body and, if set, Content-Type, to a request
+// for the url, if set.
+func compressedResponse(t *testing.T, body []byte, contentType, u string) *http.Response {
+	t.Helper()
+
+	resp := responseWithEncoding(body, "")
+	if contentType != "" {
+		resp.Header.Set("Content-Type", contentType)
+	}
+
+	if u != "" {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		require.NoError(t, err)
+
+		resp.Request = req
+	}
+
+	return resp
+}
+
+func TestGetResponseBodyMultiFileZip(t *testing.T) {
+	var zipBuf bytes.Buffer
+	zipW := zip.NewWriter(&zipBuf)
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		f, err := zipW.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(name))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zipW.Close())
+
+	_, err := web.GetResponseBody(compressedResponse(t, zipBuf.Bytes(), "application/zip", ""))
+	require.ErrorContains(t, err, "contains 2 files")
+}
+
+func TestGetResponseBodyEmpty(t *testing.T) {
+	resp := &http.Response{Body: http.NoBody, Header: http.Header{"Content-Encoding": {"gzip"}}}
+
+	data, err := web.GetResponseBody(resp)
+	require.NoError(t, err)
+	require.Empty(t, data)
+}
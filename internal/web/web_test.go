@@ -3,6 +3,7 @@ package web_test
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
@@ -51,6 +52,7 @@ func TestRequestFailure(t *testing.T) {
 	body, headers, status, err := web.Request(c, testURL, http.MethodGet, nil, []string{}, 10*time.Second)
 	require.Error(t, err)
 	require.ErrorContains(t, err, "no worky")
+	require.ErrorIs(t, err, web.ErrNetwork)
 	require.Empty(t, headers)
 	require.Empty(t, body)
 	require.Equal(t, 0, status)
@@ -273,3 +275,31 @@ func TestMaskSecrets(t *testing.T) {
 	masked = web.MaskSecrets("a=1&b=two", nil)
 	require.Equal(t, "a=1&b=two", masked)
 }
+
+func TestRequestStream(t *testing.T) {
+	testURL := "https://www.example.com/mytextfile.txt"
+	u, err := url.Parse(testURL)
+	require.NoError(t, err)
+
+	urlBase := fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+
+	gock.New(urlBase).
+		Get(u.Path).
+		Reply(http.StatusOK).
+		File("testdata/mytextfile.txt").
+		SetHeader("hello", "World")
+
+	c := web.NewHTTPClient()
+
+	gock.InterceptClient(c.HTTPClient)
+
+	body, headers, status, err := web.RequestStream(c, testURL, http.MethodGet, nil, nil, 2*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "World", headers.Get("Hello"))
+
+	b, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.Equal(t, "hello world", string(b))
+	require.NoError(t, body.Close())
+}
@@ -0,0 +1,148 @@
+// Package overlap finds the prefixes of different sources that overlap, such as an address in
+// a deny list that is inside a provider's range.
+package overlap
+
+import (
+	"cmp"
+	"net/netip"
+	"slices"
+)
+
+// Entry is a prefix read from a source.
+type Entry struct {
+	Prefix netip.Prefix `json:"prefix"`
+	Source string       `json:"source"`
+	// Attributes describe the prefix, such as the region or service of a provider's range.
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Relation is how the prefixes of an Overlap relate.
+type Relation string
+
+const (
+	// Equal prefixes cover the same addresses.
+	Equal Relation = "equal"
+	// Contains means the first prefix contains the second.
+	Contains Relation = "contains"
+)
+
+// Overlap is a pair of overlapping prefixes from different sources. A is the shorter, or
+// equal, prefix and so contains B.
+type Overlap struct {
+	A        Entry    `json:"a"`
+	B        Entry    `json:"b"`
+	Relation Relation `json:"relation"`
+}
+
+// node is a node of a binary trie of prefixes, one bit per level.
+type node struct {
+	children [2]*node
+	entries  []Entry
+}
+
+// Tree holds entries in binary tries, one per address family, so that overlapping prefixes are
+// found by walking from each prefix to its ancestors rather than comparing every pair.
+type Tree struct {
+	v4, v6 node
+}
+
+// NewTree returns a Tree holding the entries.
+func NewTree(entries ...Entry) *Tree {
+	t := &Tree{}
+	for _, e := range entries {
+		t.Insert(e)
+	}
+
+	return t
+}
+
+// Insert adds an entry. Entries with invalid prefixes are ignored.
+func (t *Tree) Insert(e Entry) {
+	if !e.Prefix.IsValid() {
+		return
+	}
+
+	e.Prefix = e.Prefix.Masked()
+
+	n := &t.v4
+	if e.Prefix.Addr().Is6() {
+		n = &t.v6
+	}
+
+	b := e.Prefix.Addr().AsSlice()
+
+	for i := range e.Prefix.Bits() {
+		bit := b[i/8] >> (7 - i%8) & 1
+		if n.children[bit] == nil {
+			n.children[bit] = &node{}
+		}
+
+		n = n.children[bit]
+	}
+
+	n.entries = append(n.entries, e)
+}
+
+// Overlaps returns the pairs of overlapping prefixes from different sources, ordered by the
+// containing prefix then the contained prefix.
+func (t *Tree) Overlaps() []Overlap {
+	var res []Overlap
+
+	for _, root := range []*node{&t.v4, &t.v6} {
+		walk(root, nil, &res)
+	}
+
+	slices.SortStableFunc(res, func(a, b Overlap) int {
+		return cmp.Or(
+			comparePrefixes(a.A.Prefix, b.A.Prefix),
+			comparePrefixes(a.B.Prefix, b.B.Prefix),
+			cmp.Compare(a.A.Source, b.A.Source),
+			cmp.Compare(a.B.Source, b.B.Source),
+		)
+	})
+
+	return res
+}
+
+// walk pairs the entries of n with each other and with those of its ancestors, before
+// descending.
+func walk(n *node, ancestors []Entry, res *[]Overlap) {
+	for i, e := range n.entries {
+		for _, a := range ancestors {
+			if a.Source != e.Source {
+				*res = append(*res, Overlap{A: a, B: e, Relation: Contains})
+			}
+		}
+
+		for _, o := range n.entries[i+1:] {
+			if o.Source != e.Source {
+				*res = append(*res, Overlap{A: e, B: o, Relation: Equal})
+			}
+		}
+	}
+
+	ancestors = append(ancestors, n.entries...)
+
+	for _, c := range n.children {
+		if c != nil {
+			walk(c, ancestors[:len(ancestors):len(ancestors)], res)
+		}
+	}
+}
+
+// Find returns the pairs of overlapping prefixes from different sources.
+func Find(entries ...[]Entry) []Overlap {
+	t := &Tree{}
+
+	for _, es := range entries {
+		for _, e := range es {
+			t.Insert(e)
+		}
+	}
+
+	return t.Overlaps()
+}
+
+func comparePrefixes(a, b netip.Prefix) int {
+	return cmp.Or(a.Addr().Compare(b.Addr()), cmp.Compare(a.Bits(), b.Bits()))
+}
@@ -0,0 +1,78 @@
+package overlap_test
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/netip"
+	"testing"
+
+	"github.com/jonhadfield/ip-fetcher/overlap"
+	"github.com/stretchr/testify/require"
+)
+
+func entries(source string, prefixes ...string) []overlap.Entry {
+	res := make([]overlap.Entry, len(prefixes))
+	for i, p := range prefixes {
+		res[i] = overlap.Entry{Prefix: netip.MustParsePrefix(p), Source: source}
+	}
+
+	return res
+}
+
+func describe(overlaps []overlap.Overlap) []string {
+	res := make([]string, len(overlaps))
+	for i, o := range overlaps {
+		res[i] = fmt.Sprintf("%s %s %s %s %s", o.A.Source, o.A.Prefix, o.Relation, o.B.Source, o.B.Prefix)
+	}
+
+	return res
+}
+
+func TestFind(t *testing.T) {
+	cloudflare := entries("cloudflare", "104.16.0.0/13", "2606:4700::/32")
+	abuse := entries("abuseipdb", "104.16.1.1/32", "8.8.8.8/32", "2606:4700:10::1/128")
+	internal := entries("internal", "104.16.0.0/13", "104.16.1.0/24", "10.0.0.0/8", "8.8.8.8/32")
+
+	got := describe(overlap.Find(cloudflare, abuse, internal))
+	require.Equal(t, []string{
+		"abuseipdb 8.8.8.8/32 equal internal 8.8.8.8/32",
+		"cloudflare 104.16.0.0/13 equal internal 104.16.0.0/13",
+		"cloudflare 104.16.0.0/13 contains internal 104.16.1.0/24",
+		"cloudflare 104.16.0.0/13 contains abuseipdb 104.16.1.1/32",
+		"internal 104.16.0.0/13 contains abuseipdb 104.16.1.1/32",
+		"internal 104.16.1.0/24 contains abuseipdb 104.16.1.1/32",
+		"cloudflare 2606:4700::/32 contains abuseipdb 2606:4700:10::1/128",
+	}, got)
+
+	// prefixes of the same source are not compared
+	require.Empty(t, overlap.Find(entries("a", "10.0.0.0/8", "10.1.0.0/16", "10.1.0.0/16")))
+	require.Empty(t, overlap.Find(entries("a", "10.0.0.0/8"), entries("b", "11.0.0.0/8", "::/0")))
+}
+
+func TestTreeMatchesPairwise(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+
+	random := func(source string, n int) []overlap.Entry {
+		res := make([]overlap.Entry, n)
+		for i := range res {
+			a := netip.AddrFrom4([4]byte{10, byte(r.IntN(4)), byte(r.IntN(256)), byte(r.IntN(256))})
+			res[i] = overlap.Entry{Prefix: netip.PrefixFrom(a, 14+r.IntN(19)).Masked(), Source: source}
+		}
+
+		return res
+	}
+
+	a, b := random("a", 300), random("b", 300)
+
+	var want int
+
+	for _, x := range a {
+		for _, y := range b {
+			if x.Prefix.Overlaps(y.Prefix) {
+				want++
+			}
+		}
+	}
+
+	require.Len(t, overlap.Find(a, b), want)
+}
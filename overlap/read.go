@@ -0,0 +1,129 @@
+package overlap
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jonhadfield/ip-fetcher/providers/url"
+)
+
+// ReadEntries reads the prefixes of a source's document. The prefixes of JSON documents are
+// described by the other values of the objects holding them, such as a range's region and
+// service, except for those of the document itself. Other documents are read in any format
+// read by url sources.
+func ReadEntries(source string, data []byte) ([]Entry, error) {
+	var entries []Entry
+
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		dec.UseNumber()
+
+		var doc any
+		if err := dec.Decode(&doc); err == nil {
+			r := jsonReader{source: source, entries: &entries}
+
+			// the document's own values, such as a sync token, don't describe its prefixes
+			if m, ok := doc.(map[string]any); ok {
+				for _, k := range sortedKeys(m) {
+					r.read(m[k], nil)
+				}
+			} else {
+				r.read(doc, nil)
+			}
+
+			return entries, nil
+		}
+	}
+
+	err := url.ReadPrefixes(bytes.NewReader(data), url.FormatAuto, "", func(p netip.Prefix) error {
+		entries = append(entries, Entry{Prefix: p, Source: source})
+
+		return nil
+	})
+
+	return entries, err
+}
+
+type jsonReader struct {
+	source  string
+	entries *[]Entry
+}
+
+// read adds the prefixes in v, described by the attributes of the objects holding it.
+func (r jsonReader) read(v any, attrs map[string]string) {
+	switch t := v.(type) {
+	case string:
+		if p, ok := parsePrefix(t); ok {
+			*r.entries = append(*r.entries, Entry{Prefix: p, Source: r.source, Attributes: attrs})
+		}
+	case []any:
+		for _, e := range t {
+			r.read(e, attrs)
+		}
+	case map[string]any:
+		own := maps.Clone(attrs)
+
+		for k, mv := range t {
+			s, ok := scalar(mv)
+			if !ok {
+				continue
+			}
+
+			if _, isPrefix := parsePrefix(s); isPrefix {
+				continue
+			}
+
+			if own == nil {
+				own = make(map[string]string)
+			}
+
+			own[k] = s
+		}
+
+		for _, k := range sortedKeys(t) {
+			r.read(t[k], own)
+		}
+	}
+}
+
+// scalar returns the text of a string, number or boolean.
+func scalar(v any) (string, bool) {
+	switch t := v.(type) {
+	case string:
+		return t, true
+	case json.Number:
+		return t.String(), true
+	case bool:
+		return strconv.FormatBool(t), true
+	default:
+		return "", false
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// parsePrefix parses a prefix or, as a single address prefix, an address.
+func parsePrefix(s string) (netip.Prefix, bool) {
+	s = strings.TrimSpace(s)
+
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+
+		return p, err == nil
+	}
+
+	a, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+
+	return netip.PrefixFrom(a, a.BitLen()), true
+}
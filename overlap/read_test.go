@@ -0,0 +1,37 @@
+package overlap_test
+
+import (
+	"testing"
+
+	"github.com/jonhadfield/ip-fetcher/overlap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadEntriesJSON(t *testing.T) {
+	doc := `{"syncToken":"1","prefixes":[{"ip_prefix":"192.0.2.0/24","region":"eu-west-1","service":"EC2"}],` +
+		`"values":[{"name":"AzureCloud","properties":{"region":"","addressPrefixes":["2001:db8::/32"]}}]}`
+
+	entries, err := overlap.ReadEntries("aws", []byte(doc))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	require.Equal(t, "192.0.2.0/24", entries[0].Prefix.String())
+	require.Equal(t, "aws", entries[0].Source)
+	require.Equal(t, map[string]string{"region": "eu-west-1", "service": "EC2"}, entries[0].Attributes)
+
+	require.Equal(t, "2001:db8::/32", entries[1].Prefix.String())
+	require.Equal(t, map[string]string{"name": "AzureCloud", "region": ""}, entries[1].Attributes)
+}
+
+func TestReadEntriesText(t *testing.T) {
+	entries, err := overlap.ReadEntries("deny.txt", []byte("# deny\n192.0.2.1\n198.51.100.0-198.51.100.3\n"))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "192.0.2.1/32", entries[0].Prefix.String())
+	require.Equal(t, "198.51.100.0/30", entries[1].Prefix.String())
+	require.Nil(t, entries[1].Attributes)
+
+	entries, err = overlap.ReadEntries("geofeed", []byte("192.0.2.0/24,GB,GB-LND,London,\n"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
@@ -19,11 +19,17 @@ import (
 )
 
 const (
+	ShortName  = "abuseipdb"
 	APIURL     = "https://api.abuseipdb.com/api/v2/blacklist"
 	ModuleName = "AbuseIPDB"
 	TimeFormat = "2006-01-02T15:04:05-07:00"
 )
 
+// ErrDailyLimitExceeded is returned when the daily blacklist download quota has been used.
+// It matches web.ErrRateLimited.
+var ErrDailyLimitExceeded = fmt.Errorf( //nolint:gochecknoglobals
+	"%w: exceeded number of allowed blacklist downloads in last 24 hours", web.ErrRateLimited)
+
 type AbuseIPDB struct {
 	Client            *retryablehttp.Client
 	APIURL            string
@@ -70,7 +76,7 @@ func retryPolicy(ctx context.Context, resp *http.Response, err error) (bool, err
 	}
 
 	if resp.StatusCode == http.StatusTooManyRequests {
-		return false, errors.New("exceeded number of allowed blacklist downloads in last 24 hours")
+		return false, ErrDailyLimitExceeded
 	}
 
 	if resp.StatusCode == 0 ||
@@ -82,7 +88,7 @@ func retryPolicy(ctx context.Context, resp *http.Response, err error) (bool, err
 }
 
 func New() AbuseIPDB {
-	c := web.NewHTTPClientWithLogger()
+	c := web.NewProviderHTTPClient(ShortName)
 	c.CheckRetry = retryPolicy
 
 	return AbuseIPDB{
@@ -138,11 +144,11 @@ func (a *AbuseIPDB) FetchData() ([]byte, http.Header, int, error) { //nolint:nes
 	}
 
 	if len(blackList) == 0 {
-		return nil, nil, 0, fmt.Errorf("empty response from %s api with http status code %d", ModuleName, statusCode)
+		return nil, nil, 0, fmt.Errorf("empty response from %s api with http status code %d: %w", ModuleName, statusCode, web.ErrEmptyResult)
 	}
 
 	if statusCode >= http.StatusBadRequest && statusCode < http.StatusInternalServerError {
-		err = parseAPIErrorResponse(blackList)
+		err = parseAPIErrorResponse(reqURL.String(), statusCode, blackList)
 	}
 	return blackList, headers, statusCode, err
 }
@@ -159,7 +165,12 @@ func (a *AbuseIPDB) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 
-	return Parse(data)
+	doc, err := Parse(data)
+	if err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
 }
 
 func Parse(in []byte) (Doc, error) {
@@ -202,26 +213,32 @@ type APIErrorResponse struct {
 	Errors []APIError `json:"errors"`
 }
 
-func parseAPIErrorResponse(in []byte) error {
+func parseAPIErrorResponse(reqURL string, statusCode int, in []byte) error {
+	statusErr := web.NewStatusError(ShortName, reqURL, statusCode)
+
 	var apiErrorResponse APIErrorResponse
 	if err := json.Unmarshal(in, &apiErrorResponse); err != nil {
-		return err
+		statusErr.Detail = fmt.Sprintf("failed to parse api error response: %s", err)
+
+		return statusErr
 	}
 
 	numErrors := len(apiErrorResponse.Errors)
 
 	if numErrors == 0 {
-		return errors.New("unexpected error: api error response did not contain any errors or parsing was unsuccessful")
+		statusErr.Detail = "api error response did not contain any errors"
+
+		return statusErr
 	}
 
 	e := apiErrorResponse.Errors[0]
-	errString := fmt.Sprintf("AbuseIPDB returned '%s' with status code %d", e.Detail, e.Status)
+	statusErr.Detail = fmt.Sprintf("AbuseIPDB returned '%s' with status code %d", e.Detail, e.Status)
 
 	if numErrors > 1 {
-		errString += " - and additional errors"
+		statusErr.Detail += " - and additional errors"
 	}
 
-	return errors.New(errString)
+	return statusErr
 }
 
 type Record struct {
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jonhadfield/ip-fetcher/internal/web"
 	"github.com/jonhadfield/ip-fetcher/providers/abuseipdb"
 
 	"github.com/stretchr/testify/require"
@@ -47,6 +48,7 @@ func TestFetchBlackListFailure(t *testing.T) {
 	_, err = ac.Fetch()
 	require.Error(t, err)
 	require.ErrorContains(t, err, "empty response")
+	require.ErrorIs(t, err, web.ErrEmptyResult)
 }
 
 func TestFetchBlackListData(t *testing.T) {
@@ -136,4 +138,6 @@ func TestFetchBlackListDataIncorrectKey(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, string(data), "Authentication failed.")
 	require.Equal(t, http.StatusUnauthorized, status)
+	require.ErrorIs(t, err, web.ErrAuth)
+	require.ErrorContains(t, err, "Authentication failed.")
 }
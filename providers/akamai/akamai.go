@@ -28,7 +28,7 @@ type Akamai struct {
 func New() Akamai {
 	return Akamai{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -38,7 +38,16 @@ func (a *Akamai) FetchData() ([]byte, http.Header, int, error) {
 		a.DownloadURL = DownloadURL
 	}
 
-	return web.Request(a.Client, a.DownloadURL, http.MethodGet, nil, nil, a.Timeout)
+	data, headers, status, err := web.Request(a.Client, a.DownloadURL, http.MethodGet, nil, nil, a.Timeout)
+	if err != nil {
+		return nil, headers, status, err
+	}
+
+	if status >= http.StatusBadRequest {
+		return nil, headers, status, web.NewStatusError(ShortName, a.DownloadURL, status)
+	}
+
+	return data, headers, status, nil
 }
 
 func (a *Akamai) Fetch() ([]netip.Prefix, error) {
@@ -47,7 +56,12 @@ func (a *Akamai) Fetch() ([]netip.Prefix, error) {
 		return nil, err
 	}
 
-	return ProcessData(data)
+	prefixes, err := ProcessData(data)
+	if err != nil {
+		return nil, web.NewParseError(ShortName, err)
+	}
+
+	return prefixes, nil
 }
 
 func ProcessData(data []byte) ([]netip.Prefix, error) {
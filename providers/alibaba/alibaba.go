@@ -32,7 +32,7 @@ func New() Alibaba {
 	return Alibaba{
 		DownloadURL: bgpview.DefaultURL,
 		ASNs:        ASNs,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.LongRequestTimeout,
 	}
 }
@@ -2,7 +2,6 @@ package atlassian
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/netip"
 	"time"
@@ -29,7 +28,7 @@ type Atlassian struct {
 func New() Atlassian {
 	return Atlassian{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -64,7 +63,7 @@ func (a *Atlassian) FetchData() ([]byte, http.Header, int, error) {
 	}
 
 	if status >= http.StatusBadRequest {
-		return nil, headers, status, fmt.Errorf("failed to download atlassian prefixes. http status code: %d", status)
+		return nil, headers, status, web.NewStatusError(ShortName, a.DownloadURL, status)
 	}
 
 	return data, headers, status, nil
@@ -76,7 +75,12 @@ func (a *Atlassian) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 
-	return ProcessData(data)
+	doc, err := ProcessData(data)
+	if err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
 }
 
 type rawDoc struct {
@@ -46,7 +46,7 @@ func (a *AWS) SourceURL() string {
 func New() AWS {
 	return AWS{
 		InitialURL: DownloadURL,
-		Client:     web.NewHTTPClientWithLogger(),
+		Client:     web.NewProviderHTTPClient(ShortName),
 		Timeout:    web.DefaultRequestTimeout,
 	}
 }
@@ -104,7 +104,16 @@ func (a *AWS) FetchData() ([]byte, http.Header, int, error) {
 	inHeaders := http.Header{}
 	inHeaders.Add("Accept", "application/json")
 
-	return web.Request(a.Client, a.DownloadURL, http.MethodGet, inHeaders, nil, a.Timeout)
+	data, headers, status, err := web.Request(a.Client, a.DownloadURL, http.MethodGet, inHeaders, nil, a.Timeout)
+	if err != nil {
+		return nil, headers, status, err
+	}
+
+	if status >= http.StatusBadRequest {
+		return nil, headers, status, web.NewStatusError(ShortName, a.DownloadURL, status)
+	}
+
+	return data, headers, status, nil
 }
 
 func (a *AWS) Fetch() (Doc, string, error) {
@@ -119,8 +128,11 @@ func (a *AWS) Fetch() (Doc, string, error) {
 	}
 
 	doc, err := ProcessData(data)
+	if err != nil {
+		return Doc{}, "", web.NewParseError(ShortName, err)
+	}
 
-	return doc, etag, err
+	return doc, etag, nil
 }
 
 func ProcessData(data []byte) (Doc, error) {
@@ -156,9 +168,10 @@ func castV4Entries(entries []RawPrefix) ([]Prefix, error) {
 		}
 
 		res = append(res, Prefix{
-			IPPrefix: p,
-			Region:   entry.Region,
-			Service:  entry.Service,
+			IPPrefix:           p,
+			Region:             entry.Region,
+			Service:            entry.Service,
+			NetworkBorderGroup: entry.NetworkBorderGroup,
 		})
 	}
 
@@ -174,9 +187,10 @@ func castV6Entries(entries []RawIPv6Prefix) ([]IPv6Prefix, error) {
 		}
 
 		res = append(res, IPv6Prefix{
-			IPv6Prefix: p,
-			Region:     entry.Region,
-			Service:    entry.Service,
+			IPv6Prefix:         p,
+			Region:             entry.Region,
+			Service:            entry.Service,
+			NetworkBorderGroup: entry.NetworkBorderGroup,
 		})
 	}
 
@@ -188,15 +202,17 @@ func TrimQuotes(in *string) {
 }
 
 type RawPrefix struct {
-	IPPrefix string `json:"ip_prefix" yaml:"ip_prefix"`
-	Region   string `json:"region"    yaml:"region"`
-	Service  string `json:"service"   yaml:"service"`
+	IPPrefix           string `json:"ip_prefix"                      yaml:"ip_prefix"`
+	Region             string `json:"region"                         yaml:"region"`
+	Service            string `json:"service"                        yaml:"service"`
+	NetworkBorderGroup string `json:"network_border_group,omitempty" yaml:"network_border_group,omitempty"`
 }
 
 type RawIPv6Prefix struct {
-	IPv6Prefix string `json:"ipv6_prefix" yaml:"ipv6_prefix"`
-	Region     string `json:"region"      yaml:"region"`
-	Service    string `json:"service"     yaml:"service"`
+	IPv6Prefix         string `json:"ipv6_prefix"                    yaml:"ipv6_prefix"`
+	Region             string `json:"region"                         yaml:"region"`
+	Service            string `json:"service"                        yaml:"service"`
+	NetworkBorderGroup string `json:"network_border_group,omitempty" yaml:"network_border_group,omitempty"`
 }
 
 type RawDoc struct {
@@ -214,13 +230,15 @@ type Doc struct {
 }
 
 type Prefix struct {
-	IPPrefix netip.Prefix `json:"ip_prefix" yaml:"ip_prefix"`
-	Region   string       `json:"region"    yaml:"region"`
-	Service  string       `json:"service"   yaml:"service"`
+	IPPrefix           netip.Prefix `json:"ip_prefix"                      yaml:"ip_prefix"`
+	Region             string       `json:"region"                         yaml:"region"`
+	Service            string       `json:"service"                        yaml:"service"`
+	NetworkBorderGroup string       `json:"network_border_group,omitempty" yaml:"network_border_group,omitempty"`
 }
 
 type IPv6Prefix struct {
-	IPv6Prefix netip.Prefix `json:"ipv6_prefix" yaml:"ipv6_prefix"`
-	Region     string       `json:"region"      yaml:"region"`
-	Service    string       `json:"service"     yaml:"service"`
+	IPv6Prefix         netip.Prefix `json:"ipv6_prefix"                    yaml:"ipv6_prefix"`
+	Region             string       `json:"region"                         yaml:"region"`
+	Service            string       `json:"service"                        yaml:"service"`
+	NetworkBorderGroup string       `json:"network_border_group,omitempty" yaml:"network_border_group,omitempty"`
 }
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/jonhadfield/ip-fetcher/internal/web"
 	"github.com/jonhadfield/ip-fetcher/providers/aws"
 
 	"github.com/stretchr/testify/require"
@@ -80,3 +81,44 @@ func TestDownloadIPListWithoutQuotedEtag(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "dd5e4f079775994d8e49f63ae9a84065", etag)
 }
+
+func TestDownloadIPListStatusError(t *testing.T) {
+	u, err := url.Parse(aws.DownloadURL)
+	require.NoError(t, err)
+	urlBase := fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+
+	gock.New(urlBase).
+		Get(u.Path).
+		Reply(http.StatusForbidden)
+
+	ac := aws.New()
+	ac.DownloadURL = aws.DownloadURL
+	gock.InterceptClient(ac.Client.HTTPClient)
+
+	_, _, err = ac.Fetch()
+	require.ErrorIs(t, err, web.ErrUnexpectedStatus)
+	require.ErrorIs(t, err, web.ErrAuth)
+
+	var statusErr *web.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	require.Equal(t, http.StatusForbidden, statusErr.StatusCode)
+}
+
+func TestDownloadIPListParseError(t *testing.T) {
+	u, err := url.Parse(aws.DownloadURL)
+	require.NoError(t, err)
+	urlBase := fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+
+	gock.New(urlBase).
+		Get(u.Path).
+		Reply(http.StatusOK).
+		BodyString("<html>not json</html>")
+
+	ac := aws.New()
+	ac.DownloadURL = aws.DownloadURL
+	gock.InterceptClient(ac.Client.HTTPClient)
+
+	_, _, err = ac.Fetch()
+	require.ErrorIs(t, err, web.ErrParse)
+	require.NotErrorIs(t, err, web.ErrUnexpectedStatus)
+}
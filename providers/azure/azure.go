@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"regexp"
 	"strings"
@@ -51,7 +52,7 @@ func (a *Azure) SourceURL() string {
 func New() Azure {
 	return Azure{
 		InitialURL: InitialURL,
-		Client:     web.NewHTTPClientWithLogger(),
+		Client:     web.NewProviderHTTPClient(ShortName),
 		Timeout:    web.DefaultRequestTimeout,
 	}
 }
@@ -72,11 +73,11 @@ func (a *Azure) GetDownloadURL() (string, error) {
 		UserAgent: "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:87.0) Gecko/20100101 Firefox/87.0",
 	}, "GET")
 	if err != nil {
-		return "", errors.New(errFailedToDownload)
+		return "", fmt.Errorf("%s: %w: %w", errFailedToDownload, web.ErrNetwork, err)
 	}
 
 	if response.Status >= http.StatusBadRequest {
-		return url, errors.New(errFailedToDownload)
+		return url, fmt.Errorf("%s: %w", errFailedToDownload, web.NewStatusError(ShortName, a.InitialURL, response.Status))
 	}
 
 	body := response.Body
@@ -111,17 +112,7 @@ func (a *Azure) GetDownloadURL() (string, error) {
 }
 
 func (a *Azure) FetchData() ([]byte, http.Header, int, error) {
-	if a.DownloadURL == "" {
-		// Microsoft rotates the dated snapshot URL on roughly a weekly cadence.
-		// Scrape the download page for the current URL and fall back to the
-		// last-known snapshot if discovery fails.
-		discoveredURL, err := a.GetDownloadURL()
-		if err != nil || discoveredURL == "" {
-			a.DownloadURL = WorkaroundDownloadURL
-		} else {
-			a.DownloadURL = discoveredURL
-		}
-	}
+	a.setDownloadURL()
 
 	data, headers, status, err := web.Request(
 		a.Client,
@@ -132,26 +123,162 @@ func (a *Azure) FetchData() ([]byte, http.Header, int, error) {
 		a.Timeout,
 	)
 	if status >= http.StatusBadRequest {
-		return nil, nil, status, fmt.Errorf("failed to download prefixes. http status code: %d", status)
+		return nil, nil, status, web.NewStatusError(ShortName, a.DownloadURL, status)
 	}
 
 	return data, headers, status, err
 }
 
+// setDownloadURL discovers the current download URL if one has not been specified.
+func (a *Azure) setDownloadURL() {
+	if a.DownloadURL != "" {
+		return
+	}
+
+	// Microsoft rotates the dated snapshot URL on roughly a weekly cadence.
+	// Scrape the download page for the current URL and fall back to the
+	// last-known snapshot if discovery fails.
+	discoveredURL, err := a.GetDownloadURL()
+	if err != nil || discoveredURL == "" {
+		a.DownloadURL = WorkaroundDownloadURL
+	} else {
+		a.DownloadURL = discoveredURL
+	}
+}
+
 func (a *Azure) Fetch() (Doc, string, error) {
-	data, headers, _, err := a.FetchData()
+	var values []Value
+
+	doc, md5, err := a.FetchStream(func(v Value) error {
+		values = append(values, v)
+
+		return nil
+	})
 	if err != nil {
 		return Doc{}, "", err
 	}
 
-	var doc Doc
-	if err = json.Unmarshal(data, &doc); err != nil {
+	doc.Values = values
+
+	return doc, md5, nil
+}
+
+// FetchStream downloads the Service Tags document and calls fn for each value as it is decoded,
+// avoiding holding the raw document in memory. The returned Doc has no Values.
+func (a *Azure) FetchStream(fn func(Value) error) (Doc, string, error) {
+	a.setDownloadURL()
+
+	body, headers, status, err := web.RequestStream(
+		a.Client,
+		a.DownloadURL,
+		http.MethodGet,
+		nil,
+		nil,
+		a.Timeout,
+	)
+	if err != nil {
 		return Doc{}, "", err
 	}
+	defer body.Close()
 
-	md5 := headers.Get(web.ContentMD5Header)
+	if status >= http.StatusBadRequest {
+		return Doc{}, "", web.NewStatusError(ShortName, a.DownloadURL, status)
+	}
 
-	return doc, md5, nil
+	doc, err := DecodeValues(body, fn)
+	if err != nil {
+		return Doc{}, "", err
+	}
+
+	return doc, headers.Get(web.ContentMD5Header), nil
+}
+
+// DecodeValues reads a Service Tags document from r, calling fn for each entry of the values
+// array as it is decoded. The returned Doc has no Values. Errors returned by fn stop decoding
+// and are returned unchanged.
+func DecodeValues(r io.Reader, fn func(Value) error) (Doc, error) {
+	var doc Doc
+
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return Doc{}, web.NewParseError(ShortName, err)
+		}
+
+		switch tok {
+		case "changeNumber":
+			err = dec.Decode(&doc.ChangeNumber)
+		case "cloud":
+			err = dec.Decode(&doc.Cloud)
+		case "values":
+			err = decodeValueArray(dec, fn)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+
+		var cbErr callbackError
+		if errors.As(err, &cbErr) {
+			return Doc{}, cbErr.err
+		}
+
+		if err != nil {
+			return Doc{}, web.NewParseError(ShortName, err)
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
+}
+
+// callbackError marks an error returned by a DecodeValues callback so it is not reported as a parse failure.
+type callbackError struct {
+	err error
+}
+
+func (e callbackError) Error() string {
+	return e.err.Error()
+}
+
+func decodeValueArray(dec *json.Decoder, fn func(Value) error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		var v Value
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+
+		if err := fn(v); err != nil {
+			return callbackError{err: err}
+		}
+	}
+
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("expected %s but found %v", want, tok)
+	}
+
+	return nil
 }
 
 type Doc struct {
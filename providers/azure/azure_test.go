@@ -1,9 +1,12 @@
 package azure_test
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/jonhadfield/ip-fetcher/providers/azure"
@@ -179,3 +182,54 @@ func TestFetch(t *testing.T) {
 	require.Equal(t, 232, prefixes.ChangeNumber)
 	require.Len(t, prefixes.Values, 2643)
 }
+
+func TestDecodeValues(t *testing.T) {
+	f, err := os.Open(testDataFilePath)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	var count int
+
+	doc, err := azure.DecodeValues(f, func(v azure.Value) error {
+		require.NotEmpty(t, v.Name)
+		count++
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Public", doc.Cloud)
+	require.Equal(t, 232, doc.ChangeNumber)
+	require.Empty(t, doc.Values)
+	require.Equal(t, 2643, count)
+}
+
+func TestDecodeValuesCallbackError(t *testing.T) {
+	errStop := errors.New("stop")
+
+	var count int
+
+	_, err := azure.DecodeValues(
+		strings.NewReader(`{"changeNumber":1,"values":[{"name":"a"},{"name":"b"}]}`),
+		func(v azure.Value) error {
+			count++
+
+			return errStop
+		},
+	)
+	require.ErrorIs(t, err, errStop)
+	require.NotErrorIs(t, err, web.ErrParse)
+	require.Equal(t, 1, count)
+}
+
+func TestDecodeValuesInvalid(t *testing.T) {
+	_, err := azure.DecodeValues(strings.NewReader(`{"values":{}}`), func(azure.Value) error {
+		return nil
+	})
+	require.ErrorIs(t, err, web.ErrParse)
+
+	_, err = azure.DecodeValues(strings.NewReader(`[]`), func(azure.Value) error {
+		return nil
+	})
+	require.ErrorIs(t, err, web.ErrParse)
+}
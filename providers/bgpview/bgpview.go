@@ -2,6 +2,7 @@ package bgpview
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/netip"
@@ -27,6 +28,10 @@ const maxConcurrentRIPECalls = 2
 
 var ripeSem = make(chan struct{}, maxConcurrentRIPECalls) //nolint:gochecknoglobals
 
+// ErrAllSourcesFailed is returned when neither RIPE stat nor BGPView could provide prefixes for an ASN.
+// The underlying errors from each source are wrapped alongside it.
+var ErrAllSourcesFailed = errors.New("both RIPE stat and BGPView APIs failed") //nolint:gochecknoglobals
+
 // Response represents the BGPView API response structure.
 type Response struct {
 	Status        string `json:"status"`
@@ -103,13 +108,13 @@ func fetchFromBGPView(client *retryablehttp.Client, asn, url string, timeout tim
 	}
 
 	if status != http.StatusOK {
-		return Response{}, headers, status, fmt.Errorf("BGPView API returned status %d for ASN %s", status, asn)
+		return Response{}, headers, status, fmt.Errorf("BGPView API failed for ASN %s: %w", asn, web.NewStatusError("", url, status))
 	}
 
 	var response Response
 	err = json.Unmarshal(body, &response)
 	if err != nil {
-		return Response{}, nil, 0, fmt.Errorf("error unmarshalling BGPView response for ASN %s: %w", asn, err)
+		return Response{}, nil, 0, fmt.Errorf("BGPView response for ASN %s: %w", asn, web.NewParseError("BGPView", err))
 	}
 
 	return response, headers, status, nil
@@ -128,13 +133,13 @@ func fetchFromRIPEStat(client *retryablehttp.Client, asn string, timeout time.Du
 	}
 
 	if status != http.StatusOK {
-		return Response{}, headers, status, fmt.Errorf("RIPE stat API returned status %d for ASN %s", status, asn)
+		return Response{}, headers, status, fmt.Errorf("RIPE stat API failed for ASN %s: %w", asn, web.NewStatusError("", url, status))
 	}
 
 	var ripeResponse RIPEStatResponse
 	err = json.Unmarshal(body, &ripeResponse)
 	if err != nil {
-		return Response{}, nil, 0, fmt.Errorf("error unmarshalling RIPE stat response for ASN %s: %w", asn, err)
+		return Response{}, nil, 0, fmt.Errorf("RIPE stat response for ASN %s: %w", asn, web.NewParseError("RIPE stat", err))
 	}
 
 	// Convert RIPE stat response to BGPView format
@@ -248,7 +253,7 @@ func FetchData(client *retryablehttp.Client, downloadURL string, asns []string,
 				var bgpErr error
 				response, h, s, bgpErr = fetchFromBGPView(client, asn, asnURL, timeout)
 				if bgpErr != nil {
-					return fmt.Errorf("both RIPE stat and BGPView APIs failed for ASN %s: RIPE: %w; BGPView: %w", asn, ripeErr, bgpErr)
+					return fmt.Errorf("%w for ASN %s: RIPE: %w; BGPView: %w", ErrAllSourcesFailed, asn, ripeErr, bgpErr)
 				}
 			}
 
@@ -276,7 +281,7 @@ func FetchData(client *retryablehttp.Client, downloadURL string, asns []string,
 			var p netip.Prefix
 			p, err = netip.ParsePrefix(prefix.Prefix)
 			if err != nil {
-				return nil, nil, 0, fmt.Errorf("error parsing IPv4 prefix %s: %w", prefix.Prefix, err)
+				return nil, nil, 0, web.NewParseError(providerName, fmt.Errorf("error parsing IPv4 prefix %s: %w", prefix.Prefix, err))
 			}
 
 			doc.IPv4Prefixes = append(doc.IPv4Prefixes, p)
@@ -286,7 +291,7 @@ func FetchData(client *retryablehttp.Client, downloadURL string, asns []string,
 			var p netip.Prefix
 			p, err = netip.ParsePrefix(prefix.Prefix)
 			if err != nil {
-				return nil, nil, 0, fmt.Errorf("error parsing IPv6 prefix %s: %w", prefix.Prefix, err)
+				return nil, nil, 0, web.NewParseError(providerName, fmt.Errorf("error parsing IPv6 prefix %s: %w", prefix.Prefix, err))
 			}
 
 			doc.IPv6Prefixes = append(doc.IPv6Prefixes, p)
@@ -307,7 +312,7 @@ func ProcessData(data []byte, providerName string) (Doc, error) {
 	var doc Doc
 	err := json.Unmarshal(data, &doc)
 	if err != nil {
-		return Doc{}, fmt.Errorf("error unmarshalling %s doc: %w", providerName, err)
+		return Doc{}, web.NewParseError(providerName, err)
 	}
 
 	return doc, nil
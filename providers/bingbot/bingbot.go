@@ -11,6 +11,7 @@ import (
 )
 
 const (
+	ShortName                = "bingbot"
 	DownloadURL              = "https://www.bing.com/toolbox/bingbot.json"
 	downloadedFileTimeFormat = "2006-01-02T15:04:05.999999"
 )
@@ -18,7 +19,7 @@ const (
 func New() Bingbot {
 	return Bingbot{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -38,7 +39,16 @@ func (bb *Bingbot) FetchData() ([]byte, http.Header, int, error) {
 	if bb.DownloadURL == "" {
 		bb.DownloadURL = DownloadURL
 	}
-	return web.Request(bb.Client, bb.DownloadURL, http.MethodGet, nil, nil, bb.Timeout)
+	data, headers, status, err := web.Request(bb.Client, bb.DownloadURL, http.MethodGet, nil, nil, bb.Timeout)
+	if err != nil {
+		return nil, headers, status, err
+	}
+
+	if status >= http.StatusBadRequest {
+		return nil, headers, status, web.NewStatusError(ShortName, bb.DownloadURL, status)
+	}
+
+	return data, headers, status, nil
 }
 
 func (bb *Bingbot) Fetch() (Doc, error) {
@@ -47,7 +57,12 @@ func (bb *Bingbot) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 
-	return ProcessData(data)
+	doc, err := ProcessData(data)
+	if err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
 }
 
 func ProcessData(data []byte) (Doc, error) {
@@ -2,7 +2,6 @@ package bunny
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/netip"
 	"time"
@@ -34,7 +33,7 @@ func New() Bunny {
 	return Bunny{
 		IPv4URL: IPv4URL,
 		IPv6URL: IPv6URL,
-		Client:  web.NewHTTPClientWithLogger(),
+		Client:  web.NewProviderHTTPClient(ShortName),
 		Timeout: web.DefaultRequestTimeout,
 	}
 }
@@ -59,7 +58,7 @@ func (b *Bunny) fetchList(url string) ([]string, http.Header, int, error) {
 	}
 
 	if status >= http.StatusBadRequest {
-		return nil, headers, status, fmt.Errorf("failed to download bunny prefixes from %s. http status code: %d", url, status)
+		return nil, headers, status, web.NewStatusError(ShortName, url, status)
 	}
 
 	var entries []string
@@ -103,7 +102,12 @@ func (b *Bunny) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 
-	return ProcessData(data)
+	doc, err := ProcessData(data)
+	if err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
 }
 
 func ProcessData(data []byte) (Doc, error) {
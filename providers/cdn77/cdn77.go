@@ -2,7 +2,6 @@ package cdn77
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/netip"
 	"time"
@@ -29,7 +28,7 @@ type CDN77 struct {
 func New() CDN77 {
 	return CDN77{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -50,7 +49,7 @@ func (c *CDN77) FetchData() ([]byte, http.Header, int, error) {
 	}
 
 	if status >= http.StatusBadRequest {
-		return nil, headers, status, fmt.Errorf("failed to download cdn77 prefixes. http status code: %d", status)
+		return nil, headers, status, web.NewStatusError(ShortName, c.DownloadURL, status)
 	}
 
 	return data, headers, status, nil
@@ -62,7 +61,12 @@ func (c *CDN77) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 
-	return ProcessData(data)
+	doc, err := ProcessData(data)
+	if err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
 }
 
 func ProcessData(data []byte) (Doc, error) {
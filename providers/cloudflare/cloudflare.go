@@ -26,7 +26,7 @@ func New() Cloudflare {
 	return Cloudflare{
 		IPv4DownloadURL: DefaultIPv4URL,
 		IPv6DownloadURL: DefaultIPv6URL,
-		Client:          web.NewHTTPClientWithLogger(),
+		Client:          web.NewProviderHTTPClient(ShortName),
 		Timeout:         web.DefaultRequestTimeout,
 	}
 }
@@ -43,7 +43,7 @@ func (cf *Cloudflare) FetchIPv4Data() ([]byte, http.Header, int, error) {
 		cf.IPv4DownloadURL = DefaultIPv4URL
 	}
 
-	return web.Request(cf.Client, cf.IPv4DownloadURL, http.MethodGet, nil, nil, cf.Timeout)
+	return cf.fetchData(cf.IPv4DownloadURL)
 }
 
 func (cf *Cloudflare) FetchIPv6Data() ([]byte, http.Header, int, error) {
@@ -51,7 +51,20 @@ func (cf *Cloudflare) FetchIPv6Data() ([]byte, http.Header, int, error) {
 		cf.IPv6DownloadURL = DefaultIPv6URL
 	}
 
-	return web.Request(cf.Client, cf.IPv6DownloadURL, http.MethodGet, nil, nil, cf.Timeout)
+	return cf.fetchData(cf.IPv6DownloadURL)
+}
+
+func (cf *Cloudflare) fetchData(url string) ([]byte, http.Header, int, error) {
+	data, headers, status, err := web.Request(cf.Client, url, http.MethodGet, nil, nil, cf.Timeout)
+	if err != nil {
+		return nil, headers, status, err
+	}
+
+	if status >= http.StatusBadRequest {
+		return nil, headers, status, web.NewStatusError(ShortName, url, status)
+	}
+
+	return data, headers, status, nil
 }
 
 func (cf *Cloudflare) Fetch4() ([]netip.Prefix, error) {
@@ -60,7 +73,12 @@ func (cf *Cloudflare) Fetch4() ([]netip.Prefix, error) {
 		return nil, err
 	}
 
-	return ProcessData(data)
+	prefixes, err := ProcessData(data)
+	if err != nil {
+		return nil, web.NewParseError(ShortName, err)
+	}
+
+	return prefixes, nil
 }
 
 func (cf *Cloudflare) Fetch6() ([]netip.Prefix, error) {
@@ -69,7 +87,12 @@ func (cf *Cloudflare) Fetch6() ([]netip.Prefix, error) {
 		return nil, err
 	}
 
-	return ProcessData(data)
+	prefixes, err := ProcessData(data)
+	if err != nil {
+		return nil, web.NewParseError(ShortName, err)
+	}
+
+	return prefixes, nil
 }
 
 func (cf *Cloudflare) Fetch() ([]netip.Prefix, error) {
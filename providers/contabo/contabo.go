@@ -32,7 +32,7 @@ func New() Contabo {
 	return Contabo{
 		DownloadURL: bgpview.DefaultURL,
 		ASNs:        ASNs,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.LongRequestTimeout,
 	}
 }
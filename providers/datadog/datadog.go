@@ -2,7 +2,6 @@ package datadog
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/netip"
 	"sort"
@@ -30,7 +29,7 @@ type Datadog struct {
 func New() Datadog {
 	return Datadog{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -60,7 +59,7 @@ func (d *Datadog) FetchData() ([]byte, http.Header, int, error) {
 	}
 
 	if status >= http.StatusBadRequest {
-		return nil, headers, status, fmt.Errorf("failed to download datadog prefixes. http status code: %d", status)
+		return nil, headers, status, web.NewStatusError(ShortName, d.DownloadURL, status)
 	}
 
 	return data, headers, status, nil
@@ -72,7 +71,12 @@ func (d *Datadog) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 
-	return ProcessData(data)
+	doc, err := ProcessData(data)
+	if err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
 }
 
 // rawCategory mirrors the per-service object in the upstream document.
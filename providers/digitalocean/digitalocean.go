@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/csv"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"net/netip"
@@ -16,6 +15,7 @@ import (
 )
 
 const (
+	ShortName               = "digitalocean"
 	DigitaloceanDownloadURL = "https://www.digitalocean.com/geo/google.csv"
 	errFailedToDownload     = "failed to download digital ocean prefixes document "
 )
@@ -29,7 +29,7 @@ type DigitalOcean struct {
 func New() DigitalOcean {
 	return DigitalOcean{
 		DownloadURL: DigitaloceanDownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -49,7 +49,7 @@ func (a *DigitalOcean) FetchData() ([]byte, http.Header, int, error) {
 		a.Timeout,
 	)
 	if status >= http.StatusBadRequest {
-		return nil, nil, status, fmt.Errorf("failed to download prefixes. http status code: %d", status)
+		return nil, nil, status, web.NewStatusError(ShortName, a.DownloadURL, status)
 	}
 
 	return data, headers, status, err
@@ -69,7 +69,7 @@ func (a *DigitalOcean) Fetch() (Doc, error) {
 
 	records, err := Parse(data)
 	if err != nil {
-		return Doc{}, err
+		return Doc{}, web.NewParseError(ShortName, err)
 	}
 
 	var doc Doc
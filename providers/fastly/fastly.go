@@ -23,7 +23,7 @@ const (
 func New() Fastly {
 	return Fastly{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -39,7 +39,16 @@ func (f *Fastly) FetchData() ([]byte, http.Header, int, error) {
 		f.DownloadURL = DownloadURL
 	}
 
-	return web.Request(f.Client, f.DownloadURL, http.MethodGet, nil, nil, f.Timeout)
+	data, headers, status, err := web.Request(f.Client, f.DownloadURL, http.MethodGet, nil, nil, f.Timeout)
+	if err != nil {
+		return nil, headers, status, err
+	}
+
+	if status >= http.StatusBadRequest {
+		return nil, headers, status, web.NewStatusError(ShortName, f.DownloadURL, status)
+	}
+
+	return data, headers, status, nil
 }
 
 func (f *Fastly) Fetch() (Doc, error) {
@@ -48,7 +57,12 @@ func (f *Fastly) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 
-	return ProcessData(data)
+	doc, err := ProcessData(data)
+	if err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
 }
 
 type RawDoc struct {
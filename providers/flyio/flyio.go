@@ -32,7 +32,7 @@ func New() Flyio {
 	return Flyio{
 		DownloadURL: bgpview.DefaultURL,
 		ASNs:        ASNs,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.LongRequestTimeout,
 	}
 }
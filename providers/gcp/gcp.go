@@ -22,7 +22,7 @@ const (
 func New() GCP {
 	return GCP{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -45,7 +45,16 @@ func (gc *GCP) FetchData() ([]byte, http.Header, int, error) {
 		gc.DownloadURL = DownloadURL
 	}
 
-	return web.Request(gc.Client, gc.DownloadURL, http.MethodGet, nil, nil, gc.Timeout)
+	data, headers, status, err := web.Request(gc.Client, gc.DownloadURL, http.MethodGet, nil, nil, gc.Timeout)
+	if err != nil {
+		return nil, headers, status, err
+	}
+
+	if status >= http.StatusBadRequest {
+		return nil, headers, status, web.NewStatusError(ShortName, gc.DownloadURL, status)
+	}
+
+	return data, headers, status, nil
 }
 
 func (gc *GCP) Fetch() (Doc, error) {
@@ -54,7 +63,12 @@ func (gc *GCP) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 
-	return ProcessData(data)
+	doc, err := ProcessData(data)
+	if err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
 }
 
 func ProcessData(data []byte) (Doc, error) {
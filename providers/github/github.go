@@ -27,7 +27,7 @@ type GitHub struct {
 func New() GitHub {
 	return GitHub{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -37,7 +37,16 @@ func (gh *GitHub) FetchData() ([]byte, http.Header, int, error) {
 		gh.DownloadURL = DownloadURL
 	}
 
-	return web.Request(gh.Client, gh.DownloadURL, http.MethodGet, nil, nil, gh.Timeout)
+	data, headers, status, err := web.Request(gh.Client, gh.DownloadURL, http.MethodGet, nil, nil, gh.Timeout)
+	if err != nil {
+		return nil, headers, status, err
+	}
+
+	if status >= http.StatusBadRequest {
+		return nil, headers, status, web.NewStatusError(ShortName, gh.DownloadURL, status)
+	}
+
+	return data, headers, status, nil
 }
 
 func (gh *GitHub) Fetch() ([]netip.Prefix, error) {
@@ -46,7 +55,12 @@ func (gh *GitHub) Fetch() ([]netip.Prefix, error) {
 		return nil, err
 	}
 
-	return ProcessData(data)
+	prefixes, err := ProcessData(data)
+	if err != nil {
+		return nil, web.NewParseError(ShortName, err)
+	}
+
+	return prefixes, nil
 }
 
 func ProcessData(data []byte) ([]netip.Prefix, error) {
@@ -22,7 +22,7 @@ const (
 func New() Google {
 	return Google{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -58,7 +58,15 @@ func (gc *Google) FetchData() ([]byte, http.Header, int, error) {
 		nil,
 		gc.Timeout,
 	)
-	return data, headers, status, err
+	if err != nil {
+		return nil, headers, status, err
+	}
+
+	if status >= http.StatusBadRequest {
+		return nil, headers, status, web.NewStatusError(ShortName, gc.DownloadURL, status)
+	}
+
+	return data, headers, status, nil
 }
 
 func (gc *Google) Fetch() (Doc, error) {
@@ -67,7 +75,12 @@ func (gc *Google) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 
-	return ProcessData(data)
+	doc, err := ProcessData(data)
+	if err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
 }
 
 func ProcessData(data []byte) (Doc, error) {
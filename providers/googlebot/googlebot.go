@@ -22,7 +22,7 @@ const (
 func New() Googlebot {
 	return Googlebot{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -57,7 +57,15 @@ func (gc *Googlebot) FetchData() ([]byte, http.Header, int, error) {
 		nil,
 		gc.Timeout,
 	)
-	return data, headers, status, err
+	if err != nil {
+		return nil, headers, status, err
+	}
+
+	if status >= http.StatusBadRequest {
+		return nil, headers, status, web.NewStatusError(ShortName, gc.DownloadURL, status)
+	}
+
+	return data, headers, status, nil
 }
 
 func (gc *Googlebot) Fetch() (Doc, error) {
@@ -66,7 +74,12 @@ func (gc *Googlebot) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 
-	return ProcessData(data)
+	doc, err := ProcessData(data)
+	if err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
 }
 
 func ProcessData(data []byte) (Doc, error) {
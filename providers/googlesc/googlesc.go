@@ -22,7 +22,7 @@ const (
 func New() Googlesc {
 	return Googlesc{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -57,7 +57,15 @@ func (gs *Googlesc) FetchData() ([]byte, http.Header, int, error) {
 		nil,
 		gs.Timeout,
 	)
-	return data, headers, status, err
+	if err != nil {
+		return nil, headers, status, err
+	}
+
+	if status >= http.StatusBadRequest {
+		return nil, headers, status, web.NewStatusError(ShortName, gs.DownloadURL, status)
+	}
+
+	return data, headers, status, nil
 }
 
 func (gs *Googlesc) Fetch() (Doc, error) {
@@ -66,7 +74,12 @@ func (gs *Googlesc) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 
-	return ProcessData(data)
+	doc, err := ProcessData(data)
+	if err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
 }
 
 func ProcessData(data []byte) (Doc, error) {
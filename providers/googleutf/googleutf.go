@@ -22,7 +22,7 @@ const (
 func New() Googleutf {
 	return Googleutf{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -43,7 +43,16 @@ func (gu *Googleutf) FetchData() ([]byte, http.Header, int, error) {
 	if gu.DownloadURL == "" {
 		gu.DownloadURL = DownloadURL
 	}
-	return web.Request(gu.Client, gu.DownloadURL, http.MethodGet, nil, nil, gu.Timeout)
+	data, headers, status, err := web.Request(gu.Client, gu.DownloadURL, http.MethodGet, nil, nil, gu.Timeout)
+	if err != nil {
+		return nil, headers, status, err
+	}
+
+	if status >= http.StatusBadRequest {
+		return nil, headers, status, web.NewStatusError(ShortName, gu.DownloadURL, status)
+	}
+
+	return data, headers, status, nil
 }
 
 func (gu *Googleutf) Fetch() (Doc, error) {
@@ -52,7 +61,12 @@ func (gu *Googleutf) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 
-	return ProcessData(data)
+	doc, err := ProcessData(data)
+	if err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
 }
 
 func ProcessData(data []byte) (Doc, error) {
@@ -33,7 +33,7 @@ func New() IBMCloud {
 	return IBMCloud{
 		DownloadURL: bgpview.DefaultURL,
 		ASNs:        ASNs,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.LongRequestTimeout,
 	}
 }
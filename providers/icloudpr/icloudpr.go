@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/csv"
 	"errors"
-	"fmt"
 	"io"
 	"log/slog"
 	"net"
@@ -71,7 +70,7 @@ type ICloudPrivateRelay struct {
 func New() ICloudPrivateRelay {
 	return ICloudPrivateRelay{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -90,7 +89,7 @@ func (a *ICloudPrivateRelay) FetchData() ([]byte, http.Header, int, error) {
 
 	data, headers, status, err = web.Request(a.Client, a.DownloadURL, http.MethodGet, nil, nil, a.Timeout)
 	if status >= http.StatusBadRequest {
-		return nil, nil, status, fmt.Errorf("failed to download prefixes. http status code: %d", status)
+		return nil, nil, status, web.NewStatusError(ShortName, a.DownloadURL, status)
 	}
 
 	return data, headers, status, err
@@ -103,40 +102,70 @@ type Doc struct {
 }
 
 func (a *ICloudPrivateRelay) Fetch() (Doc, error) {
-	data, headers, _, err := a.FetchData()
+	var records []Record
+
+	doc, err := a.FetchStream(func(r Record) error {
+		records = append(records, r)
+
+		return nil
+	})
 	if err != nil {
 		return Doc{}, err
 	}
 
-	records, err := Parse(data)
+	doc.Records = records
+
+	return doc, nil
+}
+
+// FetchStream downloads the egress ranges and calls fn for each record as it is decoded,
+// avoiding holding the multi-megabyte CSV in memory. The returned Doc has no Records.
+func (a *ICloudPrivateRelay) FetchStream(fn func(Record) error) (Doc, error) {
+	if a.DownloadURL == "" {
+		a.DownloadURL = DownloadURL
+	}
+
+	body, headers, status, err := web.RequestStream(a.Client, a.DownloadURL, http.MethodGet, nil, nil, a.Timeout)
 	if err != nil {
 		return Doc{}, err
 	}
+	defer body.Close()
 
-	var doc Doc
-	doc.Records = records
+	if status >= http.StatusBadRequest {
+		return Doc{}, web.NewStatusError(ShortName, a.DownloadURL, status)
+	}
 
-	var etag string
+	doc, err := docFromHeaders(headers)
+	if err != nil {
+		return Doc{}, err
+	}
 
-	etags := headers.Values(web.ETagHeader)
-	if len(etags) != 0 {
-		etag = etags[0]
+	if err = ParseStream(body, fn); err != nil {
+		return Doc{}, err
 	}
 
-	doc.ETag = etag
+	return doc, nil
+}
 
-	var lastModifiedTime time.Time
+func docFromHeaders(headers http.Header) (Doc, error) {
+	var doc Doc
+
+	etags := headers.Values(web.ETagHeader)
+	if len(etags) != 0 {
+		doc.ETag = etags[0]
+	}
 
 	lastModifiedRaw := headers.Values(web.LastModifiedHeader)
 	if len(lastModifiedRaw) != 0 {
-		if lastModifiedTime, err = time.Parse(time.RFC1123, lastModifiedRaw[0]); err != nil {
+		lastModifiedTime, err := time.Parse(time.RFC1123, lastModifiedRaw[0])
+		if err != nil {
 			return Doc{}, err
 		}
-	}
 
-	doc.LastModified = lastModifiedTime
+		doc.LastModified = lastModifiedTime
+	}
 
-	return doc, err
+	return doc, nil
 }
 
 type Entry struct {
@@ -148,52 +177,61 @@ type Entry struct {
 }
 
 func Parse(data []byte) ([]Record, error) {
-	var (
-		records []Record
-		err     error
-	)
+	var records []Record
+
+	err := ParseStream(bytes.NewReader(data), func(r Record) error {
+		records = append(records, r)
 
-	reader := bytes.NewReader(data)
+		return nil
+	})
+
+	return records, err
+}
 
-	csvReader := csv.NewReader(reader)
+// ParseStream decodes geofeed CSV records from r one at a time, calling fn for each.
+// Decoding failures are returned as parse errors; errors returned by fn stop decoding
+// and are returned unchanged.
+func ParseStream(r io.Reader, fn func(Record) error) error {
+	csvReader := csv.NewReader(r)
 	csvReader.Comment = '#'
 	csvReader.TrimLeadingSpace = true
+	csvReader.ReuseRecord = true
 
 	doHeader, err := csvutil.Header(Entry{}, "csv")
 	if err != nil {
-		return records, err
+		return err
 	}
 
 	dec, err := csvutil.NewDecoder(csvReader, doHeader...)
 	if err != nil {
-		return records, err
+		return web.NewParseError(ShortName, err)
 	}
 
-Loop:
 	for {
 		var c Record
+
 		err = dec.Decode(&c)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
 
-		switch {
-		case errors.Is(err, io.EOF):
-			break Loop
-		case err == nil:
-			var pcn netip.Prefix
-			if c.PrefixText == "" {
-				continue
-			}
-			pcn, err = netip.ParsePrefix(extractNetFromString(c.PrefixText))
-			if err != nil {
-				return records, err
-			}
-			c.Prefix = pcn
-			records = append(records, c)
-		default:
-			return records, err
+		if err != nil {
+			return web.NewParseError(ShortName, err)
 		}
-	}
 
-	return records, nil
+		if c.PrefixText == "" {
+			continue
+		}
+
+		c.Prefix, err = netip.ParsePrefix(extractNetFromString(c.PrefixText))
+		if err != nil {
+			return web.NewParseError(ShortName, err)
+		}
+
+		if err = fn(c); err != nil {
+			return err
+		}
+	}
 }
 
 // Record holds prefix, alpha2code, region, city and postal_code.
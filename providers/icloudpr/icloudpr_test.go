@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"net/netip"
 	"net/url"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/jonhadfield/ip-fetcher/internal/web"
@@ -85,3 +87,26 @@ func TestFetch(t *testing.T) {
 	require.Equal(t, "London", doc.Records[0].City)
 	require.Equal(t, netip.MustParsePrefix("172.224.224.0/27"), doc.Records[0].Prefix)
 }
+
+func TestParseStream(t *testing.T) {
+	f, err := os.Open("testdata/egress-ip-ranges.csv")
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	var records []icloudpr.Record
+
+	err = icloudpr.ParseStream(f, func(r icloudpr.Record) error {
+		records = append(records, r)
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, records)
+	require.Equal(t, netip.MustParsePrefix("172.224.224.0/27"), records[0].Prefix)
+
+	err = icloudpr.ParseStream(strings.NewReader("not-a-prefix,GB,GB-EN,London,\n"), func(icloudpr.Record) error {
+		return nil
+	})
+	require.ErrorIs(t, err, web.ErrParse)
+}
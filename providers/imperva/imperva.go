@@ -2,7 +2,6 @@ package imperva
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/netip"
 	"time"
@@ -31,7 +30,7 @@ type Imperva struct {
 func New() Imperva {
 	return Imperva{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -54,7 +53,7 @@ func (i *Imperva) FetchData() ([]byte, http.Header, int, error) {
 	}
 
 	if status >= http.StatusBadRequest {
-		return nil, headers, status, fmt.Errorf("failed to download imperva prefixes. http status code: %d", status)
+		return nil, headers, status, web.NewStatusError(ShortName, i.DownloadURL, status)
 	}
 
 	return data, headers, status, nil
@@ -66,7 +65,12 @@ func (i *Imperva) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 
-	return ProcessData(data)
+	doc, err := ProcessData(data)
+	if err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
 }
 
 type rawDoc struct {
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/csv"
 	"errors"
-	"fmt"
 	"io"
 	"log/slog"
 	"net"
@@ -71,7 +70,7 @@ type Linode struct {
 func New() Linode {
 	return Linode{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -90,7 +89,7 @@ func (a *Linode) FetchData() ([]byte, http.Header, int, error) {
 
 	data, headers, status, err = web.Request(a.Client, a.DownloadURL, http.MethodGet, nil, nil, a.Timeout)
 	if status >= http.StatusBadRequest {
-		return nil, nil, status, fmt.Errorf("failed to download prefixes. http status code: %d", status)
+		return nil, nil, status, web.NewStatusError(ShortName, a.DownloadURL, status)
 	}
 
 	return data, headers, status, err
@@ -110,7 +109,7 @@ func (a *Linode) Fetch() (Doc, error) {
 
 	records, err := Parse(data)
 	if err != nil {
-		return Doc{}, err
+		return Doc{}, web.NewParseError(ShortName, err)
 	}
 
 	var doc Doc
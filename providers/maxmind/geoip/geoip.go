@@ -41,7 +41,7 @@ func New() GeoIP {
 	configureLogrus()
 
 	return GeoIP{
-		Client: web.NewHTTPClientWithLogger(),
+		Client: web.NewProviderHTTPClient(ShortName),
 	}
 }
 
@@ -59,6 +59,7 @@ type GeoIP struct {
 }
 
 const (
+	ShortName                             = "geoip"
 	DownloadScheme                        = "https"
 	DownloadHost                          = "download.maxmind.com"
 	DownloadPath                          = "/app/geoip_download"
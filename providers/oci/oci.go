@@ -22,7 +22,7 @@ const (
 func New() OCI {
 	return OCI{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -50,7 +50,7 @@ type Region struct {
 type RawRegion struct {
 	Region string    `json:"region"`
 	CIDRS  []RawCIDR `json:"cidrs"`
-	Tags   []string  `json:"tags"`
+	Tags   []string  `json:"tags,omitempty"`
 }
 
 type RawDoc struct {
@@ -107,7 +107,16 @@ func (ora *OCI) FetchData() ([]byte, http.Header, int, error) {
 		ora.DownloadURL = DownloadURL
 	}
 
-	return web.Request(ora.Client, ora.DownloadURL, http.MethodGet, nil, nil, ora.Timeout)
+	data, headers, status, err := web.Request(ora.Client, ora.DownloadURL, http.MethodGet, nil, nil, ora.Timeout)
+	if err != nil {
+		return nil, headers, status, err
+	}
+
+	if status >= http.StatusBadRequest {
+		return nil, headers, status, web.NewStatusError(ShortName, ora.DownloadURL, status)
+	}
+
+	return data, headers, status, nil
 }
 
 func (ora *OCI) Fetch() (Doc, error) {
@@ -116,9 +125,11 @@ func (ora *OCI) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 	var doc Doc
-	err = json.Unmarshal(data, &doc)
+	if err = json.Unmarshal(data, &doc); err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
 
-	return doc, err
+	return doc, nil
 }
 
 type Doc struct {
@@ -2,7 +2,6 @@ package stripe
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/netip"
 	"sort"
@@ -35,7 +34,7 @@ func New() Stripe {
 	return Stripe{
 		WebhooksURL: WebhooksURL,
 		APIURL:      APIURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -60,7 +59,7 @@ func (s *Stripe) fetchList(url string) ([]string, http.Header, int, error) {
 	}
 
 	if status >= http.StatusBadRequest {
-		return nil, headers, status, fmt.Errorf("failed to download stripe prefixes from %s. http status code: %d", url, status)
+		return nil, headers, status, web.NewStatusError(ShortName, url, status)
 	}
 
 	return collectStrings(data), headers, status, nil
@@ -120,7 +119,12 @@ func (s *Stripe) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 
-	return ProcessData(data)
+	doc, err := ProcessData(data)
+	if err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
 }
 
 func ProcessData(data []byte) (Doc, error) {
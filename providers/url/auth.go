@@ -0,0 +1,41 @@
+package url
+
+import (
+	"encoding/base64"
+	"net/http"
+
+	"github.com/jonhadfield/ip-fetcher/internal/web"
+)
+
+// SetBearerToken authenticates the request with the given bearer token.
+func (r *Request) SetBearerToken(token string) {
+	r.SetSecretHeader("Authorization", "Bearer "+token)
+	r.Secrets = append(r.Secrets, token)
+}
+
+// SetBasicAuth authenticates the request with HTTP basic authentication.
+func (r *Request) SetBasicAuth(username, password string) {
+	encoded := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+
+	r.SetSecretHeader("Authorization", "Basic "+encoded)
+	r.Secrets = append(r.Secrets, password, encoded)
+}
+
+// SetSecretHeader sets a header, such as an API key, whose value must not be revealed.
+func (r *Request) SetSecretHeader(name, value string) {
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+
+	r.Header.Set(name, value)
+	r.Secrets = append(r.Secrets, value)
+}
+
+// displayURL returns the url with any password and secrets masked, for use in logs and errors.
+func (r *Request) displayURL() string {
+	if r.URL == nil {
+		return ""
+	}
+
+	return web.MaskSecrets(r.URL.Redacted(), r.Secrets)
+}
@@ -0,0 +1,84 @@
+package url_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/jonhadfield/ip-fetcher/internal/web"
+	mUrl "github.com/jonhadfield/ip-fetcher/providers/url"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestAuthentication(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+
+		switch {
+		case r.Header.Get("Authorization") == "Bearer bearer-secret",
+			ok && user == "feed" && pass == "basic-secret",
+			r.Header.Get("X-Api-Key") == "key-secret":
+			_, _ = w.Write([]byte("9.9.9.0/24\n"))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL + "/ips.txt")
+	require.NoError(t, err)
+
+	bearer := mUrl.Request{URL: u, Method: http.MethodGet}
+	bearer.SetBearerToken("bearer-secret")
+
+	basic := mUrl.Request{URL: u, Method: http.MethodGet}
+	basic.SetBasicAuth("feed", "basic-secret")
+
+	apiKey := mUrl.Request{URL: u, Method: http.MethodGet}
+	apiKey.SetSecretHeader("X-Api-Key", "key-secret")
+
+	c := mUrl.New()
+
+	for _, req := range []mUrl.Request{bearer, basic, apiKey} {
+		prefixes, fetchErr := c.FetchPrefixes([]mUrl.Request{req})
+		require.NoError(t, fetchErr)
+		require.Len(t, prefixes, 1)
+	}
+
+	_, err = c.Get([]mUrl.Request{{URL: u, Method: http.MethodGet}})
+	require.ErrorIs(t, err, web.ErrAuth)
+}
+
+func TestRequestSecretsMasked(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL + "/ips.txt?token=query-secret")
+	require.NoError(t, err)
+
+	u.User = url.UserPassword("feed", "url-secret")
+
+	req := mUrl.Request{URL: u, Method: http.MethodGet, Secrets: []string{"query-secret"}}
+	req.SetBearerToken("bearer-secret")
+
+	_, err = mUrl.New().Get([]mUrl.Request{req})
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "query-secret")
+	require.NotContains(t, err.Error(), "url-secret")
+	require.NotContains(t, err.Error(), "bearer-secret")
+	require.Contains(t, err.Error(), "403")
+
+	// transport errors include the url, so must be masked too
+	unreachable, err := url.Parse("http://127.0.0.1:1/ips.txt?token=query-secret")
+	require.NoError(t, err)
+
+	c := mUrl.New()
+	c.HTTPClient.RetryMax = 0
+
+	_, err = c.Get([]mUrl.Request{{URL: unreachable, Method: http.MethodGet, Secrets: []string{"query-secret"}}})
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "query-secret")
+}
@@ -0,0 +1,553 @@
+package url
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jonhadfield/ip-fetcher/internal/pflog"
+	"github.com/jonhadfield/ip-fetcher/internal/web"
+	"github.com/sirupsen/logrus"
+)
+
+// Format is the format of a source's content.
+type Format string
+
+const (
+	// FormatText is newline separated text with a prefix at the start of each line.
+	// It is used when no format is specified.
+	FormatText Format = "text"
+	// FormatAuto detects the format from the content.
+	FormatAuto Format = "auto"
+	// FormatJSON is a JSON document. The selector is a JSONPath-like expression, e.g.
+	// $.prefixes[*].ip_prefix, locating the prefixes. Without one, every string is checked.
+	FormatJSON Format = "json"
+	// FormatCSV is comma separated values. The selector is the name, or zero based index,
+	// of the column holding the prefixes. Without one, the first column of prefixes is used.
+	FormatCSV Format = "csv"
+	// FormatGeofeed is an RFC 8805 geofeed, a CSV without a header starting with the prefix.
+	FormatGeofeed Format = "geofeed"
+)
+
+// Formats lists the supported formats.
+var Formats = []Format{FormatAuto, FormatText, FormatJSON, FormatCSV, FormatGeofeed} //nolint:gochecknoglobals
+
+// detectLength is how much content is inspected to detect its format.
+const detectLength = 4096
+
+// ParseFormat returns the Format with the given name. An empty name is FormatText.
+func ParseFormat(s string) (Format, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return FormatText, nil
+	}
+
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported format: %s", s)
+}
+
+// ReadPrefixesFromData reads the prefixes from data in the given format.
+func ReadPrefixesFromData(data []byte, format Format, selector string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+
+	err := ReadPrefixes(bytes.NewReader(data), format, selector, func(p netip.Prefix) error {
+		prefixes = append(prefixes, p)
+
+		return nil
+	})
+
+	return prefixes, err
+}
+
+// ReadStats counts what was read from a source.
+type ReadStats struct {
+	// Prefixes is the number of prefixes read, including duplicates.
+	Prefixes int64 `json:"prefixes"`
+	// Invalid is the number of lines, records or selected values that are not prefixes.
+	Invalid int64 `json:"invalid"`
+	// Commented is the number of commented lines or records.
+	Commented int64 `json:"commented"`
+	// Errors describes why values were invalid, up to MaxLineErrors of them.
+	Errors []LineError `json:"errors,omitempty"`
+}
+
+// MaxLineErrors is the most invalid values ReadStats describes.
+const MaxLineErrors = 100
+
+// LineError describes a value that could not be parsed as a prefix.
+type LineError struct {
+	// Line is the line the value was read from, or zero when not known.
+	Line int `json:"line,omitempty"`
+	// Text is the value read.
+	Text string `json:"text"`
+	// Reason is why the value is invalid.
+	Reason string `json:"error"`
+}
+
+func (e LineError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%q: %s", e.Text, e.Reason)
+	}
+
+	return fmt.Sprintf("line %d: %q: %s", e.Line, e.Text, e.Reason)
+}
+
+// invalid counts an invalid value, describing it while fewer than MaxLineErrors have been.
+func (rs *ReadStats) invalid(line int, text string, err error) {
+	rs.Invalid++
+
+	if len(rs.Errors) < MaxLineErrors {
+		rs.Errors = append(rs.Errors, LineError{Line: line, Text: text, Reason: err.Error()})
+	}
+}
+
+// ReadPrefixes reads prefixes from r in the given format, calling fn for each. Values that are
+// not prefixes are skipped. Bare addresses are treated as single address prefixes and ranges,
+// netmasks and wildcards are read as described by ParseNotation. Errors
+// returned by fn stop reading and are returned unchanged.
+func ReadPrefixes(r io.Reader, format Format, selector string, fn func(netip.Prefix) error) error {
+	_, err := ReadPrefixesWithStats(r, format, selector, fn)
+
+	return err
+}
+
+// ReadPrefixesWithStats is ReadPrefixes that also returns counts of what was read.
+func ReadPrefixesWithStats(r io.Reader, format Format, selector string, fn func(netip.Prefix) error) (ReadStats, error) {
+	if format == FormatAuto {
+		br := bufio.NewReaderSize(r, detectLength)
+		head, _ := br.Peek(detectLength)
+
+		format, r = DetectFormat(head), br
+
+		logrus.Debugf("%s | detected %s format", pflog.GetFunctionName(), format)
+	}
+
+	switch format {
+	case "", FormatText:
+		return readRawPrefixes(r, fn)
+	case FormatJSON:
+		return readJSONPrefixes(r, selector, fn)
+	case FormatCSV:
+		return readCSVPrefixes(r, selector, false, fn)
+	case FormatGeofeed:
+		return readCSVPrefixes(r, "0", true, fn)
+	default:
+		return ReadStats{}, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// DetectFormat returns the format of content starting with head.
+func DetectFormat(head []byte) Format {
+	head = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimSpace(head)
+
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return FormatJSON
+	}
+
+	for line := range strings.Lines(string(trimmed)) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		first, _, found := strings.Cut(line, ",")
+		if !found {
+			return FormatText
+		}
+
+		// geofeeds have no header and start every line with a prefix
+		if _, ok := parsePrefixValue(first); ok {
+			return FormatGeofeed
+		}
+
+		return FormatCSV
+	}
+
+	return FormatText
+}
+
+// parsePrefixValue parses a prefix or, as a single address prefix, a bare address.
+func parsePrefixValue(s string) (netip.Prefix, bool) {
+	s = strings.TrimSpace(s)
+
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+
+		return p, err == nil
+	}
+
+	a, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+
+	return netip.PrefixFrom(a, a.BitLen()), true
+}
+
+// prefixCounter calls fn for each prefix in the values added, counting those that are invalid.
+type prefixCounter struct {
+	fn func(netip.Prefix) error
+	ReadStats
+}
+
+// add reads the prefixes from s, found on the given line or zero when not known.
+func (pc *prefixCounter) add(line int, s string) error {
+	prefixes, err := ParseNotation(s)
+	if err != nil {
+		pc.invalid(line, strings.TrimSpace(s), err)
+
+		return nil
+	}
+
+	for _, p := range prefixes {
+		pc.Prefixes++
+
+		if err = pc.fn(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readJSONPrefixes(r io.Reader, selector string, fn func(netip.Prefix) error) (ReadStats, error) {
+	steps, err := parseJSONPath(selector)
+	if err != nil {
+		return ReadStats{}, err
+	}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var doc any
+	if err = dec.Decode(&doc); err != nil {
+		return ReadStats{}, web.NewParseError("url", err)
+	}
+
+	nodes := []any{doc}
+	for _, s := range steps {
+		nodes = s.apply(nodes)
+	}
+
+	pc := &prefixCounter{fn: fn}
+
+	// without a selector every string is a candidate, so only prefixes are of interest
+	if len(steps) == 0 {
+		if err = walkJSONStrings(doc, func(s string) error {
+			if p, ok := parsePrefixValue(s); ok {
+				pc.Prefixes++
+
+				return fn(p)
+			}
+
+			return nil
+		}); err != nil {
+			return pc.ReadStats, err
+		}
+	} else {
+		for _, n := range nodes {
+			if err = walkJSONStrings(n, func(s string) error {
+				return pc.add(0, s)
+			}); err != nil {
+				return pc.ReadStats, err
+			}
+		}
+	}
+
+	logrus.Debugf("%s | loaded %d prefixes from json with %d invalid", pflog.GetFunctionName(),
+		pc.Prefixes, pc.Invalid)
+
+	return pc.ReadStats, nil
+}
+
+// walkJSONStrings calls fn for each string in v, descending into arrays and objects.
+// Object members are visited in key order so that results are deterministic.
+func walkJSONStrings(v any, fn func(string) error) error {
+	switch t := v.(type) {
+	case string:
+		return fn(t)
+	case []any:
+		for _, e := range t {
+			if err := walkJSONStrings(e, fn); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		for _, k := range slices.Sorted(maps.Keys(t)) {
+			if err := walkJSONStrings(t[k], fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// jsonStep is a single step of a JSON path.
+type jsonStep struct {
+	field     string
+	index     int
+	wildcard  bool
+	recursive bool
+	isIndex   bool
+}
+
+func (s jsonStep) apply(nodes []any) []any {
+	if s.recursive {
+		var all []any
+		for _, n := range nodes {
+			all = appendDescendants(all, n)
+		}
+
+		nodes = all
+	}
+
+	var res []any
+
+	for _, n := range nodes {
+		switch t := n.(type) {
+		case map[string]any:
+			switch {
+			case s.wildcard:
+				for _, k := range slices.Sorted(maps.Keys(t)) {
+					res = append(res, t[k])
+				}
+			case !s.isIndex:
+				if v, ok := t[s.field]; ok {
+					res = append(res, v)
+				}
+			}
+		case []any:
+			switch {
+			case s.wildcard:
+				res = append(res, t...)
+			case s.isIndex && s.index >= 0 && s.index < len(t):
+				res = append(res, t[s.index])
+			}
+		}
+	}
+
+	return res
+}
+
+// appendDescendants appends v and every value nested within it.
+func appendDescendants(res []any, v any) []any {
+	res = append(res, v)
+
+	switch t := v.(type) {
+	case []any:
+		for _, e := range t {
+			res = appendDescendants(res, e)
+		}
+	case map[string]any:
+		for _, k := range slices.Sorted(maps.Keys(t)) {
+			res = appendDescendants(res, t[k])
+		}
+	}
+
+	return res
+}
+
+// parseJSONPath parses a JSONPath-like selector, such as $.prefixes[*].ip_prefix, $..ip_prefix
+// or the jq style .prefixes[].ip_prefix. Fields may also be quoted, e.g. $['ip ranges'].
+func parseJSONPath(selector string) ([]jsonStep, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(selector), "$")
+
+	var steps []jsonStep
+
+	invalid := func() error {
+		return fmt.Errorf("invalid json path: %s", selector)
+	}
+
+	for s != "" {
+		var step jsonStep
+
+		switch {
+		case strings.HasPrefix(s, ".."):
+			step.recursive = true
+			s = s[2:]
+		case strings.HasPrefix(s, "."):
+			s = s[1:]
+		}
+
+		switch {
+		case strings.HasPrefix(s, "["):
+			end := strings.Index(s, "]")
+			if end == -1 {
+				return nil, invalid()
+			}
+
+			inner := strings.TrimSpace(s[1:end])
+			s = s[end+1:]
+
+			switch {
+			case inner == "" || inner == "*":
+				step.wildcard = true
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				step.field = inner[1 : len(inner)-1]
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, invalid()
+				}
+
+				step.index, step.isIndex = i, true
+			}
+		case strings.HasPrefix(s, "*"):
+			step.wildcard = true
+			s = s[1:]
+		default:
+			end := strings.IndexAny(s, ".[")
+			if end == -1 {
+				end = len(s)
+			}
+
+			step.field = s[:end]
+			s = s[end:]
+
+			if step.field == "" {
+				// a lone '.' selects the root
+				if !step.recursive && s == "" {
+					continue
+				}
+
+				return nil, invalid()
+			}
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// readCSVPrefixes reads prefixes from the given column, a name or zero based index. Without
+// a column, the first column containing a prefix is used. Records before the first prefix,
+// such as a header, are skipped.
+func readCSVPrefixes(r io.Reader, column string, noHeader bool, fn func(netip.Prefix) error) (ReadStats, error) {
+	// comments are handled when reading so that they can be counted
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+
+	column = strings.TrimSpace(column)
+
+	idx := -1
+
+	if column != "" {
+		i, err := strconv.Atoi(column)
+		if err == nil && i < 0 {
+			return ReadStats{}, fmt.Errorf("invalid csv column: %s", column)
+		}
+
+		if err == nil {
+			idx = i
+		} else if noHeader {
+			return ReadStats{}, fmt.Errorf("csv column must be an index: %s", column)
+		}
+	}
+
+	pc := &prefixCounter{fn: fn}
+
+	var (
+		records int64
+		found   bool
+	)
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return pc.ReadStats, web.NewParseError("url", err)
+		}
+
+		records++
+
+		if len(record) > 0 && strings.HasPrefix(strings.TrimSpace(record[0]), "#") {
+			pc.Commented++
+
+			continue
+		}
+
+		if !found {
+			var skip bool
+
+			idx, found, skip, err = csvColumn(record, column, idx, noHeader)
+			if err != nil {
+				return pc.ReadStats, err
+			}
+
+			if skip {
+				continue
+			}
+		}
+
+		line, _ := cr.FieldPos(0)
+
+		if err = pc.add(line, fieldAt(record, idx)); err != nil {
+			return pc.ReadStats, err
+		}
+	}
+
+	logrus.Debugf("%s | loaded %d prefixes from %d csv records with %d commented and %d invalid",
+		pflog.GetFunctionName(), pc.Prefixes, records, pc.Commented, pc.Invalid)
+
+	return pc.ReadStats, nil
+}
+
+// csvColumn returns the index of the prefix column from the first record read and whether
+// that record should be skipped as a header. A named column is found in the header, an indexed
+// column is known and an unspecified column is the first holding a prefix. Records read before
+// an unspecified column is found are skipped.
+func csvColumn(record []string, column string, idx int, noHeader bool) (int, bool, bool, error) {
+	switch {
+	case idx >= 0:
+		_, ok := parsePrefixValue(fieldAt(record, idx))
+
+		return idx, true, !ok && !noHeader, nil
+	case column != "":
+		for i, f := range record {
+			if strings.EqualFold(strings.TrimSpace(f), column) {
+				return i, true, true, nil
+			}
+		}
+
+		return -1, false, false, fmt.Errorf("csv column not found: %s", column)
+	default:
+		for i, f := range record {
+			if _, ok := parsePrefixValue(f); ok {
+				return i, true, false, nil
+			}
+		}
+
+		return -1, false, true, nil
+	}
+}
+
+func fieldAt(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+
+	return record[i]
+}
@@ -0,0 +1,135 @@
+package url_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jonhadfield/ip-fetcher/internal/web"
+	mUrl "github.com/jonhadfield/ip-fetcher/providers/url"
+	"github.com/stretchr/testify/require"
+)
+
+func prefixStrings(prefixes []netip.Prefix) []string {
+	res := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		res[i] = p.String()
+	}
+
+	return res
+}
+
+func TestReadPrefixesJSON(t *testing.T) {
+	data, err := os.ReadFile("testdata/feed.json")
+	require.NoError(t, err)
+
+	tests := []struct {
+		selector string
+		want     []string
+	}{
+		{"$.prefixes[*].ip_prefix", []string{"192.0.2.0/24", "198.51.100.0/24"}},
+		{".prefixes[].ip_prefix", []string{"192.0.2.0/24", "198.51.100.0/24"}},
+		{"$.prefixes[1].ip_prefix", []string{"198.51.100.0/24"}},
+		{"$..ipv6_prefix", []string{"2001:db8::/32"}},
+		{"$['ipv6_prefixes'][0]['ipv6_prefix']", []string{"2001:db8::/32"}},
+		{"", []string{"2001:db8::/32", "192.0.2.0/24", "198.51.100.0/24"}},
+		{"$.missing[*]", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			prefixes, readErr := mUrl.ReadPrefixesFromData(data, mUrl.FormatJSON, tt.selector)
+			require.NoError(t, readErr)
+			require.Equal(t, tt.want, prefixStrings(prefixes))
+		})
+	}
+
+	_, err = mUrl.ReadPrefixesFromData(data, mUrl.FormatJSON, "$.prefixes[x]")
+	require.ErrorContains(t, err, "invalid json path")
+
+	_, err = mUrl.ReadPrefixesFromData([]byte("{"), mUrl.FormatJSON, "")
+	require.ErrorIs(t, err, web.ErrParse)
+}
+
+func TestReadPrefixesCSV(t *testing.T) {
+	data := []byte("name,cidr,region\nweb,192.0.2.0/24,eu\n# comment\napi,2001:db8::1,us\nbad,nonsense,us\n")
+
+	for _, column := range []string{"cidr", "CIDR", "1", ""} {
+		prefixes, err := mUrl.ReadPrefixesFromData(data, mUrl.FormatCSV, column)
+		require.NoError(t, err, column)
+		require.Equal(t, []string{"192.0.2.0/24", "2001:db8::1/128"}, prefixStrings(prefixes), column)
+	}
+
+	_, err := mUrl.ReadPrefixesFromData(data, mUrl.FormatCSV, "missing")
+	require.ErrorContains(t, err, "csv column not found")
+}
+
+func TestReadPrefixesGeofeed(t *testing.T) {
+	data, err := os.ReadFile("testdata/geofeed.csv")
+	require.NoError(t, err)
+
+	want := []string{"192.0.2.0/24", "2001:db8::/32", "198.51.100.7/32"}
+
+	prefixes, err := mUrl.ReadPrefixesFromData(data, mUrl.FormatGeofeed, "")
+	require.NoError(t, err)
+	require.Equal(t, want, prefixStrings(prefixes))
+
+	prefixes, err = mUrl.ReadPrefixesFromData(data, mUrl.FormatAuto, "")
+	require.NoError(t, err)
+	require.Equal(t, want, prefixStrings(prefixes))
+}
+
+func TestDetectFormat(t *testing.T) {
+	require.Equal(t, mUrl.FormatJSON, mUrl.DetectFormat([]byte("\xef\xbb\xbf  [\"192.0.2.0/24\"]")))
+	require.Equal(t, mUrl.FormatGeofeed, mUrl.DetectFormat([]byte("# geofeed\n192.0.2.0/24,US,,,\n")))
+	require.Equal(t, mUrl.FormatCSV, mUrl.DetectFormat([]byte("cidr,region\n192.0.2.0/24,eu\n")))
+	require.Equal(t, mUrl.FormatText, mUrl.DetectFormat([]byte("# list\n192.0.2.0/24\n")))
+	require.Equal(t, mUrl.FormatText, mUrl.DetectFormat(nil))
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := mUrl.ParseFormat("")
+	require.NoError(t, err)
+	require.Equal(t, mUrl.FormatText, f)
+
+	f, err = mUrl.ParseFormat(" GeoFeed ")
+	require.NoError(t, err)
+	require.Equal(t, mUrl.FormatGeofeed, f)
+
+	_, err = mUrl.ParseFormat("xml")
+	require.Error(t, err)
+}
+
+func TestFetchPrefixesWithFormat(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"ranges":[{"cidr":"192.0.2.0/24"},{"cidr":"198.51.100.0/24"}]}`))
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	prefixes, err := mUrl.New().FetchPrefixes([]mUrl.Request{{
+		URL:      u,
+		Method:   http.MethodGet,
+		Format:   mUrl.FormatJSON,
+		Selector: "$.ranges[*].cidr",
+	}})
+	require.NoError(t, err)
+	require.Len(t, prefixes, 2)
+
+	var streamed []string
+
+	err = mUrl.New().StreamPrefixes(mUrl.Request{URL: u, Method: http.MethodGet, Format: mUrl.FormatAuto},
+		func(p netip.Prefix) error {
+			streamed = append(streamed, p.String())
+
+			return nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, "192.0.2.0/24,198.51.100.0/24", strings.Join(streamed, ","))
+}
@@ -0,0 +1,95 @@
+package url
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Stdin is the source that reads prefixes from standard input.
+	Stdin = "-"
+	// stdinName identifies standard input as the origin of its prefixes.
+	stdinName = "stdin"
+)
+
+// IsLocal reports whether u refers to a local source: a file:// url, a path or Stdin.
+func IsLocal(u *url.URL) bool {
+	return u != nil && (u.Scheme == "" || u.Scheme == "file")
+}
+
+// ParseSource parses a source given as a url, a local path or Stdin.
+func ParseSource(s string) (*url.URL, error) {
+	// local paths are used as given as they may contain characters, such as '%' or '#',
+	// with special meaning in urls
+	if s == Stdin || !strings.Contains(s, "://") {
+		return &url.URL{Path: s}, nil
+	}
+
+	return url.Parse(s)
+}
+
+// openLocal opens a local source, returning its content and the name identifying it as the origin of its prefixes.
+func (c *Client) openLocal(u *url.URL) (io.ReadCloser, string, error) {
+	if u.Scheme == "" && u.Path == Stdin {
+		r := c.Stdin
+		if r == nil {
+			r = os.Stdin
+		}
+
+		return io.NopCloser(r), stdinName, nil
+	}
+
+	path := u.Path
+
+	if u.Scheme == "file" {
+		if u.Host != "" && u.Host != "localhost" {
+			return nil, "", fmt.Errorf("file url must refer to a local file: %s", u.Redacted())
+		}
+
+		path = filepath.FromSlash(u.Path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return f, localName(u), nil
+}
+
+// localName identifies a local source as the origin of its prefixes.
+func localName(u *url.URL) string {
+	switch {
+	case u.Scheme == "file":
+		return u.String()
+	case u.Path == Stdin:
+		return stdinName
+	default:
+		return u.Path
+	}
+}
+
+// getLocal reads a local source in full.
+func (c *Client) getLocal(req Request) (URLResponse, error) {
+	r, name, err := c.openLocal(req.URL)
+	if err != nil {
+		return URLResponse{}, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return URLResponse{}, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	return URLResponse{
+		url:      name,
+		Data:     data,
+		format:   req.Format,
+		selector: req.Selector,
+	}, nil
+}
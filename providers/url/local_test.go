@@ -0,0 +1,132 @@
+package url_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jonhadfield/ip-fetcher/internal/web"
+	mUrl "github.com/jonhadfield/ip-fetcher/providers/url"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchPrefixesLocalAndRemote(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("192.0.2.0/24\n198.51.100.0/24\n"))
+	}))
+	defer ts.Close()
+
+	tDir := t.TempDir()
+	path := filepath.Join(tDir, "allow.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# local\n192.0.2.0/24\n203.0.113.0/24\n"), 0o600))
+
+	fileURL := "file://" + filepath.ToSlash(path)
+
+	var requests []mUrl.Request
+
+	for _, s := range []string{ts.URL, path, fileURL, mUrl.Stdin} {
+		u, err := mUrl.ParseSource(s)
+		require.NoError(t, err)
+
+		requests = append(requests, mUrl.Request{URL: u, Method: http.MethodGet})
+	}
+
+	c := mUrl.New(mUrl.WithStdin(strings.NewReader("2001:db8::/32\n192.0.2.0/24\n")))
+
+	prefixes, err := c.FetchPrefixes(requests)
+	require.NoError(t, err)
+	require.Len(t, prefixes, 4)
+
+	require.ElementsMatch(t, []string{ts.URL, path, fileURL, "stdin"}, prefixes[netip.MustParsePrefix("192.0.2.0/24")])
+	require.ElementsMatch(t, []string{ts.URL}, prefixes[netip.MustParsePrefix("198.51.100.0/24")])
+	require.ElementsMatch(t, []string{path, fileURL}, prefixes[netip.MustParsePrefix("203.0.113.0/24")])
+	require.ElementsMatch(t, []string{"stdin"}, prefixes[netip.MustParsePrefix("2001:db8::/32")])
+}
+
+func TestLocalSourceErrors(t *testing.T) {
+	c := mUrl.New()
+
+	missing, err := mUrl.ParseSource(filepath.Join(t.TempDir(), "missing.txt"))
+	require.NoError(t, err)
+
+	_, err = c.Get([]mUrl.Request{{URL: missing, Method: http.MethodGet}})
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	remoteFile, err := mUrl.ParseSource("file://example.com/ips.txt")
+	require.NoError(t, err)
+
+	_, err = c.Get([]mUrl.Request{{URL: remoteFile, Method: http.MethodGet}})
+	require.ErrorContains(t, err, "must refer to a local file")
+}
+
+func TestParseSource(t *testing.T) {
+	u, err := mUrl.ParseSource("-")
+	require.NoError(t, err)
+	require.True(t, mUrl.IsLocal(u))
+	require.Equal(t, "-", u.Path)
+
+	u, err = mUrl.ParseSource("lists/100%.txt")
+	require.NoError(t, err)
+	require.True(t, mUrl.IsLocal(u))
+	require.Equal(t, "lists/100%.txt", u.Path)
+
+	u, err = mUrl.ParseSource("https://www.example.com/ips.txt")
+	require.NoError(t, err)
+	require.False(t, mUrl.IsLocal(u))
+}
+
+func TestFetchSources(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("# remote\n192.0.2.0/24\nnonsense\n198.51.100.0/24\n192.0.2.0/24\n"))
+	}))
+	defer ts.Close()
+
+	var requests []mUrl.Request
+
+	for _, s := range []string{ts.URL + "/ips.txt", ts.URL + "/missing", mUrl.Stdin} {
+		u, err := mUrl.ParseSource(s)
+		require.NoError(t, err)
+
+		requests = append(requests, mUrl.Request{URL: u, Method: http.MethodGet})
+	}
+
+	c := mUrl.New(mUrl.WithStdin(strings.NewReader("198.51.100.0/24\n")))
+	c.HTTPClient.RetryMax = 0
+
+	result := c.FetchSources(requests)
+	require.Len(t, result.Sources, 3)
+
+	remote := result.Sources[0]
+	require.Equal(t, ts.URL+"/ips.txt", remote.Source)
+	require.NoError(t, remote.Err)
+	require.Equal(t, mUrl.ReadStats{
+		Prefixes:  3,
+		Invalid:   1,
+		Commented: 1,
+		Errors:    []mUrl.LineError{{Line: 3, Text: "nonsense", Reason: remote.Errors[0].Reason}},
+	}, remote.ReadStats)
+
+	missing := result.Sources[1]
+	require.ErrorIs(t, missing.Err, web.ErrUnexpectedStatus)
+	require.Contains(t, missing.Error, "404")
+
+	require.Equal(t, "stdin", result.Sources[2].Source)
+	require.Len(t, result.Failed(), 1)
+
+	require.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("192.0.2.0/24"),
+		netip.MustParsePrefix("198.51.100.0/24"),
+	}, result.SortedPrefixes())
+	require.Equal(t, []string{ts.URL + "/ips.txt"}, result.Prefixes[netip.MustParsePrefix("192.0.2.0/24")])
+	require.Equal(t, []string{ts.URL + "/ips.txt", "stdin"}, result.Prefixes[netip.MustParsePrefix("198.51.100.0/24")])
+}
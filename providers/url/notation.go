@@ -0,0 +1,220 @@
+package url
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"net/netip"
+	"strings"
+)
+
+var errNoAddress = errors.New("no address found") //nolint:gochecknoglobals
+
+// ParseNotation parses an address or set of addresses written in any of the notations found in
+// published lists, returning the minimal set of prefixes covering them:
+//
+//	192.0.2.0/24                  prefix
+//	192.0.2.1, 2001:db8::1        address, as a /32 or /128 prefix
+//	192.0.2.4-192.0.2.80          range, also written with spaces around the '-'
+//	192.0.2.0 255.255.255.0       address and dotted netmask, also written with a '/'
+//	192.0.2.0 0.0.0.255           address and wildcard (inverse) mask
+//	192.0.2.*, 10.*.*.*           wildcard octets
+//
+// Anything following the notation, such as a comment, is ignored.
+func ParseNotation(s string) ([]netip.Prefix, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, errNoAddress
+	}
+
+	first := fields[0]
+
+	switch {
+	case len(fields) >= 3 && fields[1] == "-" && isAddr(fields[2]):
+		return parseRange(first, fields[2])
+	case strings.HasSuffix(first, "-") && len(fields) >= 2 && isAddr(fields[1]):
+		return parseRange(strings.TrimSuffix(first, "-"), fields[1])
+	case strings.Contains(first, "-"):
+		start, end, _ := strings.Cut(first, "-")
+
+		return parseRange(start, end)
+	case strings.Contains(first, "*"):
+		p, err := parseWildcardOctets(first)
+
+		return []netip.Prefix{p}, err
+	}
+
+	if addr, mask, found := strings.Cut(first, "/"); found && strings.Contains(mask, ".") {
+		p, err := parseMasked(addr, mask)
+
+		return []netip.Prefix{p}, err
+	}
+
+	if len(fields) >= 2 && !strings.Contains(first, "/") && isDottedQuad(fields[1]) {
+		p, err := parseMasked(first, fields[1])
+
+		return []netip.Prefix{p}, err
+	}
+
+	p, err := parseSingle(first)
+	if err != nil {
+		return nil, err
+	}
+
+	return []netip.Prefix{p}, nil
+}
+
+// parseSingle parses a prefix or an address, as a single address prefix.
+func parseSingle(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		return netip.ParsePrefix(s)
+	}
+
+	a, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	return netip.PrefixFrom(a, a.BitLen()), nil
+}
+
+func isAddr(s string) bool {
+	_, err := netip.ParseAddr(s)
+
+	return err == nil
+}
+
+func isDottedQuad(s string) bool {
+	a, err := netip.ParseAddr(s)
+
+	return err == nil && a.Is4()
+}
+
+// parseMasked parses an IPv4 address with a dotted netmask or wildcard mask.
+func parseMasked(addr, mask string) (netip.Prefix, error) {
+	a, err := netip.ParseAddr(addr)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	m, err := netip.ParseAddr(mask)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	if !a.Is4() || !m.Is4() {
+		return netip.Prefix{}, fmt.Errorf("netmask notation is only valid for IPv4: %s %s", addr, mask)
+	}
+
+	mb := m.As4()
+	v := uint32(mb[0])<<24 | uint32(mb[1])<<16 | uint32(mb[2])<<8 | uint32(mb[3])
+
+	ones := bits.LeadingZeros32(^v)
+
+	switch {
+	case v == ^uint32(0)<<(32-ones) || ones == 32:
+		// a netmask has contiguous leading ones
+	case bits.LeadingZeros32(v)+bits.TrailingZeros32(^v) == 32:
+		// a wildcard mask is the inverse of a netmask
+		ones = bits.LeadingZeros32(v)
+	default:
+		return netip.Prefix{}, fmt.Errorf("invalid netmask: %s", mask)
+	}
+
+	return a.Prefix(ones)
+}
+
+// parseWildcardOctets parses an IPv4 address whose trailing octets are '*'.
+func parseWildcardOctets(s string) (netip.Prefix, error) {
+	octets := strings.Split(s, ".")
+	if len(octets) != 4 {
+		return netip.Prefix{}, fmt.Errorf("invalid wildcard address: %s", s)
+	}
+
+	known := 0
+
+	for i, o := range octets {
+		if o != "*" {
+			if known != i {
+				return netip.Prefix{}, fmt.Errorf("wildcards must be the trailing octets: %s", s)
+			}
+
+			known++
+
+			continue
+		}
+
+		octets[i] = "0"
+	}
+
+	a, err := netip.ParseAddr(strings.Join(octets, "."))
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	return netip.PrefixFrom(a, known*8), nil
+}
+
+// parseRange parses an inclusive range of addresses into the minimal set of prefixes covering it.
+func parseRange(start, end string) ([]netip.Prefix, error) {
+	s, err := netip.ParseAddr(strings.TrimSpace(start))
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := netip.ParseAddr(strings.TrimSpace(end))
+	if err != nil {
+		return nil, err
+	}
+
+	return RangeToPrefixes(s, e)
+}
+
+// RangeToPrefixes returns the minimal set of prefixes covering the inclusive range start to end.
+func RangeToPrefixes(start, end netip.Addr) ([]netip.Prefix, error) {
+	start, end = start.Unmap(), end.Unmap()
+
+	if start.Is4() != end.Is4() {
+		return nil, fmt.Errorf("range mixes address families: %s-%s", start, end)
+	}
+
+	if end.Less(start) {
+		return nil, fmt.Errorf("range ends before it starts: %s-%s", start, end)
+	}
+
+	var prefixes []netip.Prefix
+
+	for {
+		// the largest block starting at start that does not pass end
+		var p netip.Prefix
+
+		for l := 0; l <= start.BitLen(); l++ {
+			p = netip.PrefixFrom(start, l).Masked()
+			if p.Addr() == start && !end.Less(lastAddr(p)) {
+				break
+			}
+		}
+
+		prefixes = append(prefixes, p)
+
+		last := lastAddr(p)
+		if last == end {
+			return prefixes, nil
+		}
+
+		start = last.Next()
+	}
+}
+
+// lastAddr returns the last address in p.
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().AsSlice()
+
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+
+	a, _ := netip.AddrFromSlice(b)
+
+	return a
+}
@@ -0,0 +1,94 @@
+package url_test
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	mUrl "github.com/jonhadfield/ip-fetcher/providers/url"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNotation(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"192.0.2.0/24", []string{"192.0.2.0/24"}},
+		{"192.0.2.1", []string{"192.0.2.1/32"}},
+		{"2001:db8::1", []string{"2001:db8::1/128"}},
+		{"2001:db8::1 # host", []string{"2001:db8::1/128"}},
+		{"192.0.2.1 - blocked", []string{"192.0.2.1/32"}},
+		{"1.2.3.4-1.2.3.80", []string{
+			"1.2.3.4/30", "1.2.3.8/29", "1.2.3.16/28", "1.2.3.32/27", "1.2.3.64/28", "1.2.3.80/32",
+		}},
+		{"192.0.2.0 - 192.0.2.255", []string{"192.0.2.0/24"}},
+		{"192.0.2.0- 192.0.3.255", []string{"192.0.2.0/23"}},
+		{"192.0.2.7-192.0.2.7", []string{"192.0.2.7/32"}},
+		{"0.0.0.0-255.255.255.255", []string{"0.0.0.0/0"}},
+		{"2001:db8::-2001:db8::1:ffff", []string{"2001:db8::/111"}},
+		{"10.0.0.0 255.255.255.0", []string{"10.0.0.0/24"}},
+		{"10.0.0.0/255.255.0.0", []string{"10.0.0.0/16"}},
+		{"10.0.0.0 0.0.0.255", []string{"10.0.0.0/24"}},
+		{"10.0.0.1 255.255.255.255", []string{"10.0.0.1/32"}},
+		{"10.0.*.*", []string{"10.0.0.0/16"}},
+		{"10.*.*.*", []string{"10.0.0.0/8"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			prefixes, err := mUrl.ParseNotation(tt.in)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, prefixStrings(prefixes))
+		})
+	}
+
+	for _, in := range []string{
+		"",
+		"nonsense",
+		"192.0.2.80-192.0.2.4",
+		"192.0.2.1-2001:db8::1",
+		"10.0.0.0 255.0.255.0",
+		"2001:db8:: 255.255.255.0",
+		"10.*.0.*",
+		"10.0.*",
+	} {
+		_, err := mUrl.ParseNotation(in)
+		require.Error(t, err, in)
+	}
+}
+
+func TestReadPrefixesLineErrors(t *testing.T) {
+	data := "# list\n192.0.2.1\n\n1.2.3.4-1.2.3.7\nnonsense\n2001:db8::1\n10.0.0.0 255.0.255.0\n"
+
+	var prefixes []netip.Prefix
+
+	stats, err := mUrl.ReadPrefixesWithStats(strings.NewReader(data), mUrl.FormatText, "",
+		func(p netip.Prefix) error {
+			prefixes = append(prefixes, p)
+
+			return nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, []string{"192.0.2.1/32", "1.2.3.4/30", "2001:db8::1/128"}, prefixStrings(prefixes))
+	require.EqualValues(t, 3, stats.Prefixes)
+	require.EqualValues(t, 2, stats.Invalid)
+	require.EqualValues(t, 1, stats.Commented)
+	require.Len(t, stats.Errors, 2)
+	require.Equal(t, 5, stats.Errors[0].Line)
+	require.Equal(t, "nonsense", stats.Errors[0].Text)
+	require.Equal(t, 7, stats.Errors[1].Line)
+	require.Contains(t, stats.Errors[1].Error(), "line 7")
+
+	stats, err = mUrl.ReadPrefixesWithStats(strings.NewReader("cidr\n192.0.2.0-192.0.2.1\nbad\n"),
+		mUrl.FormatCSV, "cidr", func(netip.Prefix) error { return nil })
+	require.NoError(t, err)
+	require.EqualValues(t, 1, stats.Prefixes)
+	require.Equal(t, 3, stats.Errors[0].Line)
+
+	stats, err = mUrl.ReadPrefixesWithStats(strings.NewReader(strings.Repeat("bad\n", mUrl.MaxLineErrors+5)),
+		mUrl.FormatText, "", func(netip.Prefix) error { return nil })
+	require.NoError(t, err)
+	require.EqualValues(t, mUrl.MaxLineErrors+5, stats.Invalid)
+	require.Len(t, stats.Errors, mUrl.MaxLineErrors)
+}
@@ -0,0 +1,143 @@
+package url
+
+import (
+	"bytes"
+	"net/netip"
+	"slices"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// SourceReport summarises what was read from a single source.
+type SourceReport struct {
+	// Source identifies the source, with any secrets masked.
+	Source string `json:"source"`
+	ReadStats
+	// Error describes why the source could not be fetched or parsed.
+	Error string `json:"error,omitempty"`
+	// Err is the error describing why the source could not be fetched or parsed.
+	Err error `json:"-"`
+}
+
+// sourceName identifies the request's source, with any secrets masked.
+func (r *Request) sourceName() string {
+	if IsLocal(r.URL) {
+		return localName(r.URL)
+	}
+
+	return r.displayURL()
+}
+
+// Result is the outcome of fetching prefixes from a set of sources.
+type Result struct {
+	// Prefixes maps each prefix to the sources it was read from.
+	Prefixes map[netip.Prefix][]string
+	// Sources reports on each source, in the order requested.
+	Sources []SourceReport
+}
+
+// Failed returns the reports of the sources that could not be fetched or parsed.
+func (r Result) Failed() []SourceReport {
+	var failed []SourceReport
+
+	for _, s := range r.Sources {
+		if s.Err != nil {
+			failed = append(failed, s)
+		}
+	}
+
+	return failed
+}
+
+// SortedPrefixes returns the prefixes ordered by address and then length.
+func (r Result) SortedPrefixes() []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(r.Prefixes))
+	for p := range r.Prefixes {
+		prefixes = append(prefixes, p)
+	}
+
+	slices.SortFunc(prefixes, comparePrefixes)
+
+	return prefixes
+}
+
+func comparePrefixes(a, b netip.Prefix) int {
+	if c := a.Addr().Compare(b.Addr()); c != 0 {
+		return c
+	}
+
+	return a.Bits() - b.Bits()
+}
+
+// FetchSources fetches every source and merges their prefixes, reporting on each source.
+// Unlike FetchPrefixes, sources that fail are reported rather than ignored. Prefixes read
+// from a source before a parse error are still included.
+func (c *Client) FetchSources(requests []Request) Result {
+	result := Result{
+		Prefixes: make(map[netip.Prefix][]string),
+		Sources:  make([]SourceReport, len(requests)),
+	}
+
+	var (
+		mu sync.Mutex
+		g  errgroup.Group
+	)
+
+	for i, req := range requests {
+		g.Go(func() error {
+			report := SourceReport{Source: req.sourceName()}
+
+			response, err := c.get(req)
+			if err == nil {
+				report.Source = response.url
+
+				var prefixes []netip.Prefix
+
+				report.ReadStats, err = ReadPrefixesWithStats(bytes.NewReader(response.Data), response.format,
+					response.selector, func(p netip.Prefix) error {
+						prefixes = append(prefixes, p)
+
+						return nil
+					})
+
+				mu.Lock()
+				for _, p := range prefixes {
+					if !slices.Contains(result.Prefixes[p], report.Source) {
+						result.Prefixes[p] = append(result.Prefixes[p], report.Source)
+					}
+				}
+				mu.Unlock()
+			}
+
+			if err != nil {
+				report.Err = err
+				report.Error = err.Error()
+			}
+
+			result.Sources[i] = report
+
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	// sources complete in any order so order the origins of each prefix as requested
+	order := make(map[string]int, len(result.Sources))
+	for i, s := range result.Sources {
+		if _, ok := order[s.Source]; !ok {
+			order[s.Source] = i
+		}
+	}
+
+	for p, sources := range result.Prefixes {
+		slices.SortFunc(sources, func(a, b string) int {
+			return order[a] - order[b]
+		})
+
+		result.Prefixes[p] = sources
+	}
+
+	return result
+}
@@ -1,9 +1,12 @@
 package url
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/netip"
 	"net/url"
@@ -27,6 +30,11 @@ type Client struct {
 	Debug bool
 	// URLs       []url.URL
 	HTTPClient *retryablehttp.Client
+	// Stdin is read for the Stdin source, os.Stdin if not set.
+	Stdin io.Reader
+
+	// secrets holds the credentials of every request made, so they can be masked in the client's logs
+	secrets web.Secrets
 }
 
 func New(opt ...Option) *Client {
@@ -42,6 +50,8 @@ func New(opt ...Option) *Client {
 		c.HTTPClient = web.NewHTTPClient()
 	}
 
+	c.HTTPClient.Logger = web.MaskLogger(c.HTTPClient.Logger, &c.secrets)
+
 	return c
 }
 
@@ -51,6 +61,13 @@ func WithHTTPClient(rc *retryablehttp.Client) Option {
 	}
 }
 
+// WithStdin sets the reader used for the Stdin source.
+func WithStdin(r io.Reader) Option {
+	return func(c *Client) {
+		c.Stdin = r
+	}
+}
+
 type HTTPFiles struct {
 	Client *retryablehttp.Client
 	URLs   []string
@@ -92,7 +109,7 @@ func (c *Client) FetchPrefixesAsText(requests []Request) ([]string, error) {
 
 	for i, req := range requests {
 		g.Go(func() error {
-			response, err := c.get(req.URL, req.Header)
+			response, err := c.get(req)
 			if err != nil {
 				mu.Lock()
 				fetchErrors = append(fetchErrors, err.Error())
@@ -171,7 +188,7 @@ func (c *Client) FetchPrefixes(requests []Request) (map[netip.Prefix][]string, e
 
 	for i, req := range requests {
 		g.Go(func() error {
-			response, err := c.get(req.URL, req.Header)
+			response, err := c.get(req)
 			if err != nil {
 				logrus.Debugf("%s | %s", pflog.GetFunctionName(), err.Error())
 
@@ -202,6 +219,45 @@ func (c *Client) FetchPrefixes(requests []Request) (map[netip.Prefix][]string, e
 	return prefixes, nil
 }
 
+// StreamPrefixes fetches a single URL, or reads a local source, and calls fn for each prefix as the response body is read,
+// without buffering the whole document in memory.
+func (c *Client) StreamPrefixes(req Request, fn func(netip.Prefix) error) error {
+	if c.Debug {
+		logrus.SetLevel(logrus.DebugLevel)
+	}
+
+	if IsLocal(req.URL) {
+		r, _, err := c.openLocal(req.URL)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		return ReadPrefixes(r, req.Format, req.Selector, fn)
+	}
+
+	secrets := c.requestSecrets(req)
+
+	body, _, status, err := web.RequestStream(
+		c.HTTPClient,
+		req.URL.String(),
+		http.MethodGet,
+		req.Header,
+		secrets,
+		web.DefaultRequestTimeout,
+	)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if !web.IsSuccessStatus(status) {
+		return web.NewStatusError("", req.displayURL(), status)
+	}
+
+	return ReadPrefixes(body, req.Format, req.Selector, fn)
+}
+
 func (hf *HTTPFile) FetchPrefixes() ([]netip.Prefix, error) {
 	if hf.Debug {
 		logrus.SetLevel(logrus.DebugLevel)
@@ -233,70 +289,82 @@ func (hf *HTTPFile) FetchURL() (URLResponse, error) {
 	return result, err
 }
 
-func extractNetFromString(in string) netip.Prefix {
-	funcName := pflog.GetFunctionName()
+// ReadRawPrefixesFromFileData reads the IPs as strings from the given path.
+func ReadRawPrefixesFromFileData(data []byte) ([]netip.Prefix, error) {
+	var ipnets []netip.Prefix
 
-	r := regexp.MustCompile(`^[0-9a-fA-F](\S+)`)
+	err := ReadRawPrefixes(bytes.NewReader(data), func(p netip.Prefix) error {
+		ipnets = append(ipnets, p)
 
-	s := r.FindString(in)
+		return nil
+	})
 
-	// ignore empty strings
-	if s == "" {
-		return netip.Prefix{}
-	}
+	return ipnets, err
+}
 
-	if !strings.Contains(s, "/") {
-		s += "/32"
-	}
+// maxLineLength is the longest line ReadRawPrefixes will accept.
+const maxLineLength = 1024 * 1024
 
-	p, err := netip.ParsePrefix(s)
-	if err != nil {
-		logrus.Tracef("%s | failed to parse %s", funcName, s)
-	}
+// ReadRawPrefixes reads newline separated prefixes from r one line at a time, calling fn for each
+// valid prefix. Each line starts with a prefix, address, range or netmask as described by
+// ParseNotation. Commented and invalid lines are skipped. Errors returned by fn stop reading and are
+// returned unchanged.
+func ReadRawPrefixes(r io.Reader, fn func(netip.Prefix) error) error {
+	_, err := readRawPrefixes(r, fn)
 
-	return p
+	return err
 }
 
-// ReadRawPrefixesFromFileData reads the IPs as strings from the given path.
-func ReadRawPrefixesFromFileData(data []byte) ([]netip.Prefix, error) {
-	text := strings.Split(string(data), "\n")
-
+func readRawPrefixes(r io.Reader, fn func(netip.Prefix) error) (ReadStats, error) {
 	// create regex to check for lines without IPs
-	var r *regexp.Regexp
-	if len(text) > 0 {
-		r = regexp.MustCompile(`^\s*#`)
-	}
+	commentRe := regexp.MustCompile(`^\s*#`)
 
-	var (
-		ipnets         []netip.Prefix
-		invalidCount   int64
-		commentedCount int64
-	)
-	for _, line := range text {
-		// exclude comments
-		if r.MatchString(line) {
-			commentedCount++
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineLength)
+
+	var lineCount int
+
+	pc := &prefixCounter{fn: fn}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineCount++
 
+		if strings.TrimSpace(line) == "" {
 			continue
 		}
 
-		if o := extractNetFromString(line); o.IsValid() {
-			ipnets = append(ipnets, o)
+		// exclude comments
+		if commentRe.MatchString(line) {
+			pc.Commented++
 
 			continue
 		}
 
-		invalidCount++
+		if err := pc.add(lineCount, line); err != nil {
+			return pc.ReadStats, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return pc.ReadStats, web.NewParseError("url", err)
 	}
 
-	logrus.Debugf("%s | loaded %d prefixes from %d lines with %d commented and %d invalid", pflog.GetFunctionName(),
-		len(ipnets), len(text), commentedCount, invalidCount)
+	funcName := pflog.GetFunctionName()
+
+	for _, e := range pc.Errors {
+		logrus.Debugf("%s | %s", funcName, e.Error())
+	}
+
+	logrus.Debugf("%s | loaded %d prefixes from %d lines with %d commented and %d invalid", funcName,
+		pc.Prefixes, lineCount, pc.Commented, pc.Invalid)
 
-	return ipnets, nil
+	return pc.ReadStats, nil
 }
 
+// ReadRawPrefixesFromURLResponse reads the prefixes from the response in the format of its request.
 func ReadRawPrefixesFromURLResponse(response URLResponse) ([]netip.Prefix, error) {
-	prefixes, err := ReadRawPrefixesFromFileData(response.Data)
+	prefixes, err := ReadPrefixesFromData(response.Data, response.format, response.selector)
 
 	return prefixes, err
 }
@@ -305,7 +373,7 @@ func GetPrefixURLMapFromURLResponses(responses *[]URLResponse) (map[netip.Prefix
 	funcName := pflog.GetFunctionName()
 
 	if responses == nil || len(*responses) == 0 {
-		return nil, fmt.Errorf("%s | no responses", funcName)
+		return nil, fmt.Errorf("%s | no responses: %w", funcName, web.ErrEmptyResult)
 	}
 
 	prefixesWithPaths := make(map[netip.Prefix][]string)
@@ -338,49 +406,73 @@ func GetPrefixURLMapFromURLResponses(responses *[]URLResponse) (map[netip.Prefix
 type DataMap map[string][]string
 
 type URLResponse struct {
-	url    string
-	Data   []byte
-	status int
+	url      string
+	Data     []byte
+	status   int
+	format   Format
+	selector string
+}
+
+// requestSecrets returns the secrets to mask for the request and records them for the client's logger.
+func (c *Client) requestSecrets(req Request) []string {
+	secrets := append(web.URLSecrets(req.URL), req.Secrets...)
+
+	c.secrets.Add(secrets...)
+
+	return secrets
 }
 
-func (c *Client) get(url *url.URL, header http.Header) (URLResponse, error) {
+func (c *Client) get(req Request) (URLResponse, error) {
+	if IsLocal(req.URL) {
+		return c.getLocal(req)
+	}
+
+	secrets := c.requestSecrets(req)
+
 	data, _, status, err := web.Request(
 		c.HTTPClient,
-		url.String(),
+		req.URL.String(),
 		http.MethodGet,
-		header,
-		nil,
+		req.Header,
+		secrets,
 		web.DefaultRequestTimeout,
 	)
 	if err != nil {
 		logrus.Debug(err.Error())
+
+		return URLResponse{}, err
 	}
 
-	if status < http.StatusOK || status >= http.StatusMultipleChoices {
-		return URLResponse{}, fmt.Errorf("failed to get: %s status: %d", url.String(), status)
+	if !web.IsSuccessStatus(status) {
+		return URLResponse{}, web.NewStatusError("", req.displayURL(), status)
 	}
 
 	return URLResponse{
-		url:    url.String(),
-		Data:   data,
-		status: status,
-	}, err
+		url:      req.displayURL(),
+		Data:     data,
+		status:   status,
+		format:   req.Format,
+		selector: req.Selector,
+	}, nil
 }
 
 func FetchURLResponse(client *retryablehttp.Client, url string) (URLResponse, error) {
 	data, _, status, err := web.Request(client, url, http.MethodGet, nil, nil, web.DefaultRequestTimeout)
 	if err != nil {
 		logrus.Debug(err.Error())
+
+		return URLResponse{}, err
 	}
-	if status < http.StatusOK || status >= http.StatusMultipleChoices {
-		return URLResponse{}, fmt.Errorf("failed to fetch: %s status: %d", url, status)
+
+	if !web.IsSuccessStatus(status) {
+		return URLResponse{}, web.NewStatusError("", url, status)
 	}
 
 	return URLResponse{
 		url:    url,
 		Data:   data,
 		status: status,
-	}, err
+	}, nil
 }
 
 type GetInput struct {
@@ -392,6 +484,13 @@ type Request struct {
 	Method string
 	URL    *url.URL
 	Header http.Header
+	// Secrets are masked wherever the request would otherwise reveal them in logs and errors.
+	// Passwords in the url and credentials set with the Set methods are added automatically.
+	Secrets []string
+	// Format is the format of the content, FormatText if not set.
+	Format Format
+	// Selector locates the prefixes in JSON and CSV content. See FormatJSON and FormatCSV.
+	Selector string
 }
 
 func (c *Client) Get(requests []Request) (*[]URLResponse, error) {
@@ -409,7 +508,7 @@ func (c *Client) Get(requests []Request) (*[]URLResponse, error) {
 
 	for i, req := range requests {
 		g.Go(func() error {
-			response, err := c.get(req.URL, req.Header)
+			response, err := c.get(req)
 			if err != nil {
 				logrus.Debugf("%s | %s", pflog.GetFunctionName(), err.Error())
 
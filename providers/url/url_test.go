@@ -3,6 +3,7 @@ package url_test
 import (
 	"fmt"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"os"
 	"testing"
@@ -90,3 +91,30 @@ func TestFetchUrlsWithFailedRequest(t *testing.T) {
 	require.Error(t, err)
 	require.Empty(t, responses)
 }
+
+func TestStreamPrefixes(t *testing.T) {
+	u, err := url.Parse("https://www.example.com/files/ips.net")
+	require.NoError(t, err)
+	urlBase := fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+
+	gock.New(urlBase).
+		Get(u.Path).
+		Reply(http.StatusOK).
+		File("testdata/ip-file-1.txt")
+
+	defer gock.Off()
+
+	hf := mUrl.New()
+	gock.InterceptClient(hf.HTTPClient.HTTPClient)
+
+	var prefixes []netip.Prefix
+
+	err = hf.StreamPrefixes(mUrl.Request{URL: u}, func(p netip.Prefix) error {
+		prefixes = append(prefixes, p)
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, prefixes, 4)
+	require.Equal(t, "9.9.9.0/24", prefixes[3].String())
+}
@@ -27,7 +27,7 @@ type Zscaler struct {
 func New() Zscaler {
 	return Zscaler{
 		DownloadURL: DownloadURL,
-		Client:      web.NewHTTPClientWithLogger(),
+		Client:      web.NewProviderHTTPClient(ShortName),
 		Timeout:     web.DefaultRequestTimeout,
 	}
 }
@@ -37,7 +37,16 @@ func (z *Zscaler) FetchData() ([]byte, http.Header, int, error) {
 		z.DownloadURL = DownloadURL
 	}
 
-	return web.Request(z.Client, z.DownloadURL, http.MethodGet, nil, nil, z.Timeout)
+	data, headers, status, err := web.Request(z.Client, z.DownloadURL, http.MethodGet, nil, nil, z.Timeout)
+	if err != nil {
+		return nil, headers, status, err
+	}
+
+	if status >= http.StatusBadRequest {
+		return nil, headers, status, web.NewStatusError(ShortName, z.DownloadURL, status)
+	}
+
+	return data, headers, status, nil
 }
 
 func (z *Zscaler) Fetch() (Doc, error) {
@@ -46,7 +55,12 @@ func (z *Zscaler) Fetch() (Doc, error) {
 		return Doc{}, err
 	}
 
-	return ProcessData(data)
+	doc, err := ProcessData(data)
+	if err != nil {
+		return Doc{}, web.NewParseError(ShortName, err)
+	}
+
+	return doc, nil
 }
 
 type Doc struct {
@@ -0,0 +1,17 @@
+package publisher
+
+import (
+	"net/http"
+
+	"github.com/jonhadfield/ip-fetcher/providers/akamai"
+)
+
+const akamaiFile = "akamai.txt"
+
+func fetchAkamai() ([]byte, http.Header, error) {
+	a := akamai.New()
+
+	data, headers, _, err := a.FetchData()
+
+	return data, headers, err
+}
@@ -1,48 +1,17 @@
 package publisher
 
 import (
-	"bytes"
-	"log/slog"
-	"os"
+	"net/http"
 
-	"github.com/go-git/go-billy/v5"
-	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/jonhadfield/ip-fetcher/providers/atlassian"
 )
 
 const atlassianFile = "atlassian.json"
 
-func fetchAtlassian() ([]byte, error) {
+func fetchAtlassian() ([]byte, http.Header, error) {
 	a := atlassian.New()
 
-	data, _, _, err := a.FetchData()
+	data, headers, _, err := a.FetchData()
 
-	return data, err
-}
-
-func syncAtlassianData(data []byte, wt *git.Worktree, fs billy.Filesystem) (plumbing.Hash, error) {
-	rgb, err := fs.Open(atlassianFile)
-	if err != nil && !os.IsNotExist(err) {
-		return plumbing.ZeroHash, err
-	}
-
-	if err == nil {
-		upToDate, utdErr := isUpToDate(bytes.NewReader(data), rgb)
-		if utdErr != nil || upToDate {
-			return plumbing.ZeroHash, utdErr
-		}
-
-		slog.Info(atlassianFile, "up to date", upToDate)
-	}
-
-	if err = createFile(fs, atlassianFile, data); err != nil {
-		return plumbing.ZeroHash, err
-	}
-
-	if _, err = wt.Add(atlassianFile); err != nil {
-		return plumbing.ZeroHash, err
-	}
-
-	return createCommit(wt, "update atlassian data")
+	return data, headers, err
 }
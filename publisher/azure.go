@@ -1,48 +1,17 @@
 package publisher
 
 import (
-	"bytes"
-	"log/slog"
-	"os"
+	"net/http"
 
-	"github.com/go-git/go-billy/v5"
-	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/jonhadfield/ip-fetcher/providers/azure"
 )
 
 const azureFile = "azure.json"
 
-func fetchAzure() ([]byte, error) {
+func fetchAzure() ([]byte, http.Header, error) {
 	a := azure.New()
 
-	data, _, _, err := a.FetchData()
+	data, headers, _, err := a.FetchData()
 
-	return data, err
-}
-
-func syncAzureData(data []byte, wt *git.Worktree, fs billy.Filesystem) (plumbing.Hash, error) {
-	rgb, err := fs.Open(azureFile)
-	if err != nil && !os.IsNotExist(err) {
-		return plumbing.ZeroHash, err
-	}
-
-	if err == nil {
-		upToDate, utdErr := isUpToDate(bytes.NewReader(data), rgb)
-		if utdErr != nil || upToDate {
-			return plumbing.ZeroHash, utdErr
-		}
-
-		slog.Info(azureFile, "up to date", upToDate)
-	}
-
-	if err = createFile(fs, azureFile, data); err != nil {
-		return plumbing.ZeroHash, err
-	}
-
-	if _, err = wt.Add(azureFile); err != nil {
-		return plumbing.ZeroHash, err
-	}
-
-	return createCommit(wt, "update azure data")
+	return data, headers, err
 }
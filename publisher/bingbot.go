@@ -0,0 +1,17 @@
+package publisher
+
+import (
+	"net/http"
+
+	"github.com/jonhadfield/ip-fetcher/providers/bingbot"
+)
+
+const bingbotFile = "bingbot.json"
+
+func fetchBingbot() ([]byte, http.Header, error) {
+	a := bingbot.New()
+
+	data, headers, _, err := a.FetchData()
+
+	return data, headers, err
+}
@@ -0,0 +1,78 @@
+package publisher
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+)
+
+// Destination is where the dataset is published, such as a git repository, a directory or an
+// object store.
+//
+// Files are written, then committed together. Committed files are published when the
+// destination is closed, if not before.
+type Destination interface {
+	// Open readies the destination, such as by cloning a repository, before files are read or written.
+	Open() error
+	// Read returns the content of a file as of the last commit, or an error wrapping
+	// fs.ErrNotExist. Files written or removed since are not seen until committed.
+	Read(name string) ([]byte, error)
+	// Write stages the content of a file.
+	Write(name string, data []byte) error
+	// Remove stages the removal of a file. Removing a file that doesn't exist is not an error.
+	Remove(name string) error
+	// Commit records the files written since the last commit, described by msg.
+	Commit(msg string) error
+	// Close publishes the commits, such as by pushing them, and releases the destination.
+	Close() error
+	// String describes the destination, without credentials, for logs.
+	String() string
+}
+
+// ErrNothingToCommit is returned by Commit when no files were written since the last commit.
+var ErrNothingToCommit = errors.New("nothing to commit") //nolint:gochecknoglobals
+
+// staged holds the files written to a destination that are not yet committed, in the order
+// first written, and those removed.
+type staged struct {
+	names   []string
+	files   map[string][]byte
+	removed []string
+}
+
+func (s *staged) write(name string, data []byte) {
+	if s.files == nil {
+		s.files = make(map[string][]byte)
+	}
+
+	if _, ok := s.files[name]; !ok {
+		s.names = append(s.names, name)
+	}
+
+	s.files[name] = slices.Clone(data)
+	s.removed = slices.DeleteFunc(s.removed, func(n string) bool { return n == name })
+}
+
+func (s *staged) remove(name string) {
+	delete(s.files, name)
+
+	s.names = slices.DeleteFunc(s.names, func(n string) bool { return n == name })
+
+	if !slices.Contains(s.removed, name) {
+		s.removed = append(s.removed, name)
+	}
+}
+
+func (s *staged) empty() bool {
+	return len(s.names) == 0 && len(s.removed) == 0
+}
+
+func (s *staged) reset() {
+	s.names, s.files, s.removed = nil, nil, nil
+}
+
+// notExist returns the error Read returns for a missing file.
+func notExist(dest Destination, name string) error {
+	return fmt.Errorf("%s: %s: %w", dest, name, fs.ErrNotExist)
+}
@@ -0,0 +1,326 @@
+package publisher_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/jonhadfield/ip-fetcher/guard"
+	"github.com/jonhadfield/ip-fetcher/publisher"
+	"github.com/stretchr/testify/require"
+)
+
+func testProvider(data string) publisher.Provider {
+	return publisher.Provider{
+		FetchFunc: func() ([]byte, http.Header, error) { return []byte(data), nil, nil },
+		ShortName: "test",
+		File:      "test.json",
+		FullName:  "Test",
+		HostType:  "Cloud",
+		SourceURL: "https://example.com/ranges.json",
+	}
+}
+
+func testPublish(t *testing.T, dest publisher.Destination, data string) {
+	t.Helper()
+
+	p := publisher.New()
+	for _, o := range []publisher.Option{
+		publisher.WithDestination(dest),
+		publisher.WithProviders(testProvider(data)),
+		publisher.WithThresholds(guard.Thresholds{MaxDropPercent: 50}),
+	} {
+		o(p)
+	}
+
+	_, err := p.Run()
+	require.NoError(t, err)
+}
+
+func TestDirDestination(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "ranges")
+	dest := publisher.NewDirDestination(dir)
+
+	require.NoError(t, dest.Open())
+
+	_, err := dest.Read("test.json")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	require.ErrorIs(t, dest.Commit("empty"), publisher.ErrNothingToCommit)
+
+	require.NoError(t, dest.Write("test.json", []byte("one")))
+
+	// staged files aren't published until committed
+	_, err = dest.Read("test.json")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	require.NoError(t, dest.Commit("add test"))
+	require.NoError(t, dest.Close())
+
+	data, err := dest.Read("test.json")
+	require.NoError(t, err)
+	require.Equal(t, "one", string(data))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// removed files are removed when committed, and removing a missing file succeeds
+	require.NoError(t, dest.Remove("test.json"))
+	require.NoError(t, dest.Remove("missing.json"))
+
+	_, err = dest.Read("test.json")
+	require.NoError(t, err)
+
+	require.NoError(t, dest.Commit("remove test"))
+
+	_, err = dest.Read("test.json")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+// TestDestinationRead checks that every destination reads the content of the last commit,
+// without the files written or removed since.
+func TestDestinationRead(t *testing.T) {
+	remote := t.TempDir()
+
+	_, err := git.PlainInit(remote, true)
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(&s3Stub{objects: map[string][]byte{}, types: map[string]string{}})
+	defer ts.Close()
+
+	s3 := publisher.NewS3Destination(ts.URL, "eu-west-1", "ranges", "")
+	s3.AccessKeyID, s3.SecretAccessKey = "AKID", "secret"
+
+	for _, dest := range []publisher.Destination{
+		publisher.NewDirDestination(t.TempDir()),
+		publisher.NewGitDestination("file://"+remote, nil),
+		s3,
+	} {
+		t.Run(fmt.Sprintf("%T", dest), func(t *testing.T) {
+			read := func(name string) string {
+				t.Helper()
+
+				data, err := dest.Read(name)
+				if errors.Is(err, fs.ErrNotExist) {
+					return "missing"
+				}
+
+				require.NoError(t, err)
+
+				return string(data)
+			}
+
+			require.NoError(t, dest.Open())
+
+			require.NoError(t, dest.Write("a.txt", []byte("one")))
+			require.Equal(t, "missing", read("a.txt"))
+			require.NoError(t, dest.Commit("add a"))
+			require.Equal(t, "one", read("a.txt"))
+
+			require.NoError(t, dest.Write("a.txt", []byte("two")))
+			require.NoError(t, dest.Write("b.txt", []byte("three")))
+			require.Equal(t, "one", read("a.txt"))
+			require.Equal(t, "missing", read("b.txt"))
+			require.NoError(t, dest.Commit("update a, add b"))
+			require.Equal(t, "two", read("a.txt"))
+			require.Equal(t, "three", read("b.txt"))
+
+			require.NoError(t, dest.Remove("a.txt"))
+			require.Equal(t, "two", read("a.txt"))
+			require.NoError(t, dest.Commit("remove a"))
+			require.Equal(t, "missing", read("a.txt"))
+
+			require.NoError(t, dest.Close())
+		})
+	}
+}
+
+func TestPublishToDir(t *testing.T) {
+	dir := t.TempDir()
+
+	testPublish(t, publisher.NewDirDestination(dir), `{"prefixes":["192.0.2.0/24","198.51.100.0/24"]}`)
+
+	data, err := os.ReadFile(filepath.Join(dir, "test.json"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), "198.51.100.0/24")
+
+	readme, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	require.Contains(t, string(readme), "[test.json](test.json)")
+
+	// a provider whose prefixes dropped beyond the thresholds isn't published
+	testPublish(t, publisher.NewDirDestination(dir), `{"prefixes":[]}`)
+
+	data, err = os.ReadFile(filepath.Join(dir, "test.json"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), "198.51.100.0/24")
+}
+
+func TestPublishToGit(t *testing.T) {
+	remote := t.TempDir()
+
+	_, err := git.PlainInit(remote, true)
+	require.NoError(t, err)
+
+	url := "file://" + remote
+
+	// the first run publishes to an empty repository
+	testPublish(t, publisher.NewGitDestination(url, nil), `{"prefixes":["192.0.2.0/24"]}`)
+	testPublish(t, publisher.NewGitDestination(url, nil), `{"prefixes":["192.0.2.0/24","198.51.100.0/24"]}`)
+
+	worktree := memfs.New()
+
+	repo, err := git.Clone(memory.NewStorage(), worktree, &git.CloneOptions{URL: url})
+	require.NoError(t, err)
+
+	f, err := worktree.Open("test.json")
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "198.51.100.0/24")
+
+	commits, err := repo.Log(&git.LogOptions{})
+	require.NoError(t, err)
+
+	var updates int
+
+	require.NoError(t, commits.ForEach(func(c *object.Commit) error {
+		if c.Message == "update test data" {
+			updates++
+		}
+
+		return nil
+	}))
+	require.Equal(t, 2, updates)
+}
+
+// s3Stub is an S3-compatible object store holding objects in memory.
+type s3Stub struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+	// noList denies listing the bucket, so that missing objects are reported as AccessDenied.
+	noList bool
+}
+
+func s3Error(w http.ResponseWriter, code string, status int) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
+
+func (s *s3Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+		r.Header.Get("X-Amz-Content-Sha256") == "" {
+		s3Error(w, "InvalidAccessKeyId", http.StatusForbidden)
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		data, ok := s.objects[r.URL.Path]
+		if !ok && s.noList {
+			s3Error(w, "AccessDenied", http.StatusForbidden)
+
+			return
+		}
+
+		if !ok {
+			s3Error(w, "NoSuchKey", http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write(data)
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		s.objects[r.URL.Path] = data
+		s.types[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodDelete:
+		delete(s.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func TestPublishToS3(t *testing.T) {
+	stub := &s3Stub{objects: map[string][]byte{}, types: map[string]string{}}
+	ts := httptest.NewServer(stub)
+
+	defer ts.Close()
+
+	newDest := func(keyID string) *publisher.S3Destination {
+		d := publisher.NewS3Destination(ts.URL, "eu-west-1", "ranges", "ip-fetcher/")
+		d.AccessKeyID = keyID
+		d.SecretAccessKey = "secret"
+
+		return d
+	}
+
+	testPublish(t, newDest("AKID"), `{"prefixes":["192.0.2.0/24"]}`)
+
+	require.Contains(t, string(stub.objects["/ranges/ip-fetcher/test.json"]), "192.0.2.0/24")
+	require.Equal(t, "application/json", stub.types["/ranges/ip-fetcher/test.json"])
+	require.Contains(t, stub.objects, "/ranges/ip-fetcher/README.md")
+
+	dest := newDest("AKID")
+	require.NoError(t, dest.Open())
+
+	_, err := dest.Read("missing.json")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	data, err := dest.Read("test.json")
+	require.NoError(t, err)
+	require.Contains(t, string(data), "192.0.2.0/24")
+
+	// without permission to list the bucket, missing objects are denied, which is an error
+	stub.noList = true
+
+	_, err = dest.Read("missing.json")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, fs.ErrNotExist)
+	require.ErrorContains(t, err, "requires s3:ListBucket")
+
+	stub.noList = false
+
+	require.NoError(t, dest.Remove("test.json"))
+	require.NoError(t, dest.Commit("remove test"))
+	require.NotContains(t, stub.objects, "/ranges/ip-fetcher/test.json")
+
+	// errors from the object store are reported
+	denied := newDest("OTHER")
+	denied.Client.RetryMax = 0
+
+	_, err = denied.Read("test.json")
+	require.Error(t, err)
+	require.False(t, errors.Is(err, fs.ErrNotExist))
+	require.Contains(t, err.Error(), "403")
+
+	require.Error(t, publisher.NewS3Destination(ts.URL, "", "ranges", "").Open())
+}
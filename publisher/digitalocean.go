@@ -0,0 +1,17 @@
+package publisher
+
+import (
+	"net/http"
+
+	"github.com/jonhadfield/ip-fetcher/providers/digitalocean"
+)
+
+const digitaloceanFile = "digitalocean.csv"
+
+func fetchDigitalOcean() ([]byte, http.Header, error) {
+	a := digitalocean.New()
+
+	data, headers, _, err := a.FetchData()
+
+	return data, headers, err
+}
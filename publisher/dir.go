@@ -0,0 +1,114 @@
+package publisher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DirDestination publishes to a local directory, such as one served to consumers or synced
+// elsewhere. Each commit replaces its files atomically.
+type DirDestination struct {
+	// Path is the directory, created if missing.
+	Path string
+
+	staged staged
+}
+
+// NewDirDestination returns a destination publishing to the directory at path.
+func NewDirDestination(path string) *DirDestination {
+	return &DirDestination{Path: path}
+}
+
+func (d *DirDestination) String() string {
+	return d.Path
+}
+
+func (d *DirDestination) Open() error {
+	return os.MkdirAll(d.Path, 0o755)
+}
+
+func (d *DirDestination) Read(name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(d.Path, filepath.FromSlash(name)))
+	if os.IsNotExist(err) {
+		return nil, notExist(d, name)
+	}
+
+	return data, err
+}
+
+// Write stages a file, written to the directory by Commit.
+func (d *DirDestination) Write(name string, data []byte) error {
+	d.staged.write(name, data)
+
+	return nil
+}
+
+// Remove stages the removal of a file, removed from the directory by Commit.
+func (d *DirDestination) Remove(name string) error {
+	d.staged.remove(name)
+
+	return nil
+}
+
+// Commit writes the staged files, each first to a temporary file that is then renamed so that
+// readers never see a partial file, then removes those staged for removal.
+func (d *DirDestination) Commit(string) error {
+	if d.staged.empty() {
+		return ErrNothingToCommit
+	}
+
+	for _, name := range d.staged.names {
+		if err := writeFileAtomic(filepath.Join(d.Path, filepath.FromSlash(name)), d.staged.files[name]); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range d.staged.removed {
+		if err := os.Remove(filepath.Join(d.Path, filepath.FromSlash(name))); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	d.staged.reset()
+
+	return nil
+}
+
+func (d *DirDestination) Close() error {
+	return nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	tmp := f.Name()
+
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err = f.Close(); err != nil {
+		_ = os.Remove(tmp)
+
+		return err
+	}
+
+	if err = os.Chmod(tmp, 0o644); err != nil { //nolint:gosec
+		_ = os.Remove(tmp)
+
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
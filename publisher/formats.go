@@ -0,0 +1,236 @@
+package publisher
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/jonhadfield/ip-fetcher/filter"
+	"github.com/jonhadfield/ip-fetcher/overlap"
+)
+
+// Format is a format derived from a provider's data, published alongside the data as
+// provided, with the same schema for every provider.
+type Format string
+
+const (
+	// FormatRaw is the data as provided.
+	FormatRaw Format = "raw"
+	// FormatJSON is a document listing each prefix with its family and attributes.
+	FormatJSON Format = "json"
+	// FormatText is a pair of files listing a prefix per line, one per address family.
+	FormatText Format = "txt"
+	// FormatCSV is a table of each prefix with its family and attributes.
+	FormatCSV Format = "csv"
+)
+
+// Formats are the formats that can be derived, in the order published.
+var Formats = []Format{FormatJSON, FormatText, FormatCSV} //nolint:gochecknoglobals
+
+// ParseFormat returns the Format named s.
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(strings.TrimSpace(s)))
+	if !slices.Contains(Formats, f) {
+		return "", fmt.Errorf("unknown format %q: must be one of json, txt or csv", s)
+	}
+
+	return f, nil
+}
+
+// file is a published file, its content and the number of prefixes it holds.
+type file struct {
+	name   string
+	format Format
+	data   []byte
+	counts Counts
+}
+
+// rawFile returns the file holding a provider's data as provided.
+func rawFile(provider Provider, data []byte) file {
+	return file{provider.File, FormatRaw, data, countPrefixes(filter.DataPrefixes(data))}
+}
+
+// files returns the name of each file published in the format for the provider.
+func (f Format) files(provider Provider) []string {
+	switch f {
+	case FormatJSON:
+		return []string{provider.ShortName + "/prefixes.json"}
+	case FormatText:
+		return []string{provider.ShortName + "/ipv4.txt", provider.ShortName + "/ipv6.txt"}
+	case FormatCSV:
+		return []string{provider.ShortName + "/prefixes.csv"}
+	default:
+		return nil
+	}
+}
+
+// NormalizedDoc is the document published in FormatJSON.
+type NormalizedDoc struct {
+	Provider string             `json:"provider"`
+	Source   string             `json:"source,omitempty"`
+	Prefixes []NormalizedPrefix `json:"prefixes"`
+}
+
+// NormalizedPrefix is a prefix of a NormalizedDoc with the values of each attribute describing it.
+type NormalizedPrefix struct {
+	Prefix     netip.Prefix      `json:"prefix"`
+	Family     string            `json:"family"`
+	Attributes filter.Attributes `json:"attributes,omitempty"`
+}
+
+// normalize returns the unique prefixes in a provider's data, each with the values of every
+// attribute describing it, sorted with IPv4 prefixes first.
+func normalize(provider Provider, data []byte) (NormalizedDoc, error) {
+	entries, err := overlap.ReadEntries(provider.ShortName, data)
+	if err != nil {
+		return NormalizedDoc{}, err
+	}
+
+	index := make(map[netip.Prefix]int)
+
+	doc := NormalizedDoc{Provider: provider.ShortName, Source: provider.SourceURL, Prefixes: []NormalizedPrefix{}}
+
+	for _, e := range entries {
+		p := e.Prefix.Masked()
+
+		i, ok := index[p]
+		if !ok {
+			i = len(doc.Prefixes)
+			index[p] = i
+
+			doc.Prefixes = append(doc.Prefixes, NormalizedPrefix{Prefix: p, Family: family(p)})
+		}
+
+		for k, v := range e.Attributes {
+			if v == "" {
+				continue
+			}
+
+			attrs := doc.Prefixes[i].Attributes
+			if attrs == nil {
+				attrs = make(filter.Attributes)
+				doc.Prefixes[i].Attributes = attrs
+			}
+
+			k = strings.ToLower(k)
+			if !slices.Contains(attrs[k], v) {
+				attrs[k] = append(attrs[k], v)
+			}
+		}
+	}
+
+	slices.SortFunc(doc.Prefixes, func(a, b NormalizedPrefix) int {
+		return cmp.Or(cmp.Compare(a.Prefix.Addr().BitLen(), b.Prefix.Addr().BitLen()),
+			a.Prefix.Addr().Compare(b.Prefix.Addr()), cmp.Compare(a.Prefix.Bits(), b.Prefix.Bits()))
+	})
+
+	for _, p := range doc.Prefixes {
+		for _, v := range p.Attributes {
+			slices.Sort(v)
+		}
+	}
+
+	return doc, nil
+}
+
+func family(p netip.Prefix) string {
+	if p.Addr().Is4() {
+		return "ipv4"
+	}
+
+	return "ipv6"
+}
+
+// derive returns the files published in each format for a provider's data.
+func derive(provider Provider, data []byte, formats []Format) ([]file, error) {
+	if len(formats) == 0 {
+		return nil, nil
+	}
+
+	doc, err := normalize(provider, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prefixes: %w", err)
+	}
+
+	var files []file
+
+	var counts Counts
+
+	for _, p := range doc.Prefixes {
+		if p.Prefix.Addr().Is4() {
+			counts.IPv4++
+		} else {
+			counts.IPv6++
+		}
+	}
+
+	for _, f := range formats {
+		names := f.files(provider)
+
+		switch f {
+		case FormatJSON:
+			b, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+
+			files = append(files, file{names[0], f, append(b, '\n'), counts})
+		case FormatText:
+			var v4, v6 bytes.Buffer
+
+			for _, p := range doc.Prefixes {
+				if p.Prefix.Addr().Is4() {
+					v4.WriteString(p.Prefix.String() + "\n")
+				} else {
+					v6.WriteString(p.Prefix.String() + "\n")
+				}
+			}
+
+			files = append(files, file{names[0], f, v4.Bytes(), Counts{IPv4: counts.IPv4}},
+				file{names[1], f, v6.Bytes(), Counts{IPv6: counts.IPv6}})
+		case FormatCSV:
+			b, err := normalizedCSV(doc)
+			if err != nil {
+				return nil, err
+			}
+
+			files = append(files, file{names[0], f, b, counts})
+		}
+	}
+
+	return files, nil
+}
+
+// normalizedCSV returns a table with the columns provider, prefix, family and attributes, the
+// latter as key=value pairs separated by ';', with multiple values separated by '|'.
+func normalizedCSV(doc NormalizedDoc) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write([]string{"provider", "prefix", "family", "attributes"}); err != nil {
+		return nil, err
+	}
+
+	for _, p := range doc.Prefixes {
+		var attrs []string
+
+		for _, k := range slices.Sorted(maps.Keys(p.Attributes)) {
+			attrs = append(attrs, k+"="+strings.Join(p.Attributes[k], "|"))
+		}
+
+		if err := w.Write([]string{doc.Provider, p.Prefix.String(), p.Family, strings.Join(attrs, ";")}); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,99 @@
+package publisher_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jonhadfield/ip-fetcher/publisher"
+	"github.com/stretchr/testify/require"
+)
+
+const testAWSDoc = `{
+  "syncToken": "1700000000",
+  "prefixes": [
+    {"ip_prefix": "198.51.100.0/24", "region": "eu-west-1", "service": "AMAZON"},
+    {"ip_prefix": "198.51.100.0/24", "region": "eu-west-1", "service": "EC2"},
+    {"ip_prefix": "192.0.2.0/24", "region": "us-east-1", "service": "AMAZON"}
+  ],
+  "ipv6_prefixes": [
+    {"ipv6_prefix": "2001:db8::/32", "region": "GLOBAL", "service": "AMAZON"}
+  ]
+}`
+
+func TestPublishFormats(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := publisher.Publish(
+		publisher.WithDestination(publisher.NewDirDestination(dir)),
+		publisher.WithFormats(publisher.Formats...),
+		publisher.WithProviders(publisher.Provider{
+			FetchFunc: func() ([]byte, http.Header, error) { return []byte(testAWSDoc), nil, nil },
+			ShortName: "aws",
+			File:      "aws.json",
+			FullName:  "Amazon Web Services",
+			SourceURL: "https://ip-ranges.amazonaws.com/ip-ranges.json",
+		}),
+	)
+	require.NoError(t, err)
+
+	read := func(name string) string {
+		t.Helper()
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+
+		return string(data)
+	}
+
+	require.JSONEq(t, testAWSDoc, read("aws.json"))
+
+	var doc publisher.NormalizedDoc
+	require.NoError(t, json.Unmarshal([]byte(read("aws/prefixes.json")), &doc))
+	require.Equal(t, "aws", doc.Provider)
+	require.Equal(t, "https://ip-ranges.amazonaws.com/ip-ranges.json", doc.Source)
+	require.Len(t, doc.Prefixes, 3)
+	require.Equal(t, "192.0.2.0/24", doc.Prefixes[0].Prefix.String())
+	require.Equal(t, "198.51.100.0/24", doc.Prefixes[1].Prefix.String())
+	require.Equal(t, []string{"AMAZON", "EC2"}, doc.Prefixes[1].Attributes["service"])
+	require.Equal(t, "ipv6", doc.Prefixes[2].Family)
+
+	require.Equal(t, "192.0.2.0/24\n198.51.100.0/24\n", read("aws/ipv4.txt"))
+	require.Equal(t, "2001:db8::/32\n", read("aws/ipv6.txt"))
+	require.Equal(t, "provider,prefix,family,attributes\n"+
+		"aws,192.0.2.0/24,ipv4,region=us-east-1;service=AMAZON\n"+
+		"aws,198.51.100.0/24,ipv4,region=eu-west-1;service=AMAZON|EC2\n"+
+		"aws,2001:db8::/32,ipv6,region=GLOBAL;service=AMAZON\n", read("aws/prefixes.csv"))
+
+	require.Contains(t, read("README.md"),
+		"[aws.json](aws.json)<br>[prefixes.json](aws/prefixes.json) [ipv4.txt](aws/ipv4.txt) "+
+			"[ipv6.txt](aws/ipv6.txt) [prefixes.csv](aws/prefixes.csv)")
+
+	// formats added later are published even if the data hasn't changed
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "aws")))
+
+	summary, err := publisher.Publish(
+		publisher.WithDestination(publisher.NewDirDestination(dir)),
+		publisher.WithFormats(publisher.FormatText),
+		publisher.WithProviders(publisher.Provider{
+			FetchFunc: func() ([]byte, http.Header, error) { return []byte(testAWSDoc), nil, nil },
+			ShortName: "aws",
+			File:      "aws.json",
+		}),
+	)
+	require.NoError(t, err)
+	require.Equal(t, publisher.Summary{{Provider: "aws", File: "aws.json"}}, summary)
+	require.Equal(t, "2001:db8::/32\n", read("aws/ipv6.txt"))
+	require.NoFileExists(t, filepath.Join(dir, "aws", "prefixes.csv"))
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := publisher.ParseFormat(" CSV ")
+	require.NoError(t, err)
+	require.Equal(t, publisher.FormatCSV, f)
+
+	_, err = publisher.ParseFormat("xml")
+	require.EqualError(t, err, `unknown format "xml": must be one of json, txt or csv`)
+}
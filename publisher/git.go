@@ -0,0 +1,272 @@
+package publisher
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+const (
+	// DefaultAuthorName and DefaultAuthorEmail are the author of commits to git destinations.
+	DefaultAuthorName  = "ip-fetcher"
+	DefaultAuthorEmail = "ip-fetcher@lessknown.co.uk"
+)
+
+// GitDestination publishes to a git remote, such as a GitHub repository, by cloning it into
+// memory and pushing commits to it.
+type GitDestination struct {
+	// URL is the remote, e.g. https://github.com/example/ranges.git or git@github.com:example/ranges.git.
+	URL string
+	// Auth authenticates with the remote. It may be nil for remotes that don't require it.
+	Auth transport.AuthMethod
+	// Signer, if set, signs commits.
+	Signer Signer
+	// Base, if set, is the branch cloned and published to, rather than the remote's default
+	// branch. It is created if the remote doesn't have it.
+	Base string
+	// Branch, if set, is the branch commits are pushed to, replacing it, rather than the
+	// branch cloned.
+	Branch string
+	// AuthorName and AuthorEmail replace DefaultAuthorName and DefaultAuthorEmail as the
+	// author of commits.
+	AuthorName  string
+	AuthorEmail string
+
+	repo    *git.Repository
+	wt      *git.Worktree
+	fs      billy.Filesystem
+	commits int
+}
+
+// NewGitDestination returns a destination publishing to the git remote at url.
+func NewGitDestination(url string, auth transport.AuthMethod) *GitDestination {
+	return &GitDestination{URL: strings.TrimSpace(url), Auth: auth}
+}
+
+// TokenAuth authenticates with an HTTPS remote, such as GitHub, using a token.
+func TokenAuth(token string) transport.AuthMethod {
+	return &http.BasicAuth{Username: "ip-fetcher", Password: token}
+}
+
+// SSHKeyAuth authenticates with an SSH remote using the private key in the file at path,
+// decrypted with passphrase if not empty. Host keys are checked against the user's known_hosts.
+func SSHKeyAuth(user, path, passphrase string) (transport.AuthMethod, error) {
+	if user == "" {
+		user = "git"
+	}
+
+	auth, err := ssh.NewPublicKeysFromFile(user, path, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ssh key: %w", err)
+	}
+
+	return auth, nil
+}
+
+func (d *GitDestination) String() string {
+	return redact(d.URL)
+}
+
+func (d *GitDestination) Open() error {
+	d.fs = memfs.New()
+
+	opts := &git.CloneOptions{Auth: d.Auth, URL: d.URL}
+	if d.Base != "" {
+		opts.ReferenceName = plumbing.NewBranchReferenceName(d.Base)
+		opts.SingleBranch = true
+	}
+
+	repo, err := git.Clone(memory.NewStorage(), d.fs, opts)
+
+	var missing bool
+
+	if d.Base != "" && isMissingRef(err) {
+		// start the branch from the default branch
+		d.fs = memfs.New()
+		missing = true
+
+		repo, err = git.Clone(memory.NewStorage(), d.fs, &git.CloneOptions{Auth: d.Auth, URL: d.URL})
+	}
+
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		// publish the first commit to an empty repository
+		repo, err = d.initEmpty()
+		missing = false
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to clone repo: %w", err)
+	}
+
+	if d.wt, err = repo.Worktree(); err != nil {
+		return err
+	}
+
+	d.repo = repo
+
+	if missing {
+		err = d.wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(d.Base), Create: true})
+	}
+
+	return err
+}
+
+func isMissingRef(err error) bool {
+	var noMatch git.NoMatchingRefSpecError
+
+	return errors.Is(err, plumbing.ErrReferenceNotFound) || errors.As(err, &noMatch)
+}
+
+func (d *GitDestination) initEmpty() (*git.Repository, error) {
+	d.fs = memfs.New()
+
+	repo, err := git.Init(memory.NewStorage(), d.fs)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Base != "" {
+		head := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName(d.Base))
+		if err = repo.Storer.SetReference(head); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{d.URL}})
+
+	return repo, err
+}
+
+// Read returns the content of a file in the last commit, ignoring files written or removed since.
+func (d *GitDestination) Read(name string) ([]byte, error) {
+	head, err := d.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, notExist(d, name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := d.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := commit.File(name)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, notExist(d, name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := f.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+func (d *GitDestination) Write(name string, data []byte) error {
+	if err := createFile(d.fs, name, data); err != nil {
+		return err
+	}
+
+	_, err := d.wt.Add(name)
+
+	return err
+}
+
+func (d *GitDestination) Remove(name string) error {
+	if _, err := d.fs.Stat(name); os.IsNotExist(err) {
+		return nil
+	}
+
+	_, err := d.wt.Remove(name)
+
+	return err
+}
+
+func (d *GitDestination) Commit(msg string) error {
+	status, err := d.wt.Status()
+	if err != nil {
+		return err
+	}
+
+	if status.IsClean() {
+		return ErrNothingToCommit
+	}
+
+	author := object.Signature{
+		Name:  cmp.Or(d.AuthorName, DefaultAuthorName),
+		Email: cmp.Or(d.AuthorEmail, DefaultAuthorEmail),
+		When:  time.Now(),
+	}
+
+	if _, err = createCommit(d.wt, msg, author, d.Signer); err != nil {
+		return err
+	}
+
+	d.commits++
+
+	return nil
+}
+
+// branch returns the name of the branch cloned.
+func (d *GitDestination) branch() (string, error) {
+	head, err := d.repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return "", err
+	}
+
+	return head.Target().Short(), nil
+}
+
+// Close pushes the commits to the remote.
+func (d *GitDestination) Close() error {
+	if d.commits == 0 {
+		slog.Info("nothing to push", "destination", d.String())
+
+		return nil
+	}
+
+	opts := &git.PushOptions{Auth: d.Auth}
+
+	if d.Branch != "" {
+		head, err := d.repo.Head()
+		if err != nil {
+			return err
+		}
+
+		opts.RefSpecs = []config.RefSpec{config.RefSpec("+" + head.Name().String() + ":" + plumbing.NewBranchReferenceName(d.Branch).String())}
+	}
+
+	slog.Info("pushing changes", "destination", d.String(), "branch", d.Branch)
+
+	err := d.repo.Push(opts)
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,17 @@
+package publisher
+
+import (
+	"net/http"
+
+	"github.com/jonhadfield/ip-fetcher/providers/github"
+)
+
+const githubFile = "github.json"
+
+func fetchGitHub() ([]byte, http.Header, error) {
+	a := github.New()
+
+	data, headers, _, err := a.FetchData()
+
+	return data, headers, err
+}
@@ -0,0 +1,204 @@
+package publisher
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	historyDir        = "history"
+	historyIndexFile  = "index.json"
+	historyDateLayout = "2006-01-02"
+)
+
+// HistoryIndex lists the snapshots archived for a provider, oldest first. Each provider's index
+// is published as history/<provider>/index.json.
+type HistoryIndex struct {
+	Provider  string            `json:"provider"`
+	Snapshots []HistorySnapshot `json:"snapshots"`
+}
+
+// HistorySnapshot is the data published for a provider on a day, archived gzipped as
+// history/<provider>/<YYYY>/<MM>/<DD> followed by the extension of the provider's file and .gz.
+type HistorySnapshot struct {
+	// Date is the day the data was published, as YYYY-MM-DD in UTC.
+	Date string `json:"date"`
+	Name string `json:"name"`
+	// Size and SHA256 describe the data, before it is gzipped.
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// HistoryFile returns the name of the snapshot of a provider's data on the day.
+func HistoryFile(provider Provider, day time.Time) string {
+	return path.Join(historyDir, provider.ShortName, day.UTC().Format("2006/01/02")) + path.Ext(provider.File) + ".gz"
+}
+
+func historyIndexName(provider string) string {
+	return path.Join(historyDir, provider, historyIndexFile)
+}
+
+// archive writes the snapshot of the provider's data fetched on the day, replacing any earlier
+// snapshot that day, and removes snapshots older than the retention period. It returns whether
+// anything was written.
+func (p *Publisher) archive(dest Destination, provider Provider, data []byte, fetched time.Time) (bool, error) {
+	index, err := readHistoryIndex(dest, provider.ShortName)
+	if err != nil {
+		return false, err
+	}
+
+	var changed bool
+
+	snapshot := HistorySnapshot{
+		Date:   fetched.UTC().Format(historyDateLayout),
+		Name:   HistoryFile(provider, fetched),
+		Size:   len(data),
+		SHA256: sha256Hex(data),
+	}
+
+	i := slices.IndexFunc(index.Snapshots, func(s HistorySnapshot) bool { return s.Date == snapshot.Date })
+
+	if i == -1 || index.Snapshots[i] != snapshot {
+		gz, err := gzipData(data)
+		if err != nil {
+			return false, err
+		}
+
+		if err = dest.Write(snapshot.Name, gz); err != nil {
+			return false, err
+		}
+
+		if i == -1 {
+			index.Snapshots = append(index.Snapshots, snapshot)
+		} else {
+			index.Snapshots[i] = snapshot
+		}
+
+		changed = true
+	}
+
+	if p.HistoryRetentionDays > 0 {
+		cutoff := fetched.UTC().AddDate(0, 0, -p.HistoryRetentionDays).Format(historyDateLayout)
+
+		for _, s := range index.Snapshots {
+			if s.Date >= cutoff {
+				continue
+			}
+
+			if err = dest.Remove(s.Name); err != nil {
+				return false, err
+			}
+
+			changed = true
+		}
+
+		index.Snapshots = slices.DeleteFunc(index.Snapshots, func(s HistorySnapshot) bool { return s.Date < cutoff })
+	}
+
+	if !changed {
+		return false, nil
+	}
+
+	slices.SortFunc(index.Snapshots, func(a, b HistorySnapshot) int { return strings.Compare(a.Date, b.Date) })
+
+	b, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return false, err
+	}
+
+	return true, dest.Write(historyIndexName(provider.ShortName), append(b, '\n'))
+}
+
+// readHistoryIndex returns the provider's published history index, or an empty one if none is.
+func readHistoryIndex(dest Destination, provider string) (HistoryIndex, error) {
+	index := HistoryIndex{Provider: provider, Snapshots: []HistorySnapshot{}}
+
+	data, err := dest.Read(historyIndexName(provider))
+	if errors.Is(err, fs.ErrNotExist) {
+		return index, nil
+	}
+
+	if err != nil {
+		return index, err
+	}
+
+	if err = json.Unmarshal(data, &index); err != nil {
+		return index, fmt.Errorf("invalid history index for %s: %w", provider, err)
+	}
+
+	return index, nil
+}
+
+// ReadHistory returns the snapshot of the provider's data published on the day or, if none
+// was, the latest before it, with the data it holds.
+func ReadHistory(dest Destination, provider string, day time.Time) (HistorySnapshot, []byte, error) {
+	index, err := readHistoryIndex(dest, provider)
+	if err != nil {
+		return HistorySnapshot{}, nil, err
+	}
+
+	date := day.UTC().Format(historyDateLayout)
+
+	var found *HistorySnapshot
+
+	for i, s := range index.Snapshots {
+		if s.Date <= date && (found == nil || s.Date > found.Date) {
+			found = &index.Snapshots[i]
+		}
+	}
+
+	if found == nil {
+		return HistorySnapshot{}, nil, fmt.Errorf("no snapshot of %s on or before %s: %w", provider, date, fs.ErrNotExist)
+	}
+
+	gz, err := dest.Read(found.Name)
+	if err != nil {
+		return HistorySnapshot{}, nil, err
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		return HistorySnapshot{}, nil, fmt.Errorf("invalid snapshot %s: %w", found.Name, err)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return HistorySnapshot{}, nil, fmt.Errorf("invalid snapshot %s: %w", found.Name, err)
+	}
+
+	if sha256Hex(data) != found.SHA256 {
+		return HistorySnapshot{}, nil, fmt.Errorf("invalid snapshot %s: checksum mismatch", found.Name)
+	}
+
+	return *found, data, nil
+}
+
+// gzipData compresses data without a name or modification time, so that the same data is
+// always compressed alike.
+func gzipData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,122 @@
+package publisher_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jonhadfield/ip-fetcher/publisher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishHistory(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+	provider := testProvider("")
+
+	// snapshots archived by earlier runs, one beyond the retention period
+	expired, kept := now.AddDate(0, 0, -40), now.AddDate(0, 0, -5)
+
+	seed := publisher.HistoryIndex{Provider: "test"}
+	seedData := `{"prefixes":["192.0.2.0/24"]}`
+	sum := sha256.Sum256([]byte(seedData))
+
+	for _, day := range []time.Time{expired, kept} {
+		name := publisher.HistoryFile(provider, day)
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), gzipped(t, seedData), 0o600))
+
+		seed.Snapshots = append(seed.Snapshots, publisher.HistorySnapshot{
+			Date:   day.Format("2006-01-02"),
+			Name:   name,
+			Size:   len(seedData),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+
+	index, err := json.Marshal(seed)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "history", "test", "index.json"), index, 0o600))
+
+	publish := func(data string) {
+		t.Helper()
+
+		_, err := publisher.Publish(
+			publisher.WithDestination(publisher.NewDirDestination(dir)),
+			publisher.WithProviders(testProvider(data)),
+			publisher.WithHistory(30),
+		)
+		require.NoError(t, err)
+	}
+
+	publish(`{"prefixes":["192.0.2.0/24","198.51.100.0/24"]}`)
+
+	today := publisher.HistoryFile(provider, now)
+	require.Equal(t, "history/test/"+now.Format("2006/01/02")+".json.gz", today)
+
+	require.NoFileExists(t, filepath.Join(dir, publisher.HistoryFile(provider, expired)))
+	require.FileExists(t, filepath.Join(dir, publisher.HistoryFile(provider, kept)))
+
+	dest := publisher.NewDirDestination(dir)
+	require.NoError(t, dest.Open())
+
+	snapshot, data, err := publisher.ReadHistory(dest, "test", now)
+	require.NoError(t, err)
+	require.Equal(t, today, snapshot.Name)
+	require.JSONEq(t, `{"prefixes":["192.0.2.0/24","198.51.100.0/24"]}`, string(data))
+
+	// the data published the same day replaces the day's snapshot
+	publish(`{"prefixes":["192.0.2.0/24","198.51.100.0/24","203.0.113.0/24"]}`)
+
+	_, data, err = publisher.ReadHistory(dest, "test", now)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "203.0.113.0/24")
+
+	var published publisher.HistoryIndex
+
+	indexData, err := dest.Read("history/test/index.json")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(indexData, &published))
+	require.Len(t, published.Snapshots, 2)
+	require.Equal(t, kept.Format("2006-01-02"), published.Snapshots[0].Date)
+	require.Equal(t, now.Format("2006-01-02"), published.Snapshots[1].Date)
+
+	// days without a snapshot return the latest before them
+	snapshot, data, err = publisher.ReadHistory(dest, "test", now.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	require.Equal(t, kept.Format("2006-01-02"), snapshot.Date)
+	require.Equal(t, seedData, string(data))
+
+	// snapshots are checked against their checksum
+	require.NoError(t, os.WriteFile(filepath.Join(dir, snapshot.Name), gzipped(t, `{"prefixes":[]}`), 0o600))
+
+	_, _, err = publisher.ReadHistory(dest, "test", now.AddDate(0, 0, -1))
+	require.ErrorContains(t, err, "checksum mismatch")
+
+	_, _, err = publisher.ReadHistory(dest, "test", now.AddDate(0, 0, -100))
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	_, _, err = publisher.ReadHistory(dest, "other", now)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func gzipped(t *testing.T, data string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	_, err := io.WriteString(w, data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
@@ -0,0 +1,17 @@
+package publisher
+
+import (
+	"net/http"
+
+	"github.com/jonhadfield/ip-fetcher/providers/icloudpr"
+)
+
+const icloudprFile = "icloudpr.csv"
+
+func fetchICloudPR() ([]byte, http.Header, error) {
+	a := icloudpr.New()
+
+	data, headers, _, err := a.FetchData()
+
+	return data, headers, err
+}
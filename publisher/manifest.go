@@ -0,0 +1,218 @@
+package publisher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+)
+
+const manifestFile = "manifest.json"
+
+// Manifest describes every published data file, so that consumers can verify the files and
+// detect stale data without reading the README.
+type Manifest struct {
+	Generated time.Time      `json:"generated"`
+	Files     []ManifestFile `json:"files"`
+	// Providers record the latest attempt to publish each provider's data.
+	Providers []ProviderStatus `json:"providers"`
+}
+
+// ManifestFile describes a published file and where its data came from.
+type ManifestFile struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+	Format   Format `json:"format"`
+	// SourceURL is where the provider publishes the data.
+	SourceURL string `json:"source_url,omitempty"`
+	// Fetched is when the data was last fetched, and Updated when the file last changed.
+	Fetched time.Time `json:"fetched"`
+	Updated time.Time `json:"updated"`
+	// ETag, LastModified and SyncToken identify the upstream version, where the provider
+	// sets them.
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	SyncToken    string `json:"sync_token,omitempty"`
+	Prefixes     Counts `json:"prefixes"`
+	Size         int    `json:"size"`
+	SHA256       string `json:"sha256"`
+}
+
+// Counts are the numbers of unique prefixes in a file by address family.
+type Counts struct {
+	IPv4 int `json:"ipv4"`
+	IPv6 int `json:"ipv6"`
+}
+
+func countPrefixes(prefixes []netip.Prefix) Counts {
+	var c Counts
+
+	seen := make(map[netip.Prefix]struct{}, len(prefixes))
+
+	for _, p := range prefixes {
+		p = p.Masked()
+		if _, ok := seen[p]; ok {
+			continue
+		}
+
+		seen[p] = struct{}{}
+
+		if p.Addr().Is4() {
+			c.IPv4++
+		} else {
+			c.IPv6++
+		}
+	}
+
+	return c
+}
+
+// provenance identifies the upstream version of a provider's data by the response headers or,
+// failing those, the document's own values.
+type provenance struct {
+	fetched      time.Time
+	etag         string
+	lastModified string
+	syncToken    string
+}
+
+func newProvenance(fetched time.Time, headers http.Header, data []byte) provenance {
+	p := provenance{
+		fetched:      fetched,
+		etag:         headers.Get("ETag"),
+		lastModified: headers.Get("Last-Modified"),
+	}
+
+	var doc map[string]json.RawMessage
+	if json.Unmarshal(data, &doc) != nil {
+		return p
+	}
+
+	value := func(key string) string {
+		var v any
+		if json.Unmarshal(doc[key], &v) != nil {
+			return ""
+		}
+
+		switch t := v.(type) {
+		case string:
+			return t
+		case float64:
+			return string(doc[key])
+		default:
+			return ""
+		}
+	}
+
+	p.syncToken = value("syncToken")
+
+	if p.etag == "" {
+		p.etag = value("etag")
+	}
+
+	if p.lastModified == "" {
+		p.lastModified = value("lastModified")
+	}
+
+	return p
+}
+
+// manifestFiles describes the files published for a provider, keeping the time each was
+// last updated from the previous manifest if unchanged.
+func manifestFiles(provider Provider, files []file, prov provenance, previous map[string]ManifestFile) []ManifestFile {
+	res := make([]ManifestFile, 0, len(files))
+
+	for _, f := range files {
+		mf := ManifestFile{
+			Name:         f.name,
+			Provider:     provider.ShortName,
+			Format:       f.format,
+			SourceURL:    provider.SourceURL,
+			Fetched:      prov.fetched,
+			Updated:      prov.fetched,
+			ETag:         prov.etag,
+			LastModified: prov.lastModified,
+			SyncToken:    prov.syncToken,
+			Prefixes:     f.counts,
+			Size:         len(f.data),
+			SHA256:       sha256Hex(f.data),
+		}
+
+		if prev, ok := previous[f.name]; ok && prev.SHA256 == mf.SHA256 {
+			mf.Updated = prev.Updated
+		}
+
+		res = append(res, mf)
+	}
+
+	return res
+}
+
+// readManifest returns the published manifest, or an empty one if it can't be read.
+func readManifest(dest Destination) Manifest {
+	data, err := dest.Read(manifestFile)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Info("failed to read manifest", "error", err)
+		}
+
+		return Manifest{}
+	}
+
+	m, err := ReadManifest(data)
+	if err != nil {
+		slog.Info("failed to read manifest", "error", err)
+
+		return Manifest{}
+	}
+
+	return m
+}
+
+// files returns the manifest's files by name.
+func (m Manifest) files() map[string]ManifestFile {
+	files := make(map[string]ManifestFile, len(m.Files))
+
+	for _, f := range m.Files {
+		files[f.Name] = f
+	}
+
+	return files
+}
+
+// ReadManifest parses a published manifest.
+func ReadManifest(data []byte) (Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Manifest{}, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	return m, nil
+}
+
+func (m Manifest) marshal() ([]byte, error) {
+	slices.SortFunc(m.Files, func(a, b ManifestFile) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if m.Files == nil {
+		m.Files = []ManifestFile{}
+	}
+
+	if m.Providers == nil {
+		m.Providers = []ProviderStatus{}
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,89 @@
+package publisher_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jonhadfield/ip-fetcher/publisher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	aws := publisher.Provider{
+		FetchFunc: func() ([]byte, http.Header, error) {
+			return []byte(testAWSDoc), http.Header{
+				"Etag":          []string{`"abc123"`},
+				"Last-Modified": []string{"Mon, 02 Jan 2006 15:04:05 GMT"},
+			}, nil
+		},
+		ShortName: "aws",
+		File:      "aws.json",
+		SourceURL: "https://ip-ranges.amazonaws.com/ip-ranges.json",
+	}
+	gcp := staticProvider("gcp", `{"prefixes":[{"ipv4Prefix":"203.0.113.0/24"}]}`)
+
+	publish := func(providers ...publisher.Provider) publisher.Manifest {
+		t.Helper()
+
+		_, err := publisher.Publish(
+			publisher.WithDestination(publisher.NewDirDestination(dir)),
+			publisher.WithFormats(publisher.FormatText),
+			publisher.WithProviders(providers...),
+		)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+		require.NoError(t, err)
+
+		m, err := publisher.ReadManifest(data)
+		require.NoError(t, err)
+
+		return m
+	}
+
+	first := publish(aws, gcp)
+
+	var names []string
+	for _, f := range first.Files {
+		names = append(names, f.Name)
+	}
+
+	require.Equal(t, []string{
+		"aws.json", "aws/ipv4.txt", "aws/ipv6.txt",
+		"gcp.json", "gcp/ipv4.txt", "gcp/ipv6.txt",
+	}, names)
+
+	raw := first.Files[0]
+	sum := sha256.Sum256([]byte(testAWSDoc))
+
+	require.Equal(t, "aws", raw.Provider)
+	require.Equal(t, publisher.FormatRaw, raw.Format)
+	require.Equal(t, "https://ip-ranges.amazonaws.com/ip-ranges.json", raw.SourceURL)
+	require.Equal(t, `"abc123"`, raw.ETag)
+	require.Equal(t, "Mon, 02 Jan 2006 15:04:05 GMT", raw.LastModified)
+	require.Equal(t, "1700000000", raw.SyncToken)
+	require.Equal(t, publisher.Counts{IPv4: 2, IPv6: 1}, raw.Prefixes)
+	require.Equal(t, hex.EncodeToString(sum[:]), raw.SHA256)
+	require.Equal(t, len(testAWSDoc), raw.Size)
+	require.False(t, raw.Fetched.IsZero())
+	require.Equal(t, publisher.Counts{IPv6: 1}, first.Files[2].Prefixes)
+
+	// unchanged files keep the time they were updated, and the files of providers that
+	// failed are described as before
+	failed := gcp
+	failed.FetchFunc = func() ([]byte, http.Header, error) { return nil, nil, errors.New("unavailable") }
+
+	second := publish(aws, failed)
+
+	require.Len(t, second.Files, 6)
+	require.True(t, second.Files[0].Fetched.After(raw.Fetched))
+	require.Equal(t, raw.Updated, second.Files[0].Updated)
+	require.Equal(t, first.Files[3], second.Files[3])
+}
@@ -0,0 +1,69 @@
+package publisher
+
+import "errors"
+
+// dryRunDestination reads from a destination but discards what is written to it.
+type dryRunDestination struct {
+	Destination
+}
+
+func (dryRunDestination) Write(string, []byte) error {
+	return nil
+}
+
+func (dryRunDestination) Remove(string) error {
+	return nil
+}
+
+func (dryRunDestination) Commit(string) error {
+	return nil
+}
+
+// previewDestination reads from a destination but writes to a directory in its place. Files
+// read are copied to the directory too, so that it holds every file published.
+type previewDestination struct {
+	published Destination
+	out       *DirDestination
+}
+
+func (d *previewDestination) String() string {
+	return d.out.String()
+}
+
+func (d *previewDestination) Open() error {
+	if err := d.published.Open(); err != nil {
+		return err
+	}
+
+	return d.out.Open()
+}
+
+func (d *previewDestination) Read(name string) ([]byte, error) {
+	data, err := d.published.Read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, d.out.Write(name, data)
+}
+
+func (d *previewDestination) Write(name string, data []byte) error {
+	return d.out.Write(name, data)
+}
+
+func (d *previewDestination) Remove(name string) error {
+	return d.out.Remove(name)
+}
+
+func (d *previewDestination) Commit(msg string) error {
+	return d.out.Commit(msg)
+}
+
+// Close writes any files read but not since committed, then releases the destination read.
+func (d *previewDestination) Close() error {
+	if err := d.out.Commit(""); err != nil && !errors.Is(err, ErrNothingToCommit) {
+		return err
+	}
+
+	return d.published.Close()
+}
@@ -0,0 +1,94 @@
+package publisher_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jonhadfield/ip-fetcher/publisher"
+	"github.com/stretchr/testify/require"
+)
+
+func staticProvider(name, data string) publisher.Provider {
+	return publisher.Provider{
+		FetchFunc: func() ([]byte, http.Header, error) { return []byte(data), nil, nil },
+		ShortName: name,
+		File:      name + ".json",
+	}
+}
+
+func TestPublishDryRun(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "aws.json"), []byte(`["192.0.2.0/24"]`), 0o600))
+
+	summary, err := publisher.Publish(
+		publisher.WithDestination(publisher.NewDirDestination(dir)),
+		publisher.WithDryRun(true),
+		publisher.WithProviders(
+			staticProvider("aws", `["192.0.2.0/25","198.51.100.0/24"]`),
+			staticProvider("gcp", `["203.0.113.0/24"]`),
+		),
+	)
+	require.NoError(t, err)
+	require.Equal(t, publisher.Summary{
+		{Provider: "aws", File: "aws.json", Added: 2, Removed: 1},
+		{Provider: "gcp", File: "gcp.json", Added: 1},
+	}, summary)
+
+	// nothing is written
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	data, err := os.ReadFile(filepath.Join(dir, "aws.json"))
+	require.NoError(t, err)
+	require.Equal(t, `["192.0.2.0/24"]`, string(data))
+}
+
+func TestPublishOutputDir(t *testing.T) {
+	published := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(published, "aws.json"), []byte(`["192.0.2.0/24"]`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(published, "gcp.json"), []byte(`["203.0.113.0/24"]`), 0o600))
+
+	out := filepath.Join(t.TempDir(), "preview")
+
+	summary, err := publisher.Publish(
+		publisher.WithDestination(publisher.NewDirDestination(published)),
+		publisher.WithOutputDir(out),
+		publisher.WithProviders(
+			staticProvider("aws", `["192.0.2.0/24","198.51.100.0/24"]`),
+			staticProvider("gcp", `["203.0.113.0/24"]`),
+		),
+	)
+	require.NoError(t, err)
+	require.Equal(t, publisher.Summary{{Provider: "aws", File: "aws.json", Added: 1}}, summary)
+
+	// the preview holds every file, changed or not
+	for name, want := range map[string]string{
+		"aws.json": `["192.0.2.0/24","198.51.100.0/24"]`,
+		"gcp.json": `["203.0.113.0/24"]`,
+	} {
+		data, err := os.ReadFile(filepath.Join(out, name))
+		require.NoError(t, err)
+		require.Equal(t, want, string(data))
+	}
+
+	require.FileExists(t, filepath.Join(out, "README.md"))
+	require.NoFileExists(t, filepath.Join(published, "README.md"))
+
+	data, err := os.ReadFile(filepath.Join(published, "aws.json"))
+	require.NoError(t, err)
+	require.Equal(t, `["192.0.2.0/24"]`, string(data))
+}
+
+func TestPublishRequiresRepository(t *testing.T) {
+	t.Setenv("GITHUB_PUBLISH_URL", "")
+	t.Setenv("GITHUB_TOKEN", "")
+
+	_, err := publisher.Publish()
+	require.EqualError(t, err, "GITHUB_PUBLISH_URL not set")
+
+	_, err = publisher.Publish(publisher.WithRepoURL("https://github.com/example/ranges.git"))
+	require.EqualError(t, err, "GITHUB_TOKEN not set")
+}
@@ -1,80 +1,301 @@
 package publisher
 
 import (
+	"bytes"
+	"cmp"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
+	"net/http"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/go-git/go-billy/v5"
-	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
-	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/jonhadfield/ip-fetcher/guard"
 	"golang.org/x/sync/errgroup"
 )
 
 type Publisher struct {
 	GitHubToken   string
 	GitHubRepoURL string
+	// Destination is where the dataset is published. Without one, it is published to the git
+	// repository at GitHubRepoURL using GitHubToken.
+	Destination Destination
+	// Thresholds limit how far the number of prefixes a provider publishes may drop from
+	// those already published.
+	Thresholds guard.Thresholds
+	// Force publishes providers even if their number of prefixes dropped beyond the thresholds.
+	Force bool
+	// Providers are the providers published, defaulting to all of them.
+	Providers []Provider
+	// DryRun reads the published dataset to find what a run would change, without publishing.
+	DryRun bool
+	// OutputDir is a directory to write the files a run would publish to, in place of
+	// publishing them, so that they can be previewed.
+	OutputDir string
+	// Formats are published for each provider alongside its data as provided.
+	Formats []Format
+	// Signer, if set, signs commits to git destinations and writes a detached signature of
+	// each data file and the manifest.
+	Signer Signer
+	// PullRequest, if set, proposes the changes in a pull request rather than pushing them to
+	// the git destination's default branch.
+	PullRequest *PullRequestConfig
+	// StaleAfter is how long a provider may go without a successful update before it is
+	// flagged as stale in the README and manifest. Zero disables the check.
+	StaleAfter time.Duration
+	// Branch, AuthorName and AuthorEmail set the branch of a git destination published to
+	// and the author of its commits, where the destination doesn't.
+	Branch      string
+	AuthorName  string
+	AuthorEmail string
+	// ReadMeTemplate replaces the template of the published README, in which {{ rows }} is
+	// replaced by a row per provider and {{ date }} by the time published.
+	ReadMeTemplate string
+	// History archives a snapshot of each provider's data every day it is published, under
+	// history/<provider>, keeping those from the last HistoryRetentionDays days, or every
+	// snapshot if zero.
+	History              bool
+	HistoryRetentionDays int
+	// SingleCommit publishes every change made by a run in one commit, summarizing the
+	// prefixes each provider added and removed, rather than a commit per file.
+	SingleCommit bool
 }
 
-func Publish() {
-	p := New()
+type Option func(*Publisher)
 
-	err := p.Run()
-	if err != nil {
-		slog.Error("publish failed", "error", err)
+// WithThresholds sets how far the number of prefixes a provider publishes may drop.
+func WithThresholds(t guard.Thresholds) Option {
+	return func(p *Publisher) {
+		p.Thresholds = t
+	}
+}
 
-		os.Exit(1)
+// WithRepoURL sets the url of the repository to publish to, in place of GITHUB_PUBLISH_URL.
+func WithRepoURL(u string) Option {
+	return func(p *Publisher) {
+		p.GitHubRepoURL = strings.TrimSpace(u)
 	}
 }
 
-func New() *Publisher {
-	var pub Publisher
+// WithToken sets the token used to clone and push to the repository, in place of GITHUB_TOKEN.
+func WithToken(token string) Option {
+	return func(p *Publisher) {
+		p.GitHubToken = strings.TrimSpace(token)
+	}
+}
 
-	pub.GitHubRepoURL = strings.TrimSpace(os.Getenv("GITHUB_PUBLISH_URL"))
-	if pub.GitHubRepoURL == "" {
-		slog.Error("GITHUB_PUBLISH_URL not set") //nolint:sloglint
-		os.Exit(1)
+// WithDestination publishes to d in place of the repository at GITHUB_PUBLISH_URL.
+func WithDestination(d Destination) Option {
+	return func(p *Publisher) {
+		p.Destination = d
 	}
+}
 
-	pub.GitHubToken = strings.TrimSpace(os.Getenv("GITHUB_TOKEN"))
-	if pub.GitHubToken == "" {
-		slog.Error("GITHUB_TOKEN not set") //nolint:sloglint
-		os.Exit(1)
+// WithForce publishes providers even if their number of prefixes dropped beyond the thresholds.
+func WithForce(force bool) Option {
+	return func(p *Publisher) {
+		p.Force = force
+	}
+}
+
+// WithDryRun finds what a run would change, without publishing.
+func WithDryRun(dryRun bool) Option {
+	return func(p *Publisher) {
+		p.DryRun = dryRun
+	}
+}
+
+// WithOutputDir writes the files a run would publish to dir, in place of publishing them.
+func WithOutputDir(dir string) Option {
+	return func(p *Publisher) {
+		p.OutputDir = dir
+	}
+}
+
+// WithFormats publishes the formats for each provider alongside its data as provided.
+func WithFormats(formats ...Format) Option {
+	return func(p *Publisher) {
+		p.Formats = formats
+	}
+}
+
+// WithSigner signs commits and published files with s.
+func WithSigner(s Signer) Option {
+	return func(p *Publisher) {
+		p.Signer = s
+	}
+}
+
+// WithPullRequest proposes the changes in a GitHub pull request rather than pushing them.
+func WithPullRequest(cfg PullRequestConfig) Option {
+	return func(p *Publisher) {
+		p.PullRequest = &cfg
+	}
+}
+
+// WithStaleAfter flags providers not successfully updated within d as stale.
+func WithStaleAfter(d time.Duration) Option {
+	return func(p *Publisher) {
+		p.StaleAfter = d
+	}
+}
+
+// WithBranch publishes to the branch of the git destination, in place of its default branch.
+func WithBranch(branch string) Option {
+	return func(p *Publisher) {
+		p.Branch = strings.TrimSpace(branch)
+	}
+}
+
+// WithAuthor sets the author of commits to the git destination.
+func WithAuthor(name, email string) Option {
+	return func(p *Publisher) {
+		p.AuthorName = strings.TrimSpace(name)
+		p.AuthorEmail = strings.TrimSpace(email)
+	}
+}
+
+// WithReadMeTemplate replaces the template of the published README.
+func WithReadMeTemplate(tmpl string) Option {
+	return func(p *Publisher) {
+		p.ReadMeTemplate = tmpl
+	}
+}
+
+// WithHistory archives a daily snapshot of each provider's data, removing those older than
+// retentionDays days unless zero.
+func WithHistory(retentionDays int) Option {
+	return func(p *Publisher) {
+		p.History = true
+		p.HistoryRetentionDays = retentionDays
+	}
+}
+
+// WithSingleCommit publishes every change made by a run in one commit.
+func WithSingleCommit(single bool) Option {
+	return func(p *Publisher) {
+		p.SingleCommit = single
+	}
+}
+
+// WithProviders publishes only the given providers.
+func WithProviders(providers ...Provider) Option {
+	return func(p *Publisher) {
+		p.Providers = providers
 	}
+}
+
+// Publish publishes the dataset, returning the changes made.
+func Publish(opt ...Option) (Summary, error) {
+	p := New()
+
+	for _, o := range opt {
+		o(p)
+	}
+
+	return p.Run()
+}
+
+// New returns a Publisher for the repository set by GITHUB_PUBLISH_URL, using GITHUB_TOKEN.
+func New() *Publisher {
+	pub := Publisher{
+		Thresholds: guard.Thresholds{MaxDropPercent: guard.DefaultMaxDropPercent},
+		StaleAfter: DefaultStaleAfter,
+	}
+
+	pub.GitHubRepoURL = strings.TrimSpace(os.Getenv("GITHUB_PUBLISH_URL"))
+	pub.GitHubToken = strings.TrimSpace(os.Getenv("GITHUB_TOKEN"))
 
 	return &pub
 }
 
-func (p *Publisher) Run() error {
-	fs := memfs.New()
-	storer := memory.NewStorage()
+// destination returns the Destination, or the GitHub repository if none is set, wrapped for
+// a dry run or preview.
+func (p *Publisher) destination() (Destination, error) {
+	dest := p.Destination
 
-	repo, err := git.Clone(storer, fs, &git.CloneOptions{Auth: &http.BasicAuth{
-		Username: "-",
-		Password: p.GitHubToken,
-	}, URL: p.GitHubRepoURL})
-	if err != nil {
-		return fmt.Errorf("failed to clone repo: %w", err)
+	if dest == nil {
+		if p.GitHubRepoURL == "" {
+			return nil, errors.New("GITHUB_PUBLISH_URL not set")
+		}
+
+		var auth transport.AuthMethod
+
+		switch {
+		case p.GitHubToken != "":
+			auth = TokenAuth(p.GitHubToken)
+		case !p.DryRun && p.OutputDir == "":
+			return nil, errors.New("GITHUB_TOKEN not set")
+		}
+
+		dest = NewGitDestination(p.GitHubRepoURL, auth)
+	}
+
+	g, ok := dest.(*GitDestination)
+	if ok {
+		if g.Signer == nil {
+			g.Signer = p.Signer
+		}
+
+		g.Base = cmp.Or(g.Base, p.Branch)
+		g.AuthorName = cmp.Or(g.AuthorName, p.AuthorName)
+		g.AuthorEmail = cmp.Or(g.AuthorEmail, p.AuthorEmail)
 	}
 
-	w, err := repo.Worktree()
+	if p.PullRequest != nil && !ok {
+		return nil, fmt.Errorf("pull requests require a git destination, not %s", dest)
+	}
+
+	switch {
+	case p.OutputDir != "":
+		return &previewDestination{published: dest, out: NewDirDestination(p.OutputDir)}, nil
+	case p.DryRun:
+		return dryRunDestination{dest}, nil
+	default:
+		return dest, nil
+	}
+}
+
+// Run publishes the dataset, returning the changes made or, for a dry run or preview, those
+// that would be.
+func (p *Publisher) Run() (Summary, error) {
+	dest, err := p.destination()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if err = dest.Open(); err != nil {
+		return nil, err
+	}
+
+	providers := p.Providers
+	if providers == nil {
+		providers = Providers()
 	}
 
+	last := readManifest(dest)
+	previous := last.files()
+
+	// staleness is measured from the start of the run, so data fetched by it is never stale
+	started := time.Now().UTC()
+
 	// Phase 1: Fetch all provider data in parallel
 	type fetchResult struct {
-		data []byte
-		err  error
+		data    []byte
+		headers http.Header
+		fetched time.Time
+		err     error
 	}
 
 	results := make([]fetchResult, len(providers))
@@ -83,8 +304,8 @@ func (p *Publisher) Run() error {
 
 	for i, provider := range providers {
 		g.Go(func() error {
-			data, fetchErr := provider.FetchFunc()
-			results[i] = fetchResult{data: data, err: fetchErr}
+			data, headers, fetchErr := provider.FetchFunc()
+			results[i] = fetchResult{data: data, headers: headers, fetched: time.Now().UTC(), err: fetchErr}
 
 			return nil // don't fail fast — collect all results
 		})
@@ -92,66 +313,289 @@ func (p *Publisher) Run() error {
 
 	_ = g.Wait()
 
-	// Phase 2: Sync sequentially (git operations are not concurrency-safe)
-	var included []string
+	// Phase 2: Sync sequentially (destinations are not concurrency-safe)
+	var changes Summary
+
+	// providers whose data is unchanged but whose snapshot was archived
+	var archivedOnly []string
+
+	manifest := Manifest{Generated: time.Now().UTC()}
+
+	status := newStatuses(started, p.StaleAfter, last)
+
+	// providers whose files are described by the new manifest rather than the previous one
+	listed := make(map[string]bool)
 
 	for i, provider := range providers {
 		if results[i].err != nil {
 			slog.Info("failed to fetch", "provider", provider.ShortName, "error", results[i].err)
+			status.fail(provider, StatusFailed, results[i].fetched, results[i].err)
+
+			continue
+		}
+
+		published, err := dest.Read(provider.File)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.Info("failed to read published data", "provider", provider.ShortName, "error", err)
+			status.fail(provider, StatusFailed, results[i].fetched, err)
+
+			continue
+		}
+
+		if err = p.checkShrink(provider, published, results[i].data); err != nil {
+			slog.Error("refusing to publish", "provider", provider.ShortName, "error", err)
+			status.fail(provider, StatusRefused, results[i].fetched, err)
+
+			continue
+		}
+
+		derived, err := derive(provider, results[i].data, p.Formats)
+		if err != nil {
+			slog.Info("failed to derive formats", "provider", provider.ShortName, "error", err)
+			status.fail(provider, StatusFailed, results[i].fetched, err)
 
 			continue
 		}
 
-		var commit plumbing.Hash
+		files := append([]file{rawFile(provider, results[i].data)}, derived...)
+		prov := newProvenance(results[i].fetched, results[i].headers, results[i].data)
 
-		commit, err = provider.SyncDataFunc(results[i].data, w, fs)
+		written, err := p.writeChanged(dest, files, published)
 		if err != nil {
 			slog.Info("failed to sync", "provider", provider.ShortName, "error", err)
+			status.fail(provider, StatusFailed, results[i].fetched, err)
 
 			continue
 		}
 
-		included = append(included, provider.ShortName)
+		var archived bool
 
-		if commit.IsZero() {
+		if p.History {
+			if archived, err = p.archive(dest, provider, results[i].data, results[i].fetched); err != nil {
+				slog.Info("failed to archive", "provider", provider.ShortName, "error", err)
+			}
+		}
+
+		if written == 0 {
 			slog.Info("provider", provider.ShortName, "in sync")
 
+			if archived && !p.SingleCommit {
+				if err = dest.Commit("archive " + provider.ShortName + " data"); err != nil && !errors.Is(err, ErrNothingToCommit) {
+					slog.Info("failed to archive", "provider", provider.ShortName, "error", err)
+				}
+			}
+
+			if archived {
+				archivedOnly = append(archivedOnly, provider.ShortName)
+			}
+
+			manifest.Files = append(manifest.Files, manifestFiles(provider, files, prov, previous)...)
+			listed[provider.ShortName] = true
+			status.ok(provider, results[i].fetched)
+
 			continue
 		}
 
 		slog.Info("provider", provider.ShortName, "not in sync")
 
-		_, err = repo.CommitObject(commit)
-		if err != nil {
-			return err
+		if !p.SingleCommit {
+			if err = dest.Commit("update " + provider.ShortName + " data"); err != nil && !errors.Is(err, ErrNothingToCommit) {
+				slog.Info("failed to sync", "provider", provider.ShortName, "error", err)
+				status.fail(provider, StatusFailed, results[i].fetched, err)
+
+				continue
+			}
+		}
+
+		changes = append(changes, newChange(provider, published, results[i].data))
+		manifest.Files = append(manifest.Files, manifestFiles(provider, files, prov, previous)...)
+		listed[provider.ShortName] = true
+		status.ok(provider, results[i].fetched)
+	}
+
+	// files not updated are described as before
+	for _, f := range previous {
+		if !listed[f.Provider] && slices.ContainsFunc(providers, func(p Provider) bool { return p.ShortName == f.Provider }) {
+			manifest.Files = append(manifest.Files, f)
+		}
+	}
+
+	// providers that failed are still listed while their earlier data remains published
+	var included []Provider
+
+	for _, provider := range providers {
+		if !listed[provider.ShortName] {
+			if _, err = dest.Read(provider.File); err != nil {
+				continue
+			}
+		}
+
+		included = append(included, provider)
+
+		if ps, _ := status.get(provider); ps.Stale {
+			slog.Warn("stale data", "provider", provider.ShortName, "last_success", ps.LastSuccess)
+		}
+	}
+
+	manifest.Providers = status.list()
+
+	if err = p.writeIndex(dest, included, manifest, status, changes, archivedOnly); err != nil {
+		return nil, err
+	}
+
+	for _, c := range changes {
+		slog.Info("updated", "provider", c.Provider, "added", c.Added, "removed", c.Removed)
+	}
+
+	if p.PullRequest != nil && !p.DryRun && p.OutputDir == "" {
+		if err = p.propose(dest.(*GitDestination), changes); err != nil { //nolint:forcetypeassert
+			return nil, err
 		}
+
+		return changes, nil
 	}
 
-	commit, err := syncReadMe(included, w, fs)
+	if err = dest.Close(); err != nil {
+		return nil, err
+	}
+
+	if p.DryRun || p.OutputDir != "" {
+		slog.Info("nothing published", "destination", dest.String())
+	} else {
+		slog.Info("publish successful", "destination", dest.String())
+	}
+
+	return changes, nil
+}
+
+// Providers returns every provider published by default.
+func Providers() []Provider {
+	return slices.Clone(providers)
+}
+
+// writeChanged writes the files whose content differs from that published, returning how many
+// were written. The published content of the first file, or nil if not yet published, is given.
+// With a signer, changed files and any without a signature are signed.
+func (p *Publisher) writeChanged(dest Destination, files []file, published []byte) (int, error) {
+	var written int
+
+	for i, f := range files {
+		current, exists := published, published != nil
+
+		if i > 0 {
+			var err error
+
+			current, err = dest.Read(f.name)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return written, err
+			}
+
+			exists = err == nil
+		}
+
+		if upToDate, _ := isUpToDate(bytes.NewReader(f.data), bytes.NewReader(current)); exists && upToDate {
+			if err := p.signIfUnsigned(dest, f); err != nil {
+				return written, err
+			}
+
+			continue
+		}
+
+		if err := dest.Write(f.name, f.data); err != nil {
+			return written, err
+		}
+
+		if err := p.sign(dest, f); err != nil {
+			return written, err
+		}
+
+		written++
+	}
+
+	return written, nil
+}
+
+// sign writes a detached signature of the file, if there is a signer.
+func (p *Publisher) sign(dest Destination, f file) error {
+	if p.Signer == nil {
+		return nil
+	}
+
+	sig, err := p.Signer.Sign(SignatureNamespaceFile, bytes.NewReader(f.data))
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to sign %s: %w", f.name, err)
 	}
 
-	_, err = repo.CommitObject(commit)
+	return dest.Write(f.name+p.Signer.Extension(), sig)
+}
+
+// signIfUnsigned signs a published file without a detached signature, such as one published
+// before signing was configured.
+func (p *Publisher) signIfUnsigned(dest Destination, f file) error {
+	if p.Signer == nil {
+		return nil
+	}
+
+	_, err := dest.Read(f.name + p.Signer.Extension())
+	if errors.Is(err, fs.ErrNotExist) {
+		return p.sign(dest, f)
+	}
+
+	return err
+}
+
+// writeIndex writes and commits the README and manifest, with the changes made by the run if
+// they are committed together. A run that only archived snapshots of unchanged data says so.
+func (p *Publisher) writeIndex(dest Destination, included []Provider, manifest Manifest, status *statuses, changes Summary, archived []string) error {
+	data, err := manifest.marshal()
 	if err != nil {
 		return err
 	}
 
-	slog.Info("pushing changes")
+	if err = dest.Write(readMeFile, []byte(readMeContent(cmp.Or(p.ReadMeTemplate, ReadMeTemplate), included, p.Formats, status))); err != nil {
+		return err
+	}
 
-	err = repo.Push(&git.PushOptions{Auth: &http.BasicAuth{
-		Username: "ip-fetcher",
-		Password: p.GitHubToken,
-	}})
-	if err != nil {
+	if err = dest.Write(manifestFile, data); err != nil {
 		return err
 	}
 
-	slog.Info("publish successful", "url", p.GitHubRepoURL)
+	if err = p.sign(dest, file{name: manifestFile, data: data}); err != nil {
+		return err
+	}
+
+	msg := "update " + readMeFile + " and " + manifestFile
+	switch {
+	case p.SingleCommit && len(changes) == 0 && len(archived) > 0:
+		msg = "archive " + strings.Join(archived, ", ") + " data"
+	case p.SingleCommit:
+		msg = changes.Message()
+	}
+
+	if err = dest.Commit(msg); err != nil && !errors.Is(err, ErrNothingToCommit) {
+		return err
+	}
 
 	return nil
 }
 
+// checkShrink returns an error if the provider's data holds too few prefixes compared to
+// the data already published, unless forced.
+func (p *Publisher) checkShrink(provider Provider, published, data []byte) error {
+	if published == nil {
+		return nil
+	}
+
+	err := p.Thresholds.CheckData(provider.ShortName, published, data)
+	if errors.Is(err, guard.ErrShrink) && p.Force {
+		slog.Warn("publishing despite drop in prefixes", "provider", provider.ShortName, "error", err)
+
+		return nil
+	}
+
+	return err
+}
+
 func isUpToDate(origin, repo io.Reader) (bool, error) {
 	originHash, err := fileContentHash(origin)
 	if err != nil {
@@ -172,17 +616,14 @@ func isUpToDate(origin, repo io.Reader) (bool, error) {
 	return false, nil
 }
 
-func createCommit(wt *git.Worktree, msg string) (plumbing.Hash, error) {
+func createCommit(wt *git.Worktree, msg string, author object.Signature, signer Signer) (plumbing.Hash, error) {
 	var err error
 
 	var commit plumbing.Hash
 
 	commit, err = wt.Commit(msg, &git.CommitOptions{
-		Author: &object.Signature{
-			Name:  "ip-fetcher",
-			Email: "ip-fetcher@lessknown.co.uk",
-			When:  time.Now(),
-		},
+		Author: &author,
+		Signer: gitSigner(signer),
 	})
 	if err != nil {
 		return plumbing.Hash{}, err
@@ -201,19 +642,11 @@ func createFile(fs billy.Filesystem, name string, content []byte) error {
 		return err
 	}
 
-	if _, err = gbFile.Write(content); err != nil {
-		return err
-	}
+	defer gbFile.Close()
 
-	if name == "README.md" {
-		slog.Info("README.md updated")
+	_, err = gbFile.Write(content)
 
-		return nil
-	}
-
-	slog.Info("provider", name, "updated")
-
-	return nil
+	return err
 }
 
 func fileContentHash(content io.Reader) (string, error) {
@@ -0,0 +1,282 @@
+package publisher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/jonhadfield/ip-fetcher/internal/web"
+)
+
+const (
+	defaultGitHubAPIURL  = "https://api.github.com"
+	defaultBranchPrefix  = "ip-fetcher/"
+	gitHubAPIVersion     = "2022-11-28"
+	pullRequestDateStyle = "2006-01-02"
+	pullRequestsPerPage  = 100
+)
+
+// PullRequestConfig proposes the changes of a run in a GitHub pull request, rather than
+// pushing them to the repository's default branch. Commits are pushed to a dated branch and a
+// pull request opened from it or, if one opened by an earlier run is still open, pushed to its
+// branch and the pull request updated.
+type PullRequestConfig struct {
+	// APIURL is the GitHub REST API's url, defaulting to https://api.github.com.
+	APIURL string
+	// Owner and Repo name the repository, defaulting to those of the git destination's url.
+	Owner string
+	Repo  string
+	// Token authenticates with the API, defaulting to the publisher's GitHub token.
+	Token string
+	// BranchPrefix begins the name of each branch pushed, defaulting to "ip-fetcher/". Open
+	// pull requests from branches with the prefix are updated rather than duplicated.
+	BranchPrefix string
+	Client       *retryablehttp.Client
+}
+
+// PullRequest is a GitHub pull request.
+type PullRequest struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	Head    struct {
+		Ref  string `json:"ref"`
+		Repo struct {
+			FullName string `json:"full_name"`
+		} `json:"repo"`
+	} `json:"head"`
+}
+
+// ParseGitHubRepo returns the owner and name of the GitHub repository at the git remote u,
+// such as https://github.com/example/ranges.git or git@github.com:example/ranges.git.
+func ParseGitHubRepo(u string) (string, string, error) {
+	p := strings.TrimSpace(u)
+
+	if rest, ok := strings.CutPrefix(p, "git@"); ok {
+		_, p, _ = strings.Cut(rest, ":")
+	} else {
+		parsed, err := url.Parse(p)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid repository url: %w", err)
+		}
+
+		p = parsed.Path
+	}
+
+	owner, repo, ok := strings.Cut(strings.Trim(strings.TrimSuffix(p, ".git"), "/"), "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", fmt.Errorf("invalid repository url %s: expected OWNER/REPO", redact(u))
+	}
+
+	return owner, repo, nil
+}
+
+func redact(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.User == nil {
+		return u
+	}
+
+	return parsed.Redacted()
+}
+
+// propose pushes the commits to a branch and opens or updates a pull request from it.
+func (p *Publisher) propose(dest *GitDestination, changes Summary) error {
+	if len(changes) == 0 {
+		// the README and manifest alone aren't worth reviewing
+		slog.Info("no changes to propose", "destination", dest.String())
+
+		return nil
+	}
+
+	cfg := *p.PullRequest
+
+	if cfg.Owner == "" || cfg.Repo == "" {
+		var err error
+		if cfg.Owner, cfg.Repo, err = ParseGitHubRepo(dest.URL); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Token == "" {
+		cfg.Token = p.GitHubToken
+	}
+
+	if cfg.BranchPrefix == "" {
+		cfg.BranchPrefix = defaultBranchPrefix
+	}
+
+	base, err := dest.branch()
+	if err != nil {
+		return err
+	}
+
+	pr, err := cfg.findOpen(base)
+	if err != nil {
+		return err
+	}
+
+	dest.Branch = cfg.BranchPrefix + time.Now().UTC().Format(pullRequestDateStyle)
+	if pr != nil {
+		dest.Branch = pr.Head.Ref
+	}
+
+	if err = dest.Close(); err != nil {
+		return err
+	}
+
+	title, body := pullRequestTitle(changes), pullRequestBody(changes)
+
+	if pr == nil {
+		pr = &PullRequest{}
+
+		err = cfg.do(http.MethodPost, "pulls", map[string]string{
+			"title": title, "body": body, "head": dest.Branch, "base": base,
+		}, pr)
+		if err != nil {
+			return fmt.Errorf("failed to open pull request: %w", err)
+		}
+
+		slog.Info("opened pull request", "url", pr.HTMLURL)
+
+		return nil
+	}
+
+	if err = cfg.do(http.MethodPatch, fmt.Sprintf("pulls/%d", pr.Number), map[string]string{
+		"title": title, "body": body,
+	}, pr); err != nil {
+		return fmt.Errorf("failed to update pull request: %w", err)
+	}
+
+	slog.Info("updated pull request", "url", pr.HTMLURL)
+
+	return nil
+}
+
+// findOpen returns the open pull request into base from a branch pushed by an earlier run, if any.
+// Branches are named by date, so every page of open pull requests is searched.
+func (cfg PullRequestConfig) findOpen(base string) (*PullRequest, error) {
+	for page := 1; ; page++ {
+		var prs []PullRequest
+
+		path := fmt.Sprintf("pulls?state=open&per_page=%d&page=%d&base=%s", pullRequestsPerPage, page, url.QueryEscape(base))
+		if err := cfg.do(http.MethodGet, path, nil, &prs); err != nil {
+			return nil, fmt.Errorf("failed to list pull requests: %w", err)
+		}
+
+		for _, pr := range prs {
+			if strings.HasPrefix(pr.Head.Ref, cfg.BranchPrefix) &&
+				strings.EqualFold(pr.Head.Repo.FullName, cfg.Owner+"/"+cfg.Repo) {
+				return &pr, nil
+			}
+		}
+
+		if len(prs) < pullRequestsPerPage {
+			return nil, nil
+		}
+	}
+}
+
+// do calls the repository's endpoint at path, sending in, if not nil, and decoding the
+// response into out.
+func (cfg PullRequestConfig) do(method, path string, in, out any) error {
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		apiURL = defaultGitHubAPIURL
+	}
+
+	var body []byte
+
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	req, err := retryablehttp.NewRequest(method,
+		fmt.Sprintf("%s/repos/%s/%s/%s", strings.TrimSuffix(apiURL, "/"), cfg.Owner, cfg.Repo, path), body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", gitHubAPIVersion)
+
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	}
+
+	client := cfg.Client
+	if client == nil {
+		client = web.NewHTTPClientWithLogger()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return web.MaskError(err, []string{cfg.Token})
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if !web.IsSuccessStatus(resp.StatusCode) {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("%s %s: %s", method, path, apiErr.Message)
+		}
+
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err = json.NewDecoder(bytes.NewReader(data)).Decode(out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+
+	return nil
+}
+
+func pullRequestTitle(changes Summary) string {
+	subject, _, _ := strings.Cut(changes.Message(), "\n")
+
+	return subject
+}
+
+// pullRequestBody lists the prefixes each provider added and removed.
+func pullRequestBody(changes Summary) string {
+	var b strings.Builder
+
+	b.WriteString("Updates the published ranges.\n\n")
+	b.WriteString("| provider | file | added | removed |\n")
+	b.WriteString("| -------- | ---- | ----: | ------: |\n")
+
+	for _, c := range changes {
+		fmt.Fprintf(&b, "| %s | %s | %d | %d |\n", c.Provider, c.File, c.Added, c.Removed)
+	}
+
+	b.WriteString("\n_generated by [ip-fetcher](https://github.com/jonhadfield/ip-fetcher)_\n")
+
+	return b.String()
+}
@@ -0,0 +1,349 @@
+package publisher
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/jonhadfield/ip-fetcher/internal/web"
+)
+
+const (
+	s3Service       = "s3"
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3DateFormat    = "20060102"
+	s3TimeFormat    = "20060102T150405Z"
+	s3DefaultRegion = "us-east-1"
+)
+
+// S3Destination publishes to a bucket in Amazon S3 or an S3-compatible object store, such as
+// MinIO, Cloudflare R2 or Ceph. Objects are addressed by path, so buckets need not be DNS names.
+type S3Destination struct {
+	// Endpoint is the object store's url, e.g. https://s3.eu-west-1.amazonaws.com or http://localhost:9000.
+	Endpoint string
+	// Region signs requests, defaulting to us-east-1.
+	Region string
+	// Bucket holds the published objects, named by Prefix followed by the file name.
+	Bucket string
+	Prefix string
+	// AccessKeyID, SecretAccessKey and, for temporary credentials, SessionToken sign requests.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Client          *retryablehttp.Client
+	Timeout         time.Duration
+
+	staged staged
+	now    func() time.Time
+}
+
+// NewS3Destination returns a destination publishing to bucket at endpoint, with object names
+// beginning with prefix.
+func NewS3Destination(endpoint, region, bucket, prefix string) *S3Destination {
+	return &S3Destination{
+		Endpoint: strings.TrimSuffix(endpoint, "/"),
+		Region:   region,
+		Bucket:   bucket,
+		Prefix:   prefix,
+		Client:   web.NewHTTPClientWithLogger(),
+		Timeout:  web.LongRequestTimeout,
+	}
+}
+
+func (d *S3Destination) String() string {
+	return "s3://" + d.Bucket + "/" + d.Prefix
+}
+
+func (d *S3Destination) Open() error {
+	if d.Endpoint == "" || d.Bucket == "" {
+		return fmt.Errorf("%s: endpoint and bucket are required", d)
+	}
+
+	if d.AccessKeyID == "" || d.SecretAccessKey == "" {
+		return fmt.Errorf("%s: access key id and secret access key are required", d)
+	}
+
+	return nil
+}
+
+// Read returns the object's content. Without s3:ListBucket permission, S3 answers requests for
+// missing objects with 403 AccessDenied rather than 404 NoSuchKey, which can't be told apart from
+// other denials and so is returned as an error.
+func (d *S3Destination) Read(name string) ([]byte, error) {
+	resp, err := d.do(http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, notExist(d, name)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	code := s3ErrorCode(body)
+	if code == "NoSuchKey" {
+		return nil, notExist(d, name)
+	}
+
+	if !web.IsSuccessStatus(resp.StatusCode) {
+		msg := s3ErrorMessage(resp.Status, body)
+		if code == "AccessDenied" {
+			msg += " (reading objects that don't exist requires s3:ListBucket)"
+		}
+
+		return nil, fmt.Errorf("%s: failed to get %s: %s", d, name, msg)
+	}
+
+	return body, nil
+}
+
+// Write stages a file, uploaded by Commit.
+func (d *S3Destination) Write(name string, data []byte) error {
+	d.staged.write(name, data)
+
+	return nil
+}
+
+// Remove stages the removal of a file, deleted by Commit.
+func (d *S3Destination) Remove(name string) error {
+	d.staged.remove(name)
+
+	return nil
+}
+
+// Commit uploads the staged files, then deletes those staged for removal. Each object is
+// replaced atomically, but a failed commit may leave earlier objects uploaded.
+func (d *S3Destination) Commit(string) error {
+	if d.staged.empty() {
+		return ErrNothingToCommit
+	}
+
+	for _, name := range d.staged.names {
+		if err := d.put(name, d.staged.files[name]); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range d.staged.removed {
+		if err := d.delete(name); err != nil {
+			return err
+		}
+	}
+
+	d.staged.reset()
+
+	return nil
+}
+
+func (d *S3Destination) Close() error {
+	return nil
+}
+
+func (d *S3Destination) put(name string, data []byte) error {
+	resp, err := d.do(http.MethodPut, name, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if !web.IsSuccessStatus(resp.StatusCode) {
+		body, _ := io.ReadAll(resp.Body)
+
+		return fmt.Errorf("%s: failed to put %s: %s", d, name, s3ErrorMessage(resp.Status, body))
+	}
+
+	return nil
+}
+
+func (d *S3Destination) delete(name string) error {
+	resp, err := d.do(http.MethodDelete, name, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// deleting a missing object succeeds
+	if !web.IsSuccessStatus(resp.StatusCode) && resp.StatusCode != http.StatusNotFound {
+		body, _ := io.ReadAll(resp.Body)
+
+		return fmt.Errorf("%s: failed to delete %s: %s", d, name, s3ErrorMessage(resp.Status, body))
+	}
+
+	return nil
+}
+
+func (d *S3Destination) do(method, name string, body []byte) (*http.Response, error) {
+	u, err := url.Parse(d.Endpoint + "/" + s3EscapePath(d.Bucket+"/"+d.Prefix+name))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := retryablehttp.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	if method == http.MethodPut {
+		req.Header.Set("Content-Type", contentType(name))
+	}
+
+	now := time.Now
+	if d.now != nil {
+		now = d.now
+	}
+
+	d.sign(req.Request, body, now().UTC())
+
+	client := d.Client
+	if client == nil {
+		client = web.NewHTTPClientWithLogger()
+	}
+
+	client.HTTPClient.Timeout = d.Timeout
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, web.MaskError(err, []string{d.SecretAccessKey, d.SessionToken})
+	}
+
+	return resp, nil
+}
+
+// sign adds AWS Signature Version 4 headers to req, signing every header already set.
+func (d *S3Destination) sign(req *http.Request, body []byte, t time.Time) {
+	region := d.Region
+	if region == "" {
+		region = s3DefaultRegion
+	}
+
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", t.Format(s3TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	if d.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", d.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.Join(v, ",")
+	}
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+
+	slices.Sort(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + strings.TrimSpace(headers[k]) + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{t.Format(s3DateFormat), region, s3Service, "aws4_request"}, "/")
+
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		t.Format(s3TimeFormat),
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := []byte("AWS4" + d.SecretAccessKey)
+	for _, v := range []string{t.Format(s3DateFormat), region, s3Service, "aws4_request"} {
+		key = hmacSHA256(key, v)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, d.AccessKeyID, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign))))
+}
+
+// s3EscapePath escapes each segment of an object path as S3 requires, keeping the '/'s.
+func s3EscapePath(p string) string {
+	var sb strings.Builder
+
+	for _, b := range []byte(p) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', bytes.IndexByte([]byte("-_.~/"), b) >= 0:
+			sb.WriteByte(b)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", b)
+		}
+	}
+
+	return sb.String()
+}
+
+func s3ErrorMessage(status string, body []byte) string {
+	if msg := strings.TrimSpace(string(body)); msg != "" {
+		return status + ": " + msg
+	}
+
+	return status
+}
+
+// s3ErrorCode returns the code of an S3 error response, e.g. NoSuchKey, or an empty string if
+// the body isn't one.
+func s3ErrorCode(body []byte) string {
+	var e struct {
+		Code string `xml:"Code"`
+	}
+
+	if xml.Unmarshal(body, &e) != nil {
+		return ""
+	}
+
+	return e.Code
+}
+
+func contentType(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".json"):
+		return "application/json"
+	case strings.HasSuffix(name, ".md"):
+		return "text/markdown; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
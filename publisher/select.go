@@ -0,0 +1,94 @@
+package publisher
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// SelectProviders returns the providers named by include, or every provider if none are, except
+// those named by exclude.
+func SelectProviders(include, exclude []string) ([]Provider, error) {
+	all := Providers()
+
+	known := func(names []string) error {
+		for _, name := range names {
+			if !slices.ContainsFunc(all, func(p Provider) bool { return p.ShortName == name }) {
+				return fmt.Errorf("unknown provider %q", name)
+			}
+		}
+
+		return nil
+	}
+
+	include, exclude = providerNames(include), providerNames(exclude)
+
+	if err := known(include); err != nil {
+		return nil, err
+	}
+
+	if err := known(exclude); err != nil {
+		return nil, err
+	}
+
+	var selected []Provider
+
+	for _, p := range all {
+		if (len(include) == 0 || slices.Contains(include, p.ShortName)) && !slices.Contains(exclude, p.ShortName) {
+			selected = append(selected, p)
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, errors.New("no providers selected")
+	}
+
+	return selected, nil
+}
+
+func providerNames(names []string) []string {
+	res := make([]string, 0, len(names))
+
+	for _, name := range names {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			res = append(res, name)
+		}
+	}
+
+	return res
+}
+
+// RenameFiles returns the providers with the file each publishes its data to replaced by that
+// named in files, keyed by provider, e.g. aws: ranges/aws.json.
+func RenameFiles(providers []Provider, files map[string]string) ([]Provider, error) {
+	all := Providers()
+
+	for name, file := range files {
+		if !slices.ContainsFunc(all, func(p Provider) bool { return p.ShortName == name }) {
+			return nil, fmt.Errorf("unknown provider %q", name)
+		}
+
+		if !fs.ValidPath(file) || file == "." || file == readMeFile || file == manifestFile {
+			return nil, fmt.Errorf("invalid file name %q for %s", file, name)
+		}
+	}
+
+	res := slices.Clone(providers)
+	seen := make(map[string]string, len(res))
+
+	for i, p := range res {
+		if file, ok := files[p.ShortName]; ok {
+			res[i].File = file
+		}
+
+		if other, ok := seen[res[i].File]; ok {
+			return nil, fmt.Errorf("%s and %s are both published to %s", other, p.ShortName, res[i].File)
+		}
+
+		seen[res[i].File] = p.ShortName
+	}
+
+	return res, nil
+}
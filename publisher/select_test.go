@@ -0,0 +1,128 @@
+package publisher_test
+
+import (
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/jonhadfield/ip-fetcher/publisher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectProviders(t *testing.T) {
+	all, err := publisher.SelectProviders(nil, nil)
+	require.NoError(t, err)
+	require.Len(t, all, len(publisher.Providers()))
+
+	var names []string
+	for _, p := range all {
+		names = append(names, p.ShortName)
+	}
+
+	require.Subset(t, names, []string{"akamai", "bingbot", "digitalocean", "github", "icloudpr"})
+
+	selected, err := publisher.SelectProviders([]string{"gcp", " AWS ", "azure"}, []string{"azure"})
+	require.NoError(t, err)
+	require.Len(t, selected, 2)
+	require.Equal(t, "aws", selected[0].ShortName)
+	require.Equal(t, "gcp", selected[1].ShortName)
+
+	selected, err = publisher.SelectProviders(nil, []string{"icloudpr"})
+	require.NoError(t, err)
+	require.Len(t, selected, len(all)-1)
+
+	_, err = publisher.SelectProviders([]string{"example"}, nil)
+	require.ErrorContains(t, err, `unknown provider "example"`)
+
+	_, err = publisher.SelectProviders(nil, []string{"example"})
+	require.ErrorContains(t, err, `unknown provider "example"`)
+
+	_, err = publisher.SelectProviders([]string{"aws"}, []string{"aws"})
+	require.ErrorContains(t, err, "no providers selected")
+}
+
+func TestRenameFiles(t *testing.T) {
+	selected, err := publisher.SelectProviders([]string{"aws", "gcp"}, nil)
+	require.NoError(t, err)
+
+	renamed, err := publisher.RenameFiles(selected, map[string]string{"aws": "ranges/aws.json", "azure": "azure.json"})
+	require.NoError(t, err)
+	require.Equal(t, "ranges/aws.json", renamed[0].File)
+	require.Equal(t, "gcp.json", renamed[1].File)
+	require.Equal(t, "aws.json", selected[0].File)
+
+	for _, name := range []string{"", "/aws.json", "../aws.json", "ranges/../aws.json", "README.md", "manifest.json"} {
+		_, err = publisher.RenameFiles(selected, map[string]string{"aws": name})
+		require.ErrorContains(t, err, "invalid file name", name)
+	}
+
+	_, err = publisher.RenameFiles(selected, map[string]string{"example": "example.json"})
+	require.ErrorContains(t, err, `unknown provider "example"`)
+
+	_, err = publisher.RenameFiles(selected, map[string]string{"aws": "gcp.json"})
+	require.ErrorContains(t, err, "aws and gcp are both published to gcp.json")
+}
+
+func TestPublishBranchAuthorAndTemplate(t *testing.T) {
+	remote := t.TempDir()
+
+	_, err := git.PlainInit(remote, true)
+	require.NoError(t, err)
+
+	url := "file://" + remote
+
+	// the default branch is published as usual
+	testPublish(t, publisher.NewGitDestination(url, nil), `{"prefixes":["192.0.2.0/24"]}`)
+
+	publish := func(data string) {
+		t.Helper()
+
+		_, err := publisher.Publish(
+			publisher.WithDestination(publisher.NewGitDestination(url, nil)),
+			publisher.WithProviders(testProvider(data)),
+			publisher.WithBranch("data"),
+			publisher.WithAuthor("Ranges Bot", "ranges@example.com"),
+			publisher.WithReadMeTemplate("# ranges\n\n{{ rows }}"),
+		)
+		require.NoError(t, err)
+	}
+
+	// the branch is created from the default branch, then published to
+	publish(`{"prefixes":["192.0.2.0/24","198.51.100.0/24"]}`)
+	publish(`{"prefixes":["192.0.2.0/24","198.51.100.0/24","203.0.113.0/24"]}`)
+
+	worktree := memfs.New()
+
+	repo, err := git.Clone(memory.NewStorage(), worktree, &git.CloneOptions{
+		URL:           url,
+		ReferenceName: plumbing.NewBranchReferenceName("data"),
+	})
+	require.NoError(t, err)
+
+	require.Contains(t, string(readFile(t, worktree, "test.json")), "203.0.113.0/24")
+	require.Contains(t, string(readFile(t, worktree, "README.md")), "# ranges\n\n| [test.json](test.json)")
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	commit, err := repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	require.Equal(t, "Ranges Bot", commit.Author.Name)
+	require.Equal(t, "ranges@example.com", commit.Author.Email)
+
+	main := memfs.New()
+
+	repo, err = git.Clone(memory.NewStorage(), main, &git.CloneOptions{URL: url})
+	require.NoError(t, err)
+	require.Equal(t, `{"prefixes":["192.0.2.0/24"]}`, string(readFile(t, main, "test.json")))
+
+	head, err = repo.Head()
+	require.NoError(t, err)
+
+	commit, err = repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	require.Equal(t, publisher.DefaultAuthorName, commit.Author.Name)
+	require.Equal(t, publisher.DefaultAuthorEmail, commit.Author.Email)
+}
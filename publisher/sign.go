@@ -0,0 +1,214 @@
+package publisher
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// SignatureNamespaceGit is the SSH signature namespace of commits, as git verifies them.
+	SignatureNamespaceGit = "git"
+	// SignatureNamespaceFile is the SSH signature namespace of published files, verified with
+	// ssh-keygen -Y verify -n file.
+	SignatureNamespaceFile = "file"
+)
+
+// Signer signs commits and published files.
+type Signer interface {
+	// Sign returns an armored detached signature of message. SSH signatures are bound to the
+	// namespace, such as SignatureNamespaceGit.
+	Sign(namespace string, message io.Reader) ([]byte, error)
+	// Extension is appended to the name of a file to name its detached signature.
+	Extension() string
+}
+
+// NewSigner returns a Signer for an armored OpenPGP or OpenSSH private key, decrypted with
+// passphrase if not empty.
+func NewSigner(key []byte, passphrase string) (Signer, error) {
+	if bytes.Contains(key, []byte("BEGIN PGP PRIVATE KEY BLOCK")) {
+		return NewOpenPGPSigner(key, passphrase)
+	}
+
+	return NewSSHSigner(key, passphrase)
+}
+
+// OpenPGPSigner signs with an OpenPGP key, as gpg does.
+type OpenPGPSigner struct {
+	entity *openpgp.Entity
+}
+
+// NewOpenPGPSigner returns a Signer for the first key of an armored OpenPGP key ring.
+func NewOpenPGPSigner(key []byte, passphrase string) (*OpenPGPSigner, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read openpgp key: %w", err)
+	}
+
+	if len(entities) == 0 {
+		return nil, errors.New("failed to read openpgp key: no keys found")
+	}
+
+	entity := entities[0]
+	if entity.PrivateKey == nil {
+		return nil, errors.New("failed to read openpgp key: not a private key")
+	}
+
+	if entity.PrivateKey.Encrypted {
+		if passphrase == "" {
+			return nil, errors.New("openpgp key is encrypted: a passphrase is required")
+		}
+
+		if err = entity.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt openpgp key: %w", err)
+		}
+	}
+
+	return &OpenPGPSigner{entity: entity}, nil
+}
+
+func (s *OpenPGPSigner) Sign(_ string, message io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := openpgp.ArmoredDetachSign(&buf, s.entity, message, nil); err != nil {
+		return nil, err
+	}
+
+	return append(buf.Bytes(), '\n'), nil
+}
+
+func (s *OpenPGPSigner) Extension() string {
+	return ".asc"
+}
+
+// SSHSigner signs with an SSH key in the SSHSIG format, as ssh-keygen -Y sign does.
+type SSHSigner struct {
+	signer ssh.Signer
+}
+
+// NewSSHSigner returns a Signer for an OpenSSH private key.
+func NewSSHSigner(key []byte, passphrase string) (*SSHSigner, error) {
+	var (
+		signer ssh.Signer
+		err    error
+	)
+
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(key)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	return &SSHSigner{signer: signer}, nil
+}
+
+// PublicKey returns the public key in authorized_keys format, as listed in allowed signers files.
+func (s *SSHSigner) PublicKey() string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(s.signer.PublicKey())))
+}
+
+func (s *SSHSigner) Sign(namespace string, message io.Reader) ([]byte, error) {
+	h := sha512.New()
+	if _, err := io.Copy(h, message); err != nil {
+		return nil, err
+	}
+
+	// the signed data and signature blob are described in OpenSSH's PROTOCOL.sshsig
+	signed := sshsigBlob(namespace, h.Sum(nil))
+
+	var (
+		sig *ssh.Signature
+		err error
+	)
+
+	if as, ok := s.signer.(ssh.AlgorithmSigner); ok && s.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		sig, err = as.SignWithAlgorithm(rand.Reader, signed, ssh.KeyAlgoRSASHA512)
+	} else {
+		sig, err = s.signer.Sign(rand.Reader, signed)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var blob bytes.Buffer
+
+	blob.WriteString("SSHSIG")
+	_ = binary.Write(&blob, binary.BigEndian, uint32(1))
+	writeSSHString(&blob, s.signer.PublicKey().Marshal())
+	writeSSHString(&blob, []byte(namespace))
+	writeSSHString(&blob, nil)
+	writeSSHString(&blob, []byte("sha512"))
+	writeSSHString(&blob, ssh.Marshal(sig))
+
+	encoded := base64.StdEncoding.EncodeToString(blob.Bytes())
+
+	var out strings.Builder
+
+	out.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+
+	for len(encoded) > 0 {
+		n := min(len(encoded), 70) //nolint:mnd
+
+		out.WriteString(encoded[:n] + "\n")
+		encoded = encoded[n:]
+	}
+
+	out.WriteString("-----END SSH SIGNATURE-----\n")
+
+	return []byte(out.String()), nil
+}
+
+func (s *SSHSigner) Extension() string {
+	return ".sig"
+}
+
+// sshsigBlob returns the data signed for a message hashed with SHA-512.
+func sshsigBlob(namespace string, hash []byte) []byte {
+	var b bytes.Buffer
+
+	b.WriteString("SSHSIG")
+	writeSSHString(&b, []byte(namespace))
+	writeSSHString(&b, nil)
+	writeSSHString(&b, []byte("sha512"))
+	writeSSHString(&b, hash)
+
+	return b.Bytes()
+}
+
+func writeSSHString(b *bytes.Buffer, s []byte) {
+	_ = binary.Write(b, binary.BigEndian, uint32(len(s))) //nolint:gosec
+	b.Write(s)
+}
+
+// commitSigner signs commits for go-git.
+type commitSigner struct {
+	signer Signer
+}
+
+func (s commitSigner) Sign(message io.Reader) ([]byte, error) {
+	return s.signer.Sign(SignatureNamespaceGit, message)
+}
+
+// gitSigner returns the go-git signer of commits, or nil to leave them unsigned.
+func gitSigner(s Signer) git.Signer {
+	if s == nil {
+		return nil
+	}
+
+	return commitSigner{signer: s}
+}
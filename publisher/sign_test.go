@@ -0,0 +1,166 @@
+package publisher_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/jonhadfield/ip-fetcher/publisher"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// testOpenPGPKey returns a new armored OpenPGP private key and its armored public key.
+func testOpenPGPKey(t *testing.T) ([]byte, string) {
+	t.Helper()
+
+	entity, err := openpgp.NewEntity("ip-fetcher", "", "ip-fetcher@example.com", nil)
+	require.NoError(t, err)
+
+	var private, public bytes.Buffer
+
+	w, err := armor.Encode(&private, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivate(w, nil))
+	require.NoError(t, w.Close())
+
+	w, err = armor.Encode(&public, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+
+	return private.Bytes(), public.String()
+}
+
+func TestOpenPGPSigner(t *testing.T) {
+	private, public := testOpenPGPKey(t)
+
+	signer, err := publisher.NewSigner(private, "")
+	require.NoError(t, err)
+	require.Equal(t, ".asc", signer.Extension())
+
+	sig, err := signer.Sign(publisher.SignatureNamespaceFile, strings.NewReader("192.0.2.0/24\n"))
+	require.NoError(t, err)
+
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(public))
+	require.NoError(t, err)
+
+	_, err = openpgp.CheckArmoredDetachedSignature(keyring, strings.NewReader("192.0.2.0/24\n"), bytes.NewReader(sig), nil)
+	require.NoError(t, err)
+
+	_, err = openpgp.CheckArmoredDetachedSignature(keyring, strings.NewReader("198.51.100.0/24\n"), bytes.NewReader(sig), nil)
+	require.Error(t, err)
+
+	_, err = publisher.NewSigner([]byte(public), "")
+	require.Error(t, err)
+}
+
+func TestSSHSigner(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := ssh.MarshalPrivateKey(key, "")
+	require.NoError(t, err)
+
+	signer, err := publisher.NewSigner(pem.EncodeToMemory(block), "")
+	require.NoError(t, err)
+	require.Equal(t, ".sig", signer.Extension())
+
+	sig, err := signer.Sign(publisher.SignatureNamespaceFile, strings.NewReader("192.0.2.0/24\n"))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(sig, []byte("-----BEGIN SSH SIGNATURE-----\n")))
+
+	keygen, err := exec.LookPath("ssh-keygen")
+	if err != nil {
+		t.Skip("ssh-keygen not found")
+	}
+
+	dir := t.TempDir()
+	sigPath := filepath.Join(dir, "data.sig")
+	require.NoError(t, os.WriteFile(sigPath, sig, 0o600))
+
+	verify := func(namespace, data string) error {
+		cmd := exec.Command(keygen, "-Y", "check-novalidate", "-n", namespace, "-s", sigPath)
+		cmd.Stdin = strings.NewReader(data)
+
+		return cmd.Run()
+	}
+
+	require.NoError(t, verify("file", "192.0.2.0/24\n"))
+	require.Error(t, verify("file", "198.51.100.0/24\n"))
+	require.Error(t, verify("git", "192.0.2.0/24\n"))
+}
+
+func TestPublishSigned(t *testing.T) {
+	private, public := testOpenPGPKey(t)
+
+	signer, err := publisher.NewSigner(private, "")
+	require.NoError(t, err)
+
+	remote := t.TempDir()
+
+	_, err = git.PlainInit(remote, true)
+	require.NoError(t, err)
+
+	url := "file://" + remote
+
+	_, err = publisher.Publish(
+		publisher.WithDestination(publisher.NewGitDestination(url, nil)),
+		publisher.WithSigner(signer),
+		publisher.WithSingleCommit(true),
+		publisher.WithProviders(staticProvider("aws", `["192.0.2.0/24"]`)),
+	)
+	require.NoError(t, err)
+
+	worktree := memfs.New()
+
+	repo, err := git.Clone(memory.NewStorage(), worktree, &git.CloneOptions{URL: url})
+	require.NoError(t, err)
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	commit, err := repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+
+	_, err = commit.Verify(public)
+	require.NoError(t, err)
+
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(public))
+	require.NoError(t, err)
+
+	for _, name := range []string{"aws.json", "manifest.json"} {
+		data := readFile(t, worktree, name)
+		sig := readFile(t, worktree, name+".asc")
+
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(data), bytes.NewReader(sig), nil)
+		require.NoError(t, err, name)
+	}
+}
+
+func readFile(t *testing.T, fs billy.Filesystem, name string) []byte {
+	t.Helper()
+
+	f, err := fs.Open(name)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+
+	return data
+}
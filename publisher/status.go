@@ -0,0 +1,121 @@
+package publisher
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultStaleAfter is how long a provider may go without a successful update before it is
+// flagged as stale.
+const DefaultStaleAfter = 48 * time.Hour
+
+// Status is the outcome of the latest attempt to publish a provider's data.
+type Status string
+
+const (
+	// StatusOK is a provider whose data was fetched and published, or already up to date.
+	StatusOK Status = "ok"
+	// StatusFailed is a provider whose data could not be fetched or published.
+	StatusFailed Status = "failed"
+	// StatusRefused is a provider whose number of prefixes dropped beyond the thresholds.
+	StatusRefused Status = "refused"
+)
+
+// ProviderStatus records the latest attempt to publish a provider's data, so that consumers
+// can tell when data published for it was last refreshed.
+type ProviderStatus struct {
+	Provider string `json:"provider"`
+	Status   Status `json:"status"`
+	// Error describes why the latest attempt failed.
+	Error       string    `json:"error,omitempty"`
+	LastAttempt time.Time `json:"last_attempt"`
+	// LastSuccess is when the data was last fetched and published, or found up to date.
+	LastSuccess time.Time `json:"last_success,omitzero"`
+	// Stale is set if the data has not been refreshed within the publisher's StaleAfter.
+	Stale bool `json:"stale"`
+}
+
+// statuses records the outcome of each provider's attempt during a run.
+type statuses struct {
+	now        time.Time
+	staleAfter time.Duration
+	previous   map[string]ProviderStatus
+	// fetched is when data was last fetched for providers published before their status was
+	// recorded.
+	fetched map[string]time.Time
+	current map[string]ProviderStatus
+}
+
+func newStatuses(now time.Time, staleAfter time.Duration, previous Manifest) *statuses {
+	s := &statuses{
+		now:        now,
+		staleAfter: staleAfter,
+		previous:   make(map[string]ProviderStatus),
+		fetched:    make(map[string]time.Time),
+		current:    make(map[string]ProviderStatus),
+	}
+
+	for _, ps := range previous.Providers {
+		s.previous[ps.Provider] = ps
+	}
+
+	for _, f := range previous.Files {
+		if f.Fetched.After(s.fetched[f.Provider]) {
+			s.fetched[f.Provider] = f.Fetched
+		}
+	}
+
+	return s
+}
+
+func (s *statuses) ok(provider Provider, attempted time.Time) {
+	s.set(ProviderStatus{Provider: provider.ShortName, Status: StatusOK, LastAttempt: attempted, LastSuccess: attempted})
+}
+
+func (s *statuses) fail(provider Provider, status Status, attempted time.Time, err error) {
+	lastSuccess := s.previous[provider.ShortName].LastSuccess
+	if lastSuccess.IsZero() {
+		lastSuccess = s.fetched[provider.ShortName]
+	}
+
+	s.set(ProviderStatus{
+		Provider:    provider.ShortName,
+		Status:      status,
+		Error:       err.Error(),
+		LastAttempt: attempted,
+		LastSuccess: lastSuccess,
+	})
+}
+
+func (s *statuses) set(ps ProviderStatus) {
+	ps.Stale = s.staleAfter > 0 && (ps.LastSuccess.IsZero() || s.now.Sub(ps.LastSuccess) > s.staleAfter)
+
+	s.current[ps.Provider] = ps
+}
+
+// get returns the status recorded for the provider during the run.
+func (s *statuses) get(provider Provider) (ProviderStatus, bool) {
+	if s == nil {
+		return ProviderStatus{}, false
+	}
+
+	ps, ok := s.current[provider.ShortName]
+
+	return ps, ok
+}
+
+// list returns the statuses recorded, sorted by provider.
+func (s *statuses) list() []ProviderStatus {
+	res := make([]ProviderStatus, 0, len(s.current))
+
+	for _, ps := range s.current {
+		res = append(res, ps)
+	}
+
+	slices.SortFunc(res, func(a, b ProviderStatus) int {
+		return strings.Compare(a.Provider, b.Provider)
+	})
+
+	return res
+}
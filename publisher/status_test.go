@@ -0,0 +1,95 @@
+package publisher_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jonhadfield/ip-fetcher/publisher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishStatus(t *testing.T) {
+	dir := t.TempDir()
+
+	aws := staticProvider("aws", `{"prefixes":[{"ip_prefix":"192.0.2.0/24"}]}`)
+	gcp := staticProvider("gcp", `{"prefixes":[{"ipv4Prefix":"203.0.113.0/24"}]}`)
+
+	publish := func(staleAfter time.Duration, providers ...publisher.Provider) (publisher.Manifest, string) {
+		t.Helper()
+
+		_, err := publisher.Publish(
+			publisher.WithDestination(publisher.NewDirDestination(dir)),
+			publisher.WithProviders(providers...),
+			publisher.WithStaleAfter(staleAfter),
+		)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+		require.NoError(t, err)
+
+		m, err := publisher.ReadManifest(data)
+		require.NoError(t, err)
+
+		readMe, err := os.ReadFile(filepath.Join(dir, "README.md"))
+		require.NoError(t, err)
+
+		return m, string(readMe)
+	}
+
+	first, readMe := publish(time.Hour, aws, gcp)
+
+	require.Len(t, first.Providers, 2)
+
+	for _, ps := range first.Providers {
+		require.Equal(t, publisher.StatusOK, ps.Status)
+		require.Empty(t, ps.Error)
+		require.Equal(t, ps.LastAttempt, ps.LastSuccess)
+		require.False(t, ps.Stale)
+	}
+
+	require.Contains(t, readMe, "[gcp.json](gcp.json)")
+	require.Contains(t, readMe, first.Providers[1].LastSuccess.Format("2006-01-02 15:04 UTC")+" | ok |")
+
+	// a provider that fails keeps its row and the time it was last updated
+	failed := gcp
+	failed.FetchFunc = func() ([]byte, http.Header, error) {
+		return nil, nil, errors.New("unexpected status | 503")
+	}
+
+	second, readMe := publish(time.Hour, aws, failed)
+
+	require.Len(t, second.Providers, 2)
+
+	ps := second.Providers[1]
+	require.Equal(t, "gcp", ps.Provider)
+	require.Equal(t, publisher.StatusFailed, ps.Status)
+	require.Equal(t, "unexpected status | 503", ps.Error)
+	require.Equal(t, first.Providers[1].LastSuccess, ps.LastSuccess)
+	require.True(t, ps.LastAttempt.After(ps.LastSuccess))
+	require.False(t, ps.Stale)
+
+	require.Contains(t, readMe, "[gcp.json](gcp.json)")
+	require.Contains(t, readMe, `| failed: unexpected status \| 503 |`)
+
+	// data not refreshed within the period is flagged
+	third, readMe := publish(time.Nanosecond, aws, failed)
+
+	require.False(t, third.Providers[0].Stale)
+	require.True(t, third.Providers[1].Stale)
+	require.Equal(t, first.Providers[1].LastSuccess, third.Providers[1].LastSuccess)
+	require.Contains(t, readMe, "**stale**<br>failed:")
+
+	// a provider never published has no row
+	_, readMe = publish(time.Hour, aws, failed, func() publisher.Provider {
+		p := failed
+		p.ShortName, p.File = "oci", "oci.json"
+
+		return p
+	}())
+
+	require.NotContains(t, readMe, "oci.json")
+}
@@ -0,0 +1,87 @@
+package publisher
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/jonhadfield/ip-fetcher/filter"
+)
+
+// Change records how the prefixes of a provider's published file changed.
+type Change struct {
+	Provider string
+	File     string
+	Added    int
+	Removed  int
+}
+
+// newChange compares the prefixes of the data published for a provider with its new data.
+func newChange(provider Provider, published, data []byte) Change {
+	before := prefixSet(published)
+	after := prefixSet(data)
+
+	c := Change{Provider: provider.ShortName, File: provider.File}
+
+	for p := range after {
+		if _, ok := before[p]; !ok {
+			c.Added++
+		}
+	}
+
+	for p := range before {
+		if _, ok := after[p]; !ok {
+			c.Removed++
+		}
+	}
+
+	return c
+}
+
+func prefixSet(data []byte) map[netip.Prefix]struct{} {
+	set := make(map[netip.Prefix]struct{})
+
+	for _, p := range filter.DataPrefixes(data) {
+		set[p.Masked()] = struct{}{}
+	}
+
+	return set
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: +%d -%d", c.Provider, c.Added, c.Removed)
+}
+
+// Summary describes the changes published by a run.
+type Summary []Change
+
+// Message returns a commit message for the changes, with a subject naming the providers
+// updated and a body listing the prefixes each added and removed.
+func (s Summary) Message() string {
+	if len(s) == 0 {
+		return "update " + readMeFile + " and " + manifestFile
+	}
+
+	names := make([]string, len(s))
+	for i, c := range s {
+		names[i] = c.Provider
+	}
+
+	subject := "update " + strings.Join(names, ", ") + " data"
+	if len(s) > 3 { //nolint:mnd
+		subject = fmt.Sprintf("update data for %d providers", len(s))
+	}
+
+	var added, removed int
+
+	var body strings.Builder
+
+	for _, c := range s {
+		added += c.Added
+		removed += c.Removed
+
+		body.WriteString(c.String() + "\n")
+	}
+
+	return fmt.Sprintf("%s\n\n%s\n%d prefixes added, %d removed\n", subject, body.String(), added, removed)
+}
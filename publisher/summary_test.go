@@ -0,0 +1,114 @@
+package publisher_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/jonhadfield/ip-fetcher/publisher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaryMessage(t *testing.T) {
+	require.Equal(t, "update README.md and manifest.json", publisher.Summary(nil).Message())
+
+	require.Equal(t, "update aws, gcp data\n\naws: +2 -1\ngcp: +0 -3\n\n2 prefixes added, 4 removed\n",
+		publisher.Summary{
+			{Provider: "aws", Added: 2, Removed: 1},
+			{Provider: "gcp", Removed: 3},
+		}.Message())
+
+	msg := publisher.Summary{{Provider: "a"}, {Provider: "b"}, {Provider: "c"}, {Provider: "d", Added: 1}}.Message()
+	require.Contains(t, msg, "update data for 4 providers\n\n")
+	require.Contains(t, msg, "d: +1 -0\n")
+}
+
+func TestPublishSingleCommit(t *testing.T) {
+	remote := t.TempDir()
+
+	_, err := git.PlainInit(remote, true)
+	require.NoError(t, err)
+
+	url := "file://" + remote
+
+	publish := func(aws, gcp string) {
+		p := publisher.New()
+		for _, o := range []publisher.Option{
+			publisher.WithDestination(publisher.NewGitDestination(url, nil)),
+			publisher.WithSingleCommit(true),
+			publisher.WithProviders(
+				publisher.Provider{FetchFunc: func() ([]byte, http.Header, error) { return []byte(aws), nil, nil }, ShortName: "aws", File: "aws.json"},
+				publisher.Provider{FetchFunc: func() ([]byte, http.Header, error) { return []byte(gcp), nil, nil }, ShortName: "gcp", File: "gcp.json"},
+			),
+		} {
+			o(p)
+		}
+
+		_, err := p.Run()
+		require.NoError(t, err)
+	}
+
+	publish(`["192.0.2.0/24","198.51.100.0/24"]`, `["203.0.113.0/24"]`)
+	publish(`["192.0.2.0/24","198.51.100.0/25","2001:db8::/32"]`, `["203.0.113.0/24"]`)
+
+	repo, err := git.Clone(memory.NewStorage(), memfs.New(), &git.CloneOptions{URL: url})
+	require.NoError(t, err)
+
+	commits, err := repo.Log(&git.LogOptions{})
+	require.NoError(t, err)
+
+	var msgs []string
+
+	require.NoError(t, commits.ForEach(func(c *object.Commit) error {
+		msgs = append(msgs, c.Message)
+
+		return nil
+	}))
+
+	require.Equal(t, []string{
+		"update aws data\n\naws: +2 -1\n\n2 prefixes added, 1 removed\n",
+		"update aws, gcp data\n\naws: +2 -0\ngcp: +1 -0\n\n3 prefixes added, 0 removed\n",
+	}, msgs)
+}
+
+func TestPublishSingleCommitArchiveOnly(t *testing.T) {
+	remote := t.TempDir()
+
+	_, err := git.PlainInit(remote, true)
+	require.NoError(t, err)
+
+	url := "file://" + remote
+
+	publish := func(opts ...publisher.Option) {
+		p := publisher.New()
+		for _, o := range append([]publisher.Option{
+			publisher.WithDestination(publisher.NewGitDestination(url, nil)),
+			publisher.WithSingleCommit(true),
+			publisher.WithProviders(
+				publisher.Provider{FetchFunc: func() ([]byte, http.Header, error) { return []byte(`["192.0.2.0/24"]`), nil, nil }, ShortName: "aws", File: "aws.json"},
+			),
+		}, opts...) {
+			o(p)
+		}
+
+		_, err := p.Run()
+		require.NoError(t, err)
+	}
+
+	// the data is unchanged when history is enabled, so only its snapshot is committed
+	publish()
+	publish(publisher.WithHistory(30))
+
+	repo, err := git.Clone(memory.NewStorage(), memfs.New(), &git.CloneOptions{URL: url})
+	require.NoError(t, err)
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	commit, err := repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	require.Equal(t, "archive aws data", commit.Message)
+}
@@ -3,6 +3,8 @@ package publisher
 import (
 	_ "embed"
 	"fmt"
+	"net/http"
+	"path"
 	"strings"
 	"time"
 
@@ -18,24 +20,26 @@ import (
 	"github.com/jonhadfield/ip-fetcher/providers/tencent"
 	"github.com/jonhadfield/ip-fetcher/providers/zscaler"
 
-	"github.com/go-git/go-billy/v5"
-	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/jonhadfield/ip-fetcher/providers/akamai"
 	"github.com/jonhadfield/ip-fetcher/providers/atlassian"
 	"github.com/jonhadfield/ip-fetcher/providers/aws"
 	"github.com/jonhadfield/ip-fetcher/providers/azure"
+	"github.com/jonhadfield/ip-fetcher/providers/bingbot"
 	"github.com/jonhadfield/ip-fetcher/providers/bunny"
 	"github.com/jonhadfield/ip-fetcher/providers/cdn77"
 	"github.com/jonhadfield/ip-fetcher/providers/cloudflare"
 	"github.com/jonhadfield/ip-fetcher/providers/contabo"
 	"github.com/jonhadfield/ip-fetcher/providers/datadog"
+	"github.com/jonhadfield/ip-fetcher/providers/digitalocean"
 	"github.com/jonhadfield/ip-fetcher/providers/fastly"
 	"github.com/jonhadfield/ip-fetcher/providers/flyio"
 	"github.com/jonhadfield/ip-fetcher/providers/gcp"
+	"github.com/jonhadfield/ip-fetcher/providers/github"
 	"github.com/jonhadfield/ip-fetcher/providers/google"
 	"github.com/jonhadfield/ip-fetcher/providers/googlebot"
 	"github.com/jonhadfield/ip-fetcher/providers/googlesc"
 	"github.com/jonhadfield/ip-fetcher/providers/googleutf"
+	"github.com/jonhadfield/ip-fetcher/providers/icloudpr"
 	"github.com/jonhadfield/ip-fetcher/providers/imperva"
 	"github.com/jonhadfield/ip-fetcher/providers/leaseweb"
 	"github.com/jonhadfield/ip-fetcher/providers/linode"
@@ -44,92 +48,157 @@ import (
 	"github.com/jonhadfield/ip-fetcher/providers/stripe"
 )
 
+const (
+	readMeFile           = "README.md"
+	readMeTimeLayout     = "2006-01-02 15:04 UTC"
+	maxReadMeErrorLength = 200
+)
+
 //go:embed README.template
 var ReadMeTemplate string
 
 type Provider struct {
-	FetchFunc    func() ([]byte, error)
-	SyncDataFunc func(data []byte, wt *git.Worktree, fs billy.Filesystem) (plumbing.Hash, error)
-	ShortName    string
-	File         string
-	FullName     string
-	HostType     string
-	SourceURL    string
+	// FetchFunc returns the provider's data and, if fetched over HTTP, the response headers.
+	FetchFunc func() ([]byte, http.Header, error)
+	ShortName string
+	File      string
+	FullName  string
+	HostType  string
+	SourceURL string
 }
 
 var providers = []Provider{ //nolint:nolintlint,gochecknoglobals
-	{fetchAlibaba, syncAlibabaData, alibaba.ShortName, alibabaFile, alibaba.FullName, alibaba.HostType, alibaba.SourceURL},
-	{fetchAtlassian, syncAtlassianData, atlassian.ShortName, atlassianFile, atlassian.FullName, atlassian.HostType, atlassian.SourceURL},
-	{fetchAWS, syncAWSData, aws.ShortName, awsFile, aws.FullName, aws.HostType, aws.SourceURL},
-	{fetchAzure, syncAzureData, azure.ShortName, azureFile, azure.FullName, azure.HostType, azure.InitialURL},
-	{fetchBunny, syncBunnyData, bunny.ShortName, bunnyFile, bunny.FullName, bunny.HostType, bunny.SourceURL},
-	{fetchCDN77, syncCDN77Data, cdn77.ShortName, cdn77File, cdn77.FullName, cdn77.HostType, cdn77.SourceURL},
-	{fetchCloudflare, syncCloudflareData, cloudflare.ShortName, cloudflareFile, cloudflare.FullName, cloudflare.HostType, cloudflare.SourceURL},
-	{fetchContabo, syncContaboData, contabo.ShortName, contaboFile, contabo.FullName, contabo.HostType, contabo.SourceURL},
-	{fetchDatadog, syncDatadogData, datadog.ShortName, datadogFile, datadog.FullName, datadog.HostType, datadog.SourceURL},
-	{fetchFastly, syncFastlyData, fastly.ShortName, fastlyFile, fastly.FullName, fastly.HostType, fastly.SourceURL},
-	{fetchFlyio, syncFlyioData, flyio.ShortName, flyioFile, flyio.FullName, flyio.HostType, flyio.SourceURL},
-	{fetchGCP, syncGCPData, gcp.ShortName, gcpFile, gcp.FullName, gcp.HostType, gcp.SourceURL},
-	{fetchGoogle, syncGoogleData, google.ShortName, googleFile, google.FullName, google.HostType, google.SourceURL},
-	{fetchGooglebot, syncGooglebotData, googlebot.ShortName, googlebotFile, googlebot.FullName, googlebot.HostType, googlebot.SourceURL},
-	{fetchGoogleSC, syncGoogleSCData, googlesc.ShortName, googlescFile, googlesc.FullName, googlesc.HostType, googlesc.SourceURL},
-	{fetchGoogleUTF, syncGoogleUTFData, googleutf.ShortName, googleutfFile, googleutf.FullName, googleutf.HostType, googleutf.SourceURL},
-	{fetchHetzner, syncHetznerData, hetzner.ShortName, hetznerFile, hetzner.FullName, hetzner.HostType, hetzner.SourceURL},
-	{fetchIBMCloud, syncIBMCloudData, ibmcloud.ShortName, ibmcloudFile, ibmcloud.FullName, ibmcloud.HostType, ibmcloud.SourceURL},
-	{fetchImperva, syncImpervaData, imperva.ShortName, impervaFile, imperva.FullName, imperva.HostType, imperva.SourceURL},
-	{fetchLeaseweb, syncLeasewebData, leaseweb.ShortName, leasewebFile, leaseweb.FullName, leaseweb.HostType, leaseweb.SourceURL},
-	{fetchLinode, syncLinodeData, linode.ShortName, linodeFile, linode.FullName, linode.HostType, linode.SourceURL},
-	{fetchM247, syncM247Data, m247.ShortName, m247File, m247.FullName, m247.HostType, m247.SourceURL},
-	{fetchOCI, syncOCIData, oci.ShortName, ociFile, oci.FullName, oci.HostType, oci.SourceURL},
-	{fetchOVH, syncOVHData, ovh.ShortName, ovhFile, ovh.FullName, ovh.HostType, ovh.SourceURL},
-	{fetchRender, syncRenderData, render.ShortName, renderFile, render.FullName, render.HostType, render.SourceURL},
-	{fetchScaleway, syncScalewayData, scaleway.ShortName, scalewayFile, scaleway.FullName, scaleway.HostType, scaleway.SourceURL},
-	{fetchStripe, syncStripeData, stripe.ShortName, stripeFile, stripe.FullName, stripe.HostType, stripe.SourceURL},
-	{fetchTencent, syncTencentData, tencent.ShortName, tencentFile, tencent.FullName, tencent.HostType, tencent.SourceURL},
-	{fetchVultr, syncVultrData, vultr.ShortName, vultrFile, vultr.FullName, vultr.HostType, vultr.SourceURL},
-	{fetchZscaler, syncZscalerData, zscaler.ShortName, zscalerFile, zscaler.FullName, zscaler.HostType, zscaler.SourceURL},
+	{fetchAkamai, akamai.ShortName, akamaiFile, akamai.FullName, akamai.HostType, akamai.SourceURL},
+	{fetchAlibaba, alibaba.ShortName, alibabaFile, alibaba.FullName, alibaba.HostType, alibaba.SourceURL},
+	{fetchAtlassian, atlassian.ShortName, atlassianFile, atlassian.FullName, atlassian.HostType, atlassian.SourceURL},
+	{fetchAWS, aws.ShortName, awsFile, aws.FullName, aws.HostType, aws.SourceURL},
+	{fetchAzure, azure.ShortName, azureFile, azure.FullName, azure.HostType, azure.InitialURL},
+	{fetchBingbot, bingbot.ShortName, bingbotFile, "Bingbot", "crawlers", bingbot.DownloadURL},
+	{fetchBunny, bunny.ShortName, bunnyFile, bunny.FullName, bunny.HostType, bunny.SourceURL},
+	{fetchCDN77, cdn77.ShortName, cdn77File, cdn77.FullName, cdn77.HostType, cdn77.SourceURL},
+	{fetchCloudflare, cloudflare.ShortName, cloudflareFile, cloudflare.FullName, cloudflare.HostType, cloudflare.SourceURL},
+	{fetchContabo, contabo.ShortName, contaboFile, contabo.FullName, contabo.HostType, contabo.SourceURL},
+	{fetchDatadog, datadog.ShortName, datadogFile, datadog.FullName, datadog.HostType, datadog.SourceURL},
+	{fetchDigitalOcean, digitalocean.ShortName, digitaloceanFile, "DigitalOcean", "hosting", digitalocean.DigitaloceanDownloadURL},
+	{fetchFastly, fastly.ShortName, fastlyFile, fastly.FullName, fastly.HostType, fastly.SourceURL},
+	{fetchFlyio, flyio.ShortName, flyioFile, flyio.FullName, flyio.HostType, flyio.SourceURL},
+	{fetchGCP, gcp.ShortName, gcpFile, gcp.FullName, gcp.HostType, gcp.SourceURL},
+	{fetchGitHub, github.ShortName, githubFile, github.FullName, github.HostType, github.SourceURL},
+	{fetchGoogle, google.ShortName, googleFile, google.FullName, google.HostType, google.SourceURL},
+	{fetchGooglebot, googlebot.ShortName, googlebotFile, googlebot.FullName, googlebot.HostType, googlebot.SourceURL},
+	{fetchGoogleSC, googlesc.ShortName, googlescFile, googlesc.FullName, googlesc.HostType, googlesc.SourceURL},
+	{fetchGoogleUTF, googleutf.ShortName, googleutfFile, googleutf.FullName, googleutf.HostType, googleutf.SourceURL},
+	{fetchHetzner, hetzner.ShortName, hetznerFile, hetzner.FullName, hetzner.HostType, hetzner.SourceURL},
+	{fetchIBMCloud, ibmcloud.ShortName, ibmcloudFile, ibmcloud.FullName, ibmcloud.HostType, ibmcloud.SourceURL},
+	{fetchICloudPR, icloudpr.ShortName, icloudprFile, icloudpr.FullName, icloudpr.HostType, icloudpr.DownloadURL},
+	{fetchImperva, imperva.ShortName, impervaFile, imperva.FullName, imperva.HostType, imperva.SourceURL},
+	{fetchLeaseweb, leaseweb.ShortName, leasewebFile, leaseweb.FullName, leaseweb.HostType, leaseweb.SourceURL},
+	{fetchLinode, linode.ShortName, linodeFile, linode.FullName, linode.HostType, linode.SourceURL},
+	{fetchM247, m247.ShortName, m247File, m247.FullName, m247.HostType, m247.SourceURL},
+	{fetchOCI, oci.ShortName, ociFile, oci.FullName, oci.HostType, oci.SourceURL},
+	{fetchOVH, ovh.ShortName, ovhFile, ovh.FullName, ovh.HostType, ovh.SourceURL},
+	{fetchRender, render.ShortName, renderFile, render.FullName, render.HostType, render.SourceURL},
+	{fetchScaleway, scaleway.ShortName, scalewayFile, scaleway.FullName, scaleway.HostType, scaleway.SourceURL},
+	{fetchStripe, stripe.ShortName, stripeFile, stripe.FullName, stripe.HostType, stripe.SourceURL},
+	{fetchTencent, tencent.ShortName, tencentFile, tencent.FullName, tencent.HostType, tencent.SourceURL},
+	{fetchVultr, vultr.ShortName, vultrFile, vultr.FullName, vultr.HostType, vultr.SourceURL},
+	{fetchZscaler, zscaler.ShortName, zscalerFile, zscaler.FullName, zscaler.HostType, zscaler.SourceURL},
 }
 
 func GenerateReadMeContent(included []string) (string, error) {
-	rows := strings.Builder{}
+	var rows []Provider
 
 	for _, inc := range included {
 		for _, provider := range providers {
 			if inc == provider.ShortName {
-				fmt.Fprintf(
-					&rows,
-					"| [%s](%s)  | %s |  %s | [source](%s) |  \r\n",
-					provider.File,
-					provider.File,
-					provider.FullName,
-					provider.HostType,
-					provider.SourceURL,
-				)
+				rows = append(rows, provider)
 			}
 		}
 	}
 
-	content := strings.ReplaceAll(ReadMeTemplate, "{{ date }}", time.Now().UTC().Format(time.RFC1123))
-	content = strings.ReplaceAll(content, "{{ rows }}", rows.String())
+	return readMeContent(ReadMeTemplate, rows, nil, nil), nil
+}
+
+func readMeContent(tmpl string, included []Provider, formats []Format, status *statuses) string {
+	rows := strings.Builder{}
+
+	for _, provider := range included {
+		ps, _ := status.get(provider)
+
+		fmt.Fprintf(
+			&rows,
+			"| [%s](%s)%s  | %s |  %s | [source](%s) | %s | %s |  \r\n",
+			provider.File,
+			provider.File,
+			formatLinks(provider, formats),
+			provider.FullName,
+			provider.HostType,
+			provider.SourceURL,
+			lastUpdated(ps),
+			statusCell(ps),
+		)
+	}
+
+	content := strings.ReplaceAll(tmpl, "{{ date }}", time.Now().UTC().Format(time.RFC1123))
+
+	return strings.ReplaceAll(content, "{{ rows }}", rows.String())
+}
+
+// lastUpdated returns when the provider's data was last refreshed.
+func lastUpdated(ps ProviderStatus) string {
+	switch {
+	case ps.Provider == "":
+		return ""
+	case ps.LastSuccess.IsZero():
+		return "never"
+	default:
+		return ps.LastSuccess.UTC().Format(readMeTimeLayout)
+	}
+}
+
+// statusCell describes the latest attempt to publish the provider's data, flagging stale data.
+func statusCell(ps ProviderStatus) string {
+	if ps.Provider == "" {
+		return ""
+	}
+
+	cell := string(ps.Status)
 
-	return content, nil
+	if ps.Error != "" {
+		cell += ": " + tableText(ps.Error, maxReadMeErrorLength)
+	}
+
+	if ps.Stale {
+		cell = "**stale**<br>" + cell
+	}
+
+	return cell
 }
 
-func syncReadMe(included []string, wt *git.Worktree, fs billy.Filesystem) (plumbing.Hash, error) {
-	readMeContent, err := GenerateReadMeContent(included)
-	if err != nil {
-		return plumbing.Hash{}, err
+// tableText returns s on a single line, truncated to at most n runes, to fit a table cell.
+func tableText(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+
+	if r := []rune(s); len(r) > n {
+		s = string(r[:n-1]) + "…"
 	}
 
-	if err = createFile(fs, "README.md", []byte(readMeContent)); err != nil {
-		return plumbing.ZeroHash, err
+	return strings.NewReplacer("|", "\\|", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// formatLinks returns links to the files of each format published for the provider.
+func formatLinks(provider Provider, formats []Format) string {
+	var links []string
+
+	for _, f := range formats {
+		for _, name := range f.files(provider) {
+			links = append(links, fmt.Sprintf("[%s](%s)", path.Base(name), name))
+		}
 	}
 
-	// Adds the new file to the staging area.
-	_, err = wt.Add("README.md")
-	if err != nil {
-		return plumbing.ZeroHash, err
+	if len(links) == 0 {
+		return ""
 	}
 
-	return createCommit(wt, "update README.md")
+	return "<br>" + strings.Join(links, " ")
 }